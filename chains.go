@@ -129,8 +129,8 @@ func LLM(model LanguageModel) HandlerFunc {
 
 // ChatMessage is a struct that represents a message in a chat conversation.
 type ChatMessage struct {
-	Role    string
-	Content string
+	Role    string `json:"role"`
+	Content string `json:"content"`
 }
 
 // ChatMessages is a list of ChatMessage.
@@ -144,6 +144,15 @@ func (m ChatMessages) String() string {
 	return strings.Join(output, "\n")
 }
 
+// Contents returns the contents of the messages, one per line, without their roles.
+func (m ChatMessages) Contents() string {
+	var output []string
+	for _, msg := range m {
+		output = append(output, msg.Content)
+	}
+	return strings.Join(output, "\n")
+}
+
 // Last returns the last N messages from the list.
 func (m ChatMessages) Last(size int) ChatMessages {
 	if len(m) < size {
@@ -182,36 +191,86 @@ func ChatLLM(model ChatLanguageModel) HandlerFunc {
 
 // Memory is an interface that can be used to store and retrieve previous conversations.
 type Memory interface {
-	// Load returns previous conversations from the memory
-	Load(context.Context) (ChatMessages, error)
+	// Load returns previous conversations from the memory. The current input is passed so
+	// implementations can select the messages relevant to it
+	Load(ctx context.Context, input string) (ChatMessages, error)
+
+	// Save adds the messages of the last exchange to the memory. Usually a question/answer pair,
+	// but it can also include other messages, like system and tool messages
+	Save(ctx context.Context, messages ChatMessages) error
+}
 
-	// Save last question/answer to the memory
-	Save(ctx context.Context, input, output string) error
+// MemoryOptions for the WithMemory handler
+type MemoryOptions struct {
+	// InputKey is the key of the input value used as the question. If empty, the only key of the
+	// input values is used, ignoring the DefaultChatKey and DefaultSessionKey keys. If there are
+	// multiple keys, the DefaultKey key is used, if available
+	InputKey string
+	// OutputKey is the key of the output value used as the answer. Defaults to DefaultKey
+	OutputKey string
+	// PersistKeys are the keys of the input values saved in the memory, in order, before the
+	// answer. Defaults to the InputKey
+	PersistKeys []string
 }
 
 // WithMemory is a wrapper that loads the previous conversation from the memory,
 // injects it into the chain as the value of the DefaultChatKey key, calls the wrapped handler,
 // and adds the last question/answer to the memory.
-func WithMemory(memory Memory, handler Handler) HandlerFunc {
+//
+// The input and output values can be strings, saved as user and assistant messages respectively,
+// or ChatMessage/ChatMessages, saved as they are. Use MemoryOptions to select which values are
+// used and saved.
+//
+// If the values have a DefaultSessionKey key, its value is used as the session ID of the
+// conversation, overriding the one set in the context with WithSessionID. This way a single chain
+// can serve multiple users.
+func WithMemory(memory Memory, handler Handler, opts ...MemoryOptions) HandlerFunc {
+	var o MemoryOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.OutputKey == "" {
+		o.OutputKey = DefaultKey
+	}
 	return func(ctx context.Context, values ...Values) (Values, error) {
 		vals := Values{}.Merge(values...)
-		history, err := memory.Load(ctx)
+		if sessionID, ok := vals[DefaultSessionKey].(string); ok && sessionID != "" {
+			ctx = WithSessionID(ctx, sessionID)
+		}
+		inputKey, err := memoryInputKey(vals, o.InputKey)
 		if err != nil {
 			return nil, err
 		}
-		outputVals, err := handler.Call(ctx, vals.Merge(Values{DefaultChatKey: history}))
+		persistKeys := o.PersistKeys
+		if len(persistKeys) == 0 {
+			persistKeys = []string{inputKey}
+		}
+		var messages ChatMessages
+		for _, key := range persistKeys {
+			msgs, err := toChatMessages(key, vals[key], "user")
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, msgs...)
+		}
+		input, err := toChatMessages(inputKey, vals[inputKey], "user")
+		if err != nil {
+			return nil, err
+		}
+
+		history, err := memory.Load(ctx, input.Contents())
 		if err != nil {
 			return nil, err
 		}
-		input, err := getValue(vals, "")
+		outputVals, err := handler.Call(ctx, vals.Merge(Values{DefaultChatKey: history}))
 		if err != nil {
 			return nil, err
 		}
-		output, err := getValue(outputVals, DefaultKey)
+		output, err := toChatMessages(o.OutputKey, outputVals[o.OutputKey], "assistant")
 		if err != nil {
 			return nil, err
 		}
-		err = memory.Save(ctx, input, output)
+		err = memory.Save(ctx, append(messages, output...))
 		if err != nil {
 			return nil, err
 		}
@@ -219,23 +278,42 @@ func WithMemory(memory Memory, handler Handler) HandlerFunc {
 	}
 }
 
-// getValue returns the value of the given key from the given Values object.
-// If the key is empty, it returns the value of the first key in the Values object.
-// If the Values object has multiple keys, it returns an error.
-func getValue(values Values, key string) (string, error) {
-	ret := func(v any) (string, error) {
-		if s, ok := v.(string); !ok {
-			return "", fmt.Errorf("input value is not a string: %v", v)
-		} else {
-			return s, nil
-		}
-	}
+// memoryInputKey returns the key of the value used as the question by WithMemory. If the key is
+// empty, it returns the only key in the Values object, ignoring the special DefaultChatKey and
+// DefaultSessionKey keys. If the Values object has multiple keys, it returns DefaultKey if
+// available, or an error otherwise.
+func memoryInputKey(values Values, key string) (string, error) {
 	if key != "" {
-		return ret(values[key])
+		return key, nil
+	}
+	var keys []string
+	for _, k := range values.Keys() {
+		if k != DefaultChatKey && k != DefaultSessionKey {
+			keys = append(keys, k)
+		}
 	}
-	keys := values.Keys()
 	if len(keys) == 1 {
-		return ret(values[keys[0]])
+		return keys[0], nil
+	}
+	if _, ok := values[DefaultKey]; ok {
+		return DefaultKey, nil
+	}
+	return "", fmt.Errorf("input values have multiple keys, use MemoryOptions.InputKey to choose one: %v", keys)
+}
+
+// toChatMessages converts a value to be saved in the memory. Strings are converted to a message
+// with the given role, ChatMessage and ChatMessages are returned as they are.
+func toChatMessages(key string, value any, role string) (ChatMessages, error) {
+	switch v := value.(type) {
+	case string:
+		return ChatMessages{{Role: role, Content: v}}, nil
+	case ChatMessage:
+		return ChatMessages{v}, nil
+	case ChatMessages:
+		return v, nil
+	case []ChatMessage:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("value of %q is not a string or chat messages: %v", key, value)
 	}
-	return "", fmt.Errorf("input values have multiple keys, memory only supported when one key currently: %v", keys)
 }
@@ -156,6 +156,7 @@ var _ = Describe("Handlers", func() {
 			result, err := chain.Call(context.Background(), Values{DefaultKey: "input"})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(HaveKeyWithValue(DefaultKey, "output"))
+			Expect(memory.LoadedInput).To(Equal("input"))
 			Expect(memory.ChatMessages).To(Equal(ChatMessages{
 				{"user", "previous conversation"},
 				{"user", "input"},
@@ -163,6 +164,104 @@ var _ = Describe("Handlers", func() {
 			}))
 		})
 
+		It("should use the session from the values, ignoring it as input", func() {
+			memory := &fakeMemory{}
+			handler := HandlerFunc(func(ctx context.Context, values ...Values) (Values, error) {
+				Expect(SessionID(ctx)).To(Equal("user-1"))
+				return Values{DefaultKey: "output"}, nil
+			})
+			chain := WithMemory(memory, handler)
+
+			ctx := WithSessionID(context.Background(), "default")
+			result, err := chain.Call(ctx, Values{"question": "input", DefaultSessionKey: "user-1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HaveKeyWithValue(DefaultKey, "output"))
+			Expect(memory.LoadedInput).To(Equal("input"))
+			Expect(memory.SessionID).To(Equal("user-1"))
+		})
+
+		It("should keep the session from the context if there is none in the values", func() {
+			memory := &fakeMemory{}
+			handler := HandlerFunc(func(ctx context.Context, values ...Values) (Values, error) {
+				return Values{DefaultKey: "output"}, nil
+			})
+			chain := WithMemory(memory, handler)
+
+			_, err := chain.Call(WithSessionID(context.Background(), "user-2"), Values{DefaultKey: "input"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(memory.SessionID).To(Equal("user-2"))
+		})
+
+		It("should use the DefaultKey as input when there are multiple keys", func() {
+			memory := &fakeMemory{}
+			handler := HandlerFunc(func(ctx context.Context, values ...Values) (Values, error) {
+				return Values{DefaultKey: "output"}, nil
+			})
+			chain := WithMemory(memory, handler)
+
+			_, err := chain.Call(context.Background(), Values{DefaultKey: "input", "language": "Portuguese"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(memory.LoadedInput).To(Equal("input"))
+			Expect(memory.ChatMessages).To(Equal(ChatMessages{{"user", "input"}, {"assistant", "output"}}))
+		})
+
+		It("should return an error if there are multiple keys and no InputKey", func() {
+			memory := &fakeMemory{}
+			handler := HandlerFunc(func(ctx context.Context, values ...Values) (Values, error) {
+				return Values{DefaultKey: "output"}, nil
+			})
+			chain := WithMemory(memory, handler)
+
+			_, err := chain.Call(context.Background(), Values{"question": "input", "language": "Portuguese"})
+			Expect(err).To(MatchError(ContainSubstring("use MemoryOptions.InputKey")))
+		})
+
+		It("should use the configured input, output and persisted keys", func() {
+			memory := &fakeMemory{}
+			handler := HandlerFunc(func(ctx context.Context, values ...Values) (Values, error) {
+				Expect(values[0]).To(HaveKeyWithValue("language", "Portuguese"))
+				return Values{"answer": "resposta", "debug": "ignored"}, nil
+			})
+			chain := WithMemory(memory, handler, MemoryOptions{
+				InputKey:    "question",
+				OutputKey:   "answer",
+				PersistKeys: []string{"context", "question"},
+			})
+
+			result, err := chain.Call(context.Background(), Values{
+				"question": "input",
+				"language": "Portuguese",
+				"context":  ChatMessage{Role: "system", Content: "Answer in {language}"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HaveKeyWithValue("answer", "resposta"))
+			Expect(memory.LoadedInput).To(Equal("input"))
+			Expect(memory.ChatMessages).To(Equal(ChatMessages{
+				{"system", "Answer in {language}"},
+				{"user", "input"},
+				{"assistant", "resposta"},
+			}))
+		})
+
+		It("should save chat messages returned by the handler as they are", func() {
+			memory := &fakeMemory{}
+			handler := HandlerFunc(func(ctx context.Context, values ...Values) (Values, error) {
+				return Values{DefaultKey: ChatMessages{
+					{"tool", `{"temperature": 25}`},
+					{"assistant", "It is 25 degrees"},
+				}}, nil
+			})
+			chain := WithMemory(memory, handler)
+
+			_, err := chain.Call(context.Background(), Values{DefaultKey: "How hot is it?"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(memory.ChatMessages).To(Equal(ChatMessages{
+				{"user", "How hot is it?"},
+				{"tool", `{"temperature": 25}`},
+				{"assistant", "It is 25 degrees"},
+			}))
+		})
+
 		It("should return an error if loading from memory fails", func() {
 			memory := &fakeMemory{
 				LoadErr: errors.New("load error"),
@@ -222,21 +321,25 @@ var _ = Describe("Handlers", func() {
 
 type fakeMemory struct {
 	ChatMessages ChatMessages
+	LoadedInput  string
+	SessionID    string
 	SaveErr      error
 	LoadErr      error
 }
 
-func (m *fakeMemory) Load(context.Context) (ChatMessages, error) {
+func (m *fakeMemory) Load(_ context.Context, input string) (ChatMessages, error) {
+	m.LoadedInput = input
 	if m.LoadErr != nil {
 		return nil, m.LoadErr
 	}
 	return m.ChatMessages, nil
 }
 
-func (m *fakeMemory) Save(_ context.Context, input, output string) error {
+func (m *fakeMemory) Save(ctx context.Context, messages ChatMessages) error {
+	m.SessionID = SessionID(ctx)
 	if m.SaveErr != nil {
 		return m.SaveErr
 	}
-	m.ChatMessages = append(m.ChatMessages, ChatMessage{Role: "user", Content: input}, ChatMessage{Role: "assistant", Content: output})
+	m.ChatMessages = append(m.ChatMessages, messages...)
 	return nil
 }
@@ -31,9 +31,42 @@ type ScoredDocument struct {
 	Score float32
 }
 
+// Retriever is the interface implemented by types that can fetch the documents most relevant to a query.
+type Retriever interface {
+	// Retrieve returns the k most relevant documents for the query, along with their relevance score
+	Retrieve(ctx context.Context, query string, k int) ([]ScoredDocument, error)
+}
+
+// RetrieverFunc is an adapter to allow the use of ordinary functions as Retrievers.
+type RetrieverFunc func(ctx context.Context, query string, k int) ([]ScoredDocument, error)
+
+func (f RetrieverFunc) Retrieve(ctx context.Context, query string, k int) ([]ScoredDocument, error) {
+	return f(ctx, query, k)
+}
+
 // Splitter is a function that splits a string into a slice of strings.
 type Splitter = func(string) ([]string, error)
 
+// ContextSplitter is a Splitter that takes a context, for splitters that call external services,
+// like the SemanticSplitter.
+type ContextSplitter = func(context.Context, string) ([]string, error)
+
+// Chunk is a piece of a text returned by a ChunkSplitter, along with its position in the text.
+type Chunk struct {
+	// Text of the chunk
+	Text string
+	// StartIndex and EndIndex are the byte offsets of the chunk in the text, with EndIndex being
+	// exclusive. Both are -1 if the chunk could not be located in the text
+	StartIndex, EndIndex int
+	// StartLine and EndLine are the lines of the text where the chunk starts and ends, starting
+	// at 1. Both are 0 if the chunk could not be located in the text
+	StartLine, EndLine int
+}
+
+// ChunkSplitter is a function that splits a string into chunks, recording the position of each
+// chunk in the string. Use ToChunkSplitter to convert a Splitter into a ChunkSplitter.
+type ChunkSplitter = func(string) ([]Chunk, error)
+
 // DocumentLoader is the interface implemented by types that can load documents.
 // The LoadNext method should the next available document, or io.EOF if there are no more documents.
 type DocumentLoader interface {
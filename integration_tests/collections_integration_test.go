@@ -0,0 +1,165 @@
+package integration_tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/memory"
+	"github.com/deluan/flowllm/vectorstores"
+	"github.com/deluan/flowllm/vectorstores/bolt"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.etcd.io/bbolt"
+)
+
+type collectionsStore interface {
+	flowllm.VectorStore
+	CreateCollection(ctx context.Context, name string) error
+	ListCollections(ctx context.Context) ([]string, error)
+	DropCollection(ctx context.Context, name string) error
+}
+
+var _ = Describe("Vector Stores Collections Integration Tests", func() {
+	var (
+		boltVS         *bolt.VectorStore
+		memoryVS       *vectorstores.Memory
+		ctx            context.Context
+		mockEmbeddings *FakeEmbeddings
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		mockEmbeddings = &FakeEmbeddings{}
+
+		memoryVS = vectorstores.NewMemoryVectorStore(mockEmbeddings)
+
+		boltTmpDB, err := os.CreateTemp("", "flowllm_bolt_*_.db")
+		Expect(err).ToNot(HaveOccurred())
+		_ = boltTmpDB.Close()
+		var closeDB func()
+		boltVS, closeDB, err = bolt.NewVectorStore(mockEmbeddings, bolt.Options{Path: boltTmpDB.Name()})
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(closeDB)
+		DeferCleanup(func() { _ = os.Remove(boltTmpDB.Name()) })
+	})
+
+	DescribeTable("It should keep documents from different collections separated",
+		func(getStore func() collectionsStore, collection func(string) flowllm.VectorStore) {
+			store := getStore()
+			Expect(store.AddDocuments(ctx, flowllm.Document{PageContent: "default document"})).To(Succeed())
+			Expect(collection("tenant1").AddDocuments(ctx, flowllm.Document{PageContent: "tenant1 document"})).To(Succeed())
+			Expect(collection("tenant2").AddDocuments(ctx,
+				flowllm.Document{PageContent: "tenant2 first document"},
+				flowllm.Document{PageContent: "tenant2 second document"},
+			)).To(Succeed())
+
+			docs, err := store.SimilaritySearch(ctx, "1", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(docs).To(HaveLen(1))
+			Expect(docs[0].PageContent).To(Equal("default document"))
+
+			docs, err = collection("tenant1").SimilaritySearch(ctx, "1", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(docs).To(HaveLen(1))
+			Expect(docs[0].PageContent).To(Equal("tenant1 document"))
+
+			docs, err = collection("tenant2").SimilaritySearch(ctx, "2", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(docs).To(HaveLen(2))
+			Expect(docs[0].PageContent).To(Equal("tenant2 second document"))
+		},
+		Entry("Memory", func() collectionsStore { return memoryVS }, func(name string) flowllm.VectorStore { return memoryVS.Collection(name) }),
+		Entry("Bolt", func() collectionsStore { return boltVS }, func(name string) flowllm.VectorStore { return boltVS.Collection(name) }),
+	)
+
+	DescribeTable("It should create, list and drop collections",
+		func(getStore func() collectionsStore, defaultCollection string) {
+			store := getStore()
+			Expect(store.CreateCollection(ctx, "tenant2")).To(Succeed())
+			Expect(store.CreateCollection(ctx, "tenant1")).To(Succeed())
+			Expect(store.CreateCollection(ctx, "tenant1")).To(Succeed())
+
+			names, err := store.ListCollections(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names).To(Equal([]string{defaultCollection, "tenant1", "tenant2"}))
+
+			Expect(store.DropCollection(ctx, "tenant1")).To(Succeed())
+			names, err = store.ListCollections(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names).To(Equal([]string{defaultCollection, "tenant2"}))
+
+			Expect(store.DropCollection(ctx, "tenant1")).To(MatchError(vectorstores.ErrCollectionNotFound))
+		},
+		Entry("Memory", func() collectionsStore { return memoryVS }, vectorstores.DefaultCollection),
+		Entry("Bolt", func() collectionsStore { return boltVS }, bolt.DefaultBucket),
+	)
+
+	DescribeTable("It should remove the documents of a dropped collection",
+		func(getStore func() collectionsStore, collection func(string) flowllm.VectorStore) {
+			store := getStore()
+			Expect(collection("tenant1").AddDocuments(ctx, flowllm.Document{PageContent: "tenant1 document"})).To(Succeed())
+			Expect(store.DropCollection(ctx, "tenant1")).To(Succeed())
+
+			docs, err := collection("tenant1").SimilaritySearch(ctx, "1", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(docs).To(BeEmpty())
+		},
+		Entry("Memory", func() collectionsStore { return memoryVS }, func(name string) flowllm.VectorStore { return memoryVS.Collection(name) }),
+		Entry("Bolt", func() collectionsStore { return boltVS }, func(name string) flowllm.VectorStore { return boltVS.Collection(name) }),
+	)
+
+	It("should not list or drop other buckets of the Bolt database", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "shared.db")
+		db, err := bbolt.Open(path, 0600, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = memory.NewBoltChatHistory(db, "chat_history")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(db.Close()).To(Succeed())
+
+		store, closeDB, err := bolt.NewVectorStore(mockEmbeddings, bolt.Options{Path: path})
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(closeDB)
+
+		names, err := store.ListCollections(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(names).To(Equal([]string{bolt.DefaultBucket}))
+		Expect(store.DropCollection(ctx, "chat_history")).To(MatchError(vectorstores.ErrCollectionNotFound))
+	})
+
+	It("should migrate the document IDs of Bolt databases written by older versions", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "old.db")
+		db, err := bbolt.Open(path, 0600, nil)
+		Expect(err).ToNot(HaveOccurred())
+		vector, _ := mockEmbeddings.EmbedString(ctx, "old document")
+		item, _ := json.Marshal(map[string]any{"vectors": vector, "content": "old document", "metadata": map[string]any{"page": 1}})
+		Expect(db.Update(func(tx *bbolt.Tx) error {
+			bucket, err := tx.CreateBucket([]byte(bolt.DefaultBucket))
+			if err != nil {
+				return err
+			}
+			// Older versions used the hash of the stored item as the ID of documents without one
+			if err := bucket.Put([]byte(fmt.Sprintf("%x", sha256.Sum256(item))), item); err != nil {
+				return err
+			}
+			return bucket.Put([]byte("doc1"), item)
+		})).To(Succeed())
+		Expect(db.Close()).To(Succeed())
+
+		store, closeDB, err := bolt.NewVectorStore(mockEmbeddings, bolt.Options{Path: path})
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(closeDB)
+		Expect(store.AddDocuments(ctx, flowllm.Document{PageContent: "old document", Metadata: map[string]any{"page": 1}})).To(Succeed())
+
+		docs, err := store.SimilaritySearchVectorWithScore(ctx, vector, 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(2))
+		ids := []string{docs[0].ID, docs[1].ID}
+		Expect(ids).To(ConsistOf("doc1", vectorstores.DocumentID(flowllm.Document{PageContent: "old document", Metadata: map[string]any{"page": 1}})))
+	})
+})
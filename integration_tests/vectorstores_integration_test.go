@@ -9,6 +9,7 @@ import (
 	"github.com/deluan/flowllm/vectorstores"
 	"github.com/deluan/flowllm/vectorstores/bolt"
 	"github.com/deluan/flowllm/vectorstores/pinecone"
+	"github.com/deluan/flowllm/vectorstores/sqlite"
 	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -18,6 +19,7 @@ var _ = Describe("Vector Stores Integration Tests", func() {
 	var (
 		boltVS         flowllm.VectorStore
 		memoryVS       flowllm.VectorStore
+		sqliteVS       flowllm.VectorStore
 		pineconeVS     flowllm.VectorStore
 		ctx            context.Context
 		mockEmbeddings *FakeEmbeddings
@@ -41,6 +43,16 @@ var _ = Describe("Vector Stores Integration Tests", func() {
 		DeferCleanup(closeDB)
 		DeferCleanup(func() { _ = os.Remove(boltTmpDB.Name()) })
 
+		// Create a SQLite VectorStore
+		sqliteTmpDB, err := os.CreateTemp("", "flowllm_sqlite_*_.db")
+		Expect(err).ToNot(HaveOccurred())
+		_ = sqliteTmpDB.Close()
+		var closeSQLite func()
+		sqliteVS, closeSQLite, err = sqlite.NewVectorStore(mockEmbeddings, sqlite.Options{Path: sqliteTmpDB.Name()})
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(closeSQLite)
+		DeferCleanup(func() { _ = os.Remove(sqliteTmpDB.Name()) })
+
 		if os.Getenv("PINECONE_API_KEY") != "" {
 			// Create a Pinecone VectorStore
 			pineconeVS, err = pinecone.NewVectorStore(ctx, mockEmbeddings,
@@ -84,6 +96,7 @@ var _ = Describe("Vector Stores Integration Tests", func() {
 		},
 		Entry("Memory", func() flowllm.VectorStore { return memoryVS }),
 		Entry("Bolt", func() flowllm.VectorStore { return boltVS }),
+		Entry("SQLite", func() flowllm.VectorStore { return sqliteVS }),
 		Entry("Pinecone", func() flowllm.VectorStore { return pineconeVS }),
 	)
 
@@ -111,6 +124,7 @@ var _ = Describe("Vector Stores Integration Tests", func() {
 		},
 		Entry("Memory", func() flowllm.VectorStore { return memoryVS }),
 		Entry("Bolt", func() flowllm.VectorStore { return boltVS }),
+		Entry("SQLite", func() flowllm.VectorStore { return sqliteVS }),
 		Entry("Pinecone", func() flowllm.VectorStore { return pineconeVS }),
 	)
 
@@ -137,6 +151,7 @@ var _ = Describe("Vector Stores Integration Tests", func() {
 		},
 		Entry("Memory", func() flowllm.VectorStore { return memoryVS }),
 		Entry("Bolt", func() flowllm.VectorStore { return boltVS }),
+		Entry("SQLite", func() flowllm.VectorStore { return sqliteVS }),
 		Entry("Pinecone", func() flowllm.VectorStore { return pineconeVS }),
 	)
 
@@ -155,8 +170,96 @@ var _ = Describe("Vector Stores Integration Tests", func() {
 		},
 		Entry("Memory", func() flowllm.VectorStore { return memoryVS }),
 		Entry("Bolt", func() flowllm.VectorStore { return boltVS }),
+		Entry("SQLite", func() flowllm.VectorStore { return sqliteVS }),
 		Entry("Pinecone", func() flowllm.VectorStore { return pineconeVS }),
 	)
+
+	DescribeTable("It should apply the search options to the results",
+		func(getStore func(opts vectorstores.SearchOptions) flowllm.VectorStore) {
+			store := getStore(vectorstores.SearchOptions{NormalizeScores: true, MinScore: 0.99})
+			if store == nil {
+				Skip("Skipping test. No VectorStore found.")
+			}
+			documents := []flowllm.Document{
+				{PageContent: "first document"},
+				{PageContent: "second document"},
+			}
+			Expect(store.AddDocuments(ctx, documents...)).To(Succeed())
+
+			queryVector, _ := mockEmbeddings.EmbedString(ctx, "1")
+			scoredDocs, err := store.SimilaritySearchVectorWithScore(ctx, queryVector, 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(scoredDocs).To(HaveLen(1))
+			Expect(scoredDocs[0].PageContent).To(Equal(documents[0].PageContent))
+			Expect(scoredDocs[0].Score).To(BeNumerically("~", 1, 1e-5))
+
+			similarDocs, err := store.SimilaritySearch(ctx, "1", 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(similarDocs).To(HaveLen(1))
+		},
+		Entry("Memory", func(opts vectorstores.SearchOptions) flowllm.VectorStore {
+			return memoryVS.(*vectorstores.Memory).WithSearchOptions(opts)
+		}),
+		Entry("Bolt", func(opts vectorstores.SearchOptions) flowllm.VectorStore {
+			return boltVS.(*bolt.VectorStore).WithSearchOptions(opts)
+		}),
+		Entry("SQLite", func(opts vectorstores.SearchOptions) flowllm.VectorStore {
+			return sqliteVS.(*sqlite.VectorStore).WithSearchOptions(opts)
+		}),
+		Entry("Pinecone", func(opts vectorstores.SearchOptions) flowllm.VectorStore {
+			if pineconeVS == nil {
+				return nil
+			}
+			return pineconeVS.(*pinecone.VectorStore).WithSearchOptions(opts)
+		}),
+	)
+
+	DescribeTable("It should update documents by ID",
+		func(getStore func() flowllm.VectorStore) {
+			store := getStore().(interface {
+				flowllm.VectorStore
+				UpdateDocuments(ctx context.Context, documents ...flowllm.Document) error
+			})
+			documents := []flowllm.Document{
+				{ID: "doc1", PageContent: "first document", Metadata: map[string]any{"version": "1"}},
+				{ID: "doc2", PageContent: "second document"},
+			}
+			Expect(store.AddDocuments(ctx, documents...)).To(Succeed())
+
+			err := store.UpdateDocuments(ctx, flowllm.Document{ID: "doc1", PageContent: "first document", Metadata: map[string]any{"version": "2"}})
+			Expect(err).ToNot(HaveOccurred())
+			err = store.UpdateDocuments(ctx, flowllm.Document{ID: "missing", PageContent: "missing document"})
+			Expect(err).To(MatchError(vectorstores.ErrDocumentNotFound))
+
+			queryVector, _ := mockEmbeddings.EmbedString(ctx, "1")
+			scoredDocs, err := store.SimilaritySearchVectorWithScore(ctx, queryVector, 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(scoredDocs).To(HaveLen(2))
+			Expect(scoredDocs[0].ID).To(Equal("doc1"))
+			Expect(scoredDocs[0].Metadata).To(Equal(map[string]any{"version": "2"}))
+			Expect(scoredDocs[1].ID).To(Equal("doc2"))
+		},
+		Entry("Memory", func() flowllm.VectorStore { return memoryVS }),
+		Entry("Bolt", func() flowllm.VectorStore { return boltVS }),
+		Entry("SQLite", func() flowllm.VectorStore { return sqliteVS }),
+	)
+
+	DescribeTable("It should derive the same ID for documents without one",
+		func(getStore func() flowllm.VectorStore) {
+			store := getStore()
+			doc := flowllm.Document{PageContent: "first document", Metadata: map[string]any{"source": "a.txt"}}
+			Expect(store.AddDocuments(ctx, doc)).To(Succeed())
+
+			queryVector, _ := mockEmbeddings.EmbedString(ctx, "1")
+			scoredDocs, err := store.SimilaritySearchVectorWithScore(ctx, queryVector, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(scoredDocs).To(HaveLen(1))
+			Expect(scoredDocs[0].ID).To(Equal(vectorstores.DocumentID(doc)))
+		},
+		Entry("Memory", func() flowllm.VectorStore { return memoryVS }),
+		Entry("Bolt", func() flowllm.VectorStore { return boltVS }),
+		Entry("SQLite", func() flowllm.VectorStore { return sqliteVS }),
+	)
 })
 
 type FakeEmbeddings struct{}
@@ -0,0 +1,329 @@
+package loaders
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/pl"
+)
+
+const (
+	defaultCrawlerMaxParallel = 4
+	defaultCrawlerUserAgent   = "flowllm"
+)
+
+// CrawlerOptions for the URL and Sitemap loaders
+type CrawlerOptions struct {
+	// HTTPClient used to fetch the pages. Defaults to http.DefaultClient
+	HTTPClient *http.Client
+	// UserAgent sent in the requests, and used to select the rules of the robots.txt files.
+	// Defaults to "flowllm"
+	UserAgent string
+	// MaxDepth is how many levels of links are followed from the start pages. Only links to the
+	// same host as the page are followed. Defaults to 0, loading only the start pages
+	MaxDepth int
+	// MaxPages is the maximum number of pages loaded. Defaults to no limit
+	MaxPages int
+	// MaxParallel is the maximum number of concurrent requests. Defaults to 4
+	MaxParallel int
+	// Delay is the minimum interval between requests. Defaults to no delay
+	Delay time.Duration
+	// IgnoreRobotsTxt disables checking the robots.txt files before fetching the pages
+	IgnoreRobotsTxt bool
+	// HTML are the options used to convert the pages to documents, including the Splitter
+	HTML HTMLOptions
+}
+
+// URL creates a DocumentLoader that loads a web page, and optionally the pages linked from it, up
+// to CrawlerOptions.MaxDepth. The pages are converted to documents like the HTML loader does, with
+// the URL of the page as the "source" metadata. Pages are fetched concurrently, so the order of
+// the documents is not deterministic.
+//
+// An error is returned if the start page can't be loaded. Linked pages that fail to load, are not
+// HTML or are disallowed by robots.txt are skipped.
+func URL(pageURL string, opts CrawlerOptions) flowllm.DocumentLoaderFunc {
+	return startCrawler(opts, func(ctx context.Context, c *crawler) ([]crawlPage, error) {
+		u, err := url.Parse(pageURL)
+		if err != nil {
+			return nil, err
+		}
+		return []crawlPage{{url: u, required: true}}, nil
+	})
+}
+
+// Sitemap creates a DocumentLoader that loads all pages listed in a sitemap, following sitemap
+// indexes. Like the URL loader, it can also load the pages linked from them. An error is returned
+// if the sitemap can't be loaded, but pages that fail to load are skipped.
+func Sitemap(sitemapURL string, opts CrawlerOptions) flowllm.DocumentLoaderFunc {
+	return startCrawler(opts, func(ctx context.Context, c *crawler) ([]crawlPage, error) {
+		var pages []crawlPage
+		visited := map[string]bool{}
+		var load func(string) error
+		load = func(sitemapURL string) error {
+			if visited[sitemapURL] {
+				return nil
+			}
+			visited[sitemapURL] = true
+			sitemap, err := c.fetchSitemap(ctx, sitemapURL)
+			if err != nil {
+				return err
+			}
+			for _, entry := range sitemap.URLs {
+				u, err := url.Parse(entry.Loc)
+				if err != nil {
+					continue
+				}
+				pages = append(pages, crawlPage{url: u})
+			}
+			for _, entry := range sitemap.Sitemaps {
+				if err := load(entry.Loc); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		err := load(sitemapURL)
+		return pages, err
+	})
+}
+
+type crawlPage struct {
+	url      *url.URL
+	depth    int
+	required bool
+}
+
+type crawlResult struct {
+	docs  []flowllm.Document
+	links []*url.URL
+}
+
+type crawler struct {
+	opts   CrawlerOptions
+	robots map[string]*robotsRules
+}
+
+func startCrawler(opts CrawlerOptions, seeds func(context.Context, *crawler) ([]crawlPage, error)) flowllm.DocumentLoaderFunc {
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = defaultCrawlerUserAgent
+	}
+	if opts.MaxParallel == 0 {
+		opts.MaxParallel = defaultCrawlerMaxParallel
+	}
+	return channelLoader(func(ctx context.Context) (chan []flowllm.Document, chan error, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(ctx)
+		docsC := make(chan []flowllm.Document)
+		finalErrC := make(chan error, 1)
+		go func() {
+			c := &crawler{opts: opts, robots: map[string]*robotsRules{}}
+			err := c.crawl(ctx, seeds, docsC)
+			close(docsC)
+			finalErrC <- err
+		}()
+		return docsC, finalErrC, cancel
+	})
+}
+
+// crawl loads the pages level by level, sending their documents to docsC. The links found in
+// each level are the pages of the next level.
+func (c *crawler) crawl(ctx context.Context, seeds func(context.Context, *crawler) ([]crawlPage, error), docsC chan<- []flowllm.Document) error {
+	pages, err := seeds(ctx, c)
+	if err != nil {
+		return err
+	}
+	visited := map[string]bool{}
+	var frontier []crawlPage
+	for _, page := range pages {
+		page.url.Fragment = ""
+		if visited[page.url.String()] {
+			continue
+		}
+		visited[page.url.String()] = true
+		if !c.allowed(ctx, page.url) {
+			if page.required {
+				return fmt.Errorf("%s: disallowed by robots.txt", page.url)
+			}
+			continue
+		}
+		frontier = append(frontier, page)
+	}
+
+	loaded := 0
+	for depth := 0; len(frontier) > 0; depth++ {
+		if c.opts.MaxPages > 0 && loaded+len(frontier) > c.opts.MaxPages {
+			frontier = frontier[:c.opts.MaxPages-loaded]
+		}
+		loaded += len(frontier)
+		if depth > 0 && c.opts.Delay > 0 {
+			select {
+			case <-time.After(c.opts.Delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		next, err := c.crawlLevel(ctx, depth, frontier, visited, docsC)
+		if err != nil {
+			return err
+		}
+		frontier = next
+	}
+	return ctx.Err()
+}
+
+// crawlLevel fetches the pages concurrently, and returns the pages linked from them that were not
+// visited yet.
+func (c *crawler) crawlLevel(ctx context.Context, depth int, pages []crawlPage, visited map[string]bool, docsC chan<- []flowllm.Document) ([]crawlPage, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resC, errC := pl.Stage(ctx, c.opts.MaxParallel, pl.Throttle(ctx, pl.FromSlice(ctx, pages), c.opts.Delay), c.fetch)
+	finalErrC := make(chan error, 1)
+	go func() {
+		var firstErr error
+		for err := range errC {
+			if firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+		}
+		finalErrC <- firstErr
+	}()
+
+	var next []crawlPage
+	for res := range resC {
+		if len(res.docs) > 0 {
+			pl.SendOrDone(ctx, docsC, res.docs)
+		}
+		for _, link := range res.links {
+			if visited[link.String()] || !c.allowed(ctx, link) {
+				continue
+			}
+			visited[link.String()] = true
+			next = append(next, crawlPage{url: link, depth: depth + 1})
+		}
+	}
+	if err := <-finalErrC; err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// fetch loads the page. Errors are only returned for required pages, other pages are skipped.
+func (c *crawler) fetch(ctx context.Context, page crawlPage) (crawlResult, error) {
+	res, err := c.fetchPage(ctx, page)
+	if err != nil && !page.required {
+		return crawlResult{}, nil
+	}
+	return res, err
+}
+
+func (c *crawler) fetchPage(ctx context.Context, page crawlPage) (crawlResult, error) {
+	resp, err := c.get(ctx, page.url.String())
+	if err != nil {
+		return crawlResult{}, err
+	}
+	defer resp.Body.Close()
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return crawlResult{}, fmt.Errorf("%s: unsupported content type %q", page.url, mediaType)
+	}
+
+	doc, err := parseHTML(resp.Body, c.opts.HTML)
+	if err != nil {
+		return crawlResult{}, fmt.Errorf("%s: %w", page.url, err)
+	}
+	doc.Metadata["source"] = page.url.String()
+
+	var result crawlResult
+	if page.depth < c.opts.MaxDepth {
+		// Links are relative to the final URL, after redirects
+		base := resp.Request.URL
+		hrefs, _ := doc.Metadata["links"].([]string)
+		for _, href := range hrefs {
+			link, err := base.Parse(href)
+			if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host != base.Host {
+				continue
+			}
+			link.Fragment = ""
+			result.links = append(result.links, link)
+		}
+	}
+
+	result.docs = []flowllm.Document{doc}
+	if c.opts.HTML.Splitter != nil {
+		result.docs, err = SplitDocuments(c.opts.HTML.Splitter, result.docs)
+	}
+	return result, err
+}
+
+func (c *crawler) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+	resp, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%s: unexpected status %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+// allowed checks the robots.txt of the host of the URL, fetching it on the first call for each
+// host. If the robots.txt can't be fetched, all URLs of the host are allowed.
+func (c *crawler) allowed(ctx context.Context, u *url.URL) bool {
+	if c.opts.IgnoreRobotsTxt {
+		return true
+	}
+	key := u.Scheme + "://" + u.Host
+	rules, ok := c.robots[key]
+	if !ok {
+		resp, err := c.get(ctx, key+"/robots.txt")
+		if err == nil {
+			rules = parseRobots(resp.Body, c.opts.UserAgent)
+			_ = resp.Body.Close()
+		}
+		c.robots[key] = rules
+	}
+	return rules.allowed(u.RequestURI())
+}
+
+type sitemapXML struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+func (c *crawler) fetchSitemap(ctx context.Context, sitemapURL string) (*sitemapXML, error) {
+	resp, err := c.get(ctx, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var sitemap sitemapXML
+	if err := xml.NewDecoder(resp.Body).Decode(&sitemap); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: empty sitemap", sitemapURL)
+		}
+		return nil, fmt.Errorf("%s: %w", sitemapURL, err)
+	}
+	return &sitemap, nil
+}
@@ -0,0 +1,190 @@
+package loaders_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Crawler", func() {
+	var (
+		server     *httptest.Server
+		requests   atomic.Int32
+		current    atomic.Int32
+		maxCurrent atomic.Int32
+		userAgents chan string
+		robotsTxt  string
+	)
+
+	page := func(title string, links ...string) string {
+		var body strings.Builder
+		for _, link := range links {
+			body.WriteString(fmt.Sprintf(`<a href="%s">%s</a> `, link, link))
+		}
+		return fmt.Sprintf(`<html><head><title>%s</title></head><body><h1>%s</h1><p>%s</p></body></html>`, title, title, body.String())
+	}
+
+	BeforeEach(func() {
+		requests.Store(0)
+		current.Store(0)
+		maxCurrent.Store(0)
+		userAgents = make(chan string, 100)
+		robotsTxt = "User-agent: *\nDisallow: /private\n"
+		pages := map[string]string{
+			"/":               page("Home", "/a", "b", "/private/secret", "https://other.example.com/x", "#top", "/a#section"),
+			"/a":              page("Page A", "/c"),
+			"/b":              page("Page B", "/"),
+			"/c":              page("Page C"),
+			"/private/secret": page("Secret"),
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			userAgents <- r.UserAgent()
+			c := current.Add(1)
+			defer current.Add(-1)
+			if c > maxCurrent.Load() {
+				maxCurrent.Store(c)
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			switch r.URL.Path {
+			case "/robots.txt":
+				_, _ = w.Write([]byte(robotsTxt))
+			case "/sitemap.xml":
+				_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<sitemap><loc>` + server.URL + `/sitemap-pages.xml</loc></sitemap>
+</sitemapindex>`))
+			case "/sitemap-pages.xml":
+				_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>` + server.URL + `/a</loc></url>
+	<url><loc>` + server.URL + `/c</loc></url>
+	<url><loc>` + server.URL + `/missing</loc></url>
+	<url><loc>` + server.URL + `/notes.txt</loc></url>
+</urlset>`))
+			case "/notes.txt":
+				w.Header().Set("Content-Type", "text/plain")
+				_, _ = w.Write([]byte("Some notes"))
+			default:
+				content, ok := pages[r.URL.Path]
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				_, _ = w.Write([]byte(content))
+			}
+		})
+		server = httptest.NewServer(mux)
+		DeferCleanup(server.Close)
+	})
+
+	titles := func(docs []flowllm.Document) []string {
+		var result []string
+		for _, doc := range docs {
+			result = append(result, doc.Metadata["title"].(string))
+		}
+		return result
+	}
+
+	Describe("URL", func() {
+		It("loads only the start page by default", func() {
+			docs, err := flowllm.LoadDocs(100, loaders.URL(server.URL+"/", loaders.CrawlerOptions{UserAgent: "test-bot"}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(docs).To(HaveLen(1))
+			Expect(docs[0].PageContent).To(HavePrefix("# Home"))
+			Expect(docs[0].Metadata).To(HaveKeyWithValue("source", server.URL+"/"))
+			Expect(docs[0].Metadata).To(HaveKeyWithValue("title", "Home"))
+			Expect(<-userAgents).To(Equal("test-bot"))
+		})
+
+		It("follows same-host links up to the max depth, respecting robots.txt", func() {
+			docs, err := flowllm.LoadDocs(100, loaders.URL(server.URL+"/", loaders.CrawlerOptions{MaxDepth: 1}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(titles(docs)).To(ConsistOf("Home", "Page A", "Page B"))
+
+			docs, err = flowllm.LoadDocs(100, loaders.URL(server.URL+"/", loaders.CrawlerOptions{MaxDepth: 2}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(titles(docs)).To(ConsistOf("Home", "Page A", "Page B", "Page C"))
+		})
+
+		It("uses the robots.txt group of the user agent, even if it allows everything", func() {
+			robotsTxt = "User-agent: test-bot\nDisallow:\n\nUser-agent: *\nDisallow: /private\n"
+			docs, err := flowllm.LoadDocs(100, loaders.URL(server.URL+"/", loaders.CrawlerOptions{MaxDepth: 1, UserAgent: "test-bot"}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(titles(docs)).To(ConsistOf("Home", "Page A", "Page B", "Secret"))
+
+			docs, err = flowllm.LoadDocs(100, loaders.URL(server.URL+"/", loaders.CrawlerOptions{MaxDepth: 1, UserAgent: "other-bot"}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(titles(docs)).To(ConsistOf("Home", "Page A", "Page B"))
+		})
+
+		It("can ignore robots.txt", func() {
+			docs, err := flowllm.LoadDocs(100, loaders.URL(server.URL+"/", loaders.CrawlerOptions{MaxDepth: 1, IgnoreRobotsTxt: true}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(titles(docs)).To(ConsistOf("Home", "Page A", "Page B", "Secret"))
+		})
+
+		It("limits the number of pages", func() {
+			docs, err := flowllm.LoadDocs(100, loaders.URL(server.URL+"/", loaders.CrawlerOptions{MaxDepth: 2, MaxPages: 2}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(docs).To(HaveLen(2))
+		})
+
+		It("limits the number of concurrent requests", func() {
+			_, err := flowllm.LoadDocs(100, loaders.URL(server.URL+"/", loaders.CrawlerOptions{MaxDepth: 2, MaxParallel: 1}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(maxCurrent.Load()).To(Equal(int32(1)))
+		})
+
+		It("waits the delay between requests", func() {
+			start := time.Now()
+			_, err := flowllm.LoadDocs(100, loaders.URL(server.URL+"/", loaders.CrawlerOptions{MaxDepth: 1, Delay: 20 * time.Millisecond}))
+			Expect(err).ToNot(HaveOccurred())
+			// 3 pages, in 2 levels
+			Expect(time.Since(start)).To(BeNumerically(">=", 40*time.Millisecond))
+		})
+
+		It("returns an error if the start page can't be loaded", func() {
+			_, err := flowllm.LoadDocs(100, loaders.URL(server.URL+"/missing", loaders.CrawlerOptions{}))
+			Expect(err).To(MatchError(ContainSubstring("404")))
+
+			_, err = flowllm.LoadDocs(100, loaders.URL(server.URL+"/private/secret", loaders.CrawlerOptions{}))
+			Expect(err).To(MatchError(ContainSubstring("disallowed by robots.txt")))
+		})
+
+		It("splits the pages with the splitter", func() {
+			docs, err := flowllm.LoadDocs(100, loaders.URL(server.URL+"/a", loaders.CrawlerOptions{
+				HTML: loaders.HTMLOptions{Splitter: flowllm.MarkdownSplitter(flowllm.SplitterOptions{ChunkSize: 10})},
+			}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(docs)).To(BeNumerically(">", 1))
+			for _, doc := range docs {
+				Expect(doc.Metadata).To(HaveKeyWithValue("source", server.URL+"/a"))
+			}
+		})
+	})
+
+	Describe("Sitemap", func() {
+		It("loads the pages listed in the sitemaps, skipping the ones that fail", func() {
+			docs, err := flowllm.LoadDocs(100, loaders.Sitemap(server.URL+"/sitemap.xml", loaders.CrawlerOptions{}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(titles(docs)).To(ConsistOf("Page A", "Page C"))
+		})
+
+		It("returns an error if the sitemap can't be loaded", func() {
+			_, err := flowllm.LoadDocs(100, loaders.Sitemap(server.URL+"/missing.xml", loaders.CrawlerOptions{}))
+			Expect(err).To(MatchError(ContainSubstring("404")))
+		})
+	})
+})
@@ -0,0 +1,84 @@
+package loaders
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"golang.org/x/exp/slices"
+)
+
+// CSVOptions for the CSV loader
+type CSVOptions struct {
+	// ContentColumns are the columns included in the content of the documents, as "column: value"
+	// lines. Defaults to all columns
+	ContentColumns []string
+	// MetadataColumns are the columns copied to the metadata of the documents. Optional
+	MetadataColumns []string
+	// Separator is the field delimiter. Defaults to ','
+	Separator rune
+	// Splitter is used to split the content of each row. Optional
+	Splitter flowllm.Splitter
+}
+
+// CSV creates a DocumentLoader that loads each row of a CSV file as a Document. The first row must
+// be the header, with the names of the columns. The documents have the path of the file as the
+// "source" metadata, and the index of the row (starting at 0, after the header) as "row".
+func CSV(path string, opts CSVOptions) flowllm.DocumentLoaderFunc {
+	if opts.Separator == 0 {
+		opts.Separator = ','
+	}
+	return streamDocuments(func() (io.Closer, recordReader, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		reader := csv.NewReader(f)
+		reader.Comma = opts.Separator
+		header, err := reader.Read()
+		if err != nil {
+			_ = f.Close()
+			if err == io.EOF {
+				return nil, nil, fmt.Errorf("%s: missing CSV header", path)
+			}
+			return nil, nil, err
+		}
+		// Excel and other tools write a UTF-8 byte order mark at the start of the file
+		header[0] = strings.TrimPrefix(header[0], "\uFEFF")
+		for _, column := range append(opts.ContentColumns, opts.MetadataColumns...) {
+			if !slices.Contains(header, column) {
+				_ = f.Close()
+				return nil, nil, fmt.Errorf("%s: column %q not found", path, column)
+			}
+		}
+		contentColumns := opts.ContentColumns
+		if len(contentColumns) == 0 {
+			contentColumns = header
+		}
+
+		row := 0
+		return f, func() (flowllm.Document, error) {
+			record, err := reader.Read()
+			if err != nil {
+				return flowllm.Document{}, err
+			}
+			values := make(map[string]string, len(header))
+			for i, column := range header {
+				values[column] = record[i]
+			}
+			var content []string
+			for _, column := range contentColumns {
+				content = append(content, column+": "+values[column])
+			}
+			metadata := map[string]any{"source": path, "row": row}
+			for _, column := range opts.MetadataColumns {
+				metadata[column] = values[column]
+			}
+			row++
+			return flowllm.Document{PageContent: strings.Join(content, "\n"), Metadata: metadata}, nil
+		}, nil
+	}, opts.Splitter)
+}
@@ -0,0 +1,85 @@
+package loaders_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CSV", func() {
+	var (
+		ctx  context.Context
+		path string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		path = filepath.Join(GinkgoT().TempDir(), "fruits.csv")
+		Expect(os.WriteFile(path, []byte("name,color,description\napple,red,A crunchy fruit\nbanana,yellow,A soft fruit\n"), 0600)).To(Succeed())
+	})
+
+	It("loads each row as a document, with all columns as content", func() {
+		loader := loaders.CSV(path, loaders.CSVOptions{})
+		doc, err := loader.LoadNext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(doc.PageContent).To(Equal("name: apple\ncolor: red\ndescription: A crunchy fruit"))
+		Expect(doc.Metadata).To(Equal(map[string]any{"source": path, "row": 0}))
+
+		doc, err = loader.LoadNext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(doc.PageContent).To(HavePrefix("name: banana"))
+		Expect(doc.Metadata).To(HaveKeyWithValue("row", 1))
+
+		_, err = loader.LoadNext(ctx)
+		Expect(err).To(MatchError(io.EOF))
+	})
+
+	It("uses the configured content and metadata columns", func() {
+		docs, err := flowllm.LoadDocs(10, loaders.CSV(path, loaders.CSVOptions{
+			ContentColumns:  []string{"description"},
+			MetadataColumns: []string{"name", "color"},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(2))
+		Expect(docs[1].PageContent).To(Equal("description: A soft fruit"))
+		Expect(docs[1].Metadata).To(Equal(map[string]any{"source": path, "row": 1, "name": "banana", "color": "yellow"}))
+	})
+
+	It("ignores the byte order mark of the header", func() {
+		Expect(os.WriteFile(path, []byte("\uFEFFname,color\napple,red\n"), 0600)).To(Succeed())
+		docs, err := flowllm.LoadDocs(10, loaders.CSV(path, loaders.CSVOptions{MetadataColumns: []string{"name"}}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs[0].PageContent).To(Equal("name: apple\ncolor: red"))
+		Expect(docs[0].Metadata).To(HaveKeyWithValue("name", "apple"))
+	})
+
+	It("supports other separators", func() {
+		Expect(os.WriteFile(path, []byte("name;color\napple;red\n"), 0600)).To(Succeed())
+		docs, err := flowllm.LoadDocs(10, loaders.CSV(path, loaders.CSVOptions{Separator: ';'}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(1))
+		Expect(docs[0].PageContent).To(Equal("name: apple\ncolor: red"))
+	})
+
+	It("splits the rows with the splitter", func() {
+		docs, err := flowllm.LoadDocs(10, loaders.CSV(path, loaders.CSVOptions{
+			ContentColumns: []string{"description"},
+			Splitter:       flowllm.RecursiveTextSplitter(flowllm.SplitterOptions{ChunkSize: 15}),
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(4))
+		Expect(docs[0].Metadata).To(HaveKeyWithValue("row", 0))
+		Expect(docs[3].Metadata).To(HaveKeyWithValue("row", 1))
+	})
+
+	It("returns an error if a column does not exist", func() {
+		_, err := flowllm.LoadDocs(10, loaders.CSV(path, loaders.CSVOptions{ContentColumns: []string{"price"}}))
+		Expect(err).To(MatchError(ContainSubstring(`column "price" not found`)))
+	})
+})
@@ -0,0 +1,295 @@
+package loaders
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/pl"
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+const defaultDirectoryMaxParallel = 4
+
+// FileLoader creates a DocumentLoader for the file in the given path.
+type FileLoader func(path string) flowllm.DocumentLoader
+
+// DefaultFileLoaders returns the loaders of the binary document formats supported by this package,
+// keyed by their extensions: PDF, DOCX, XLSX, PPTX and EPUB. Add entries to the returned map to
+// customize the loaders used by Directory, while keeping the support for these formats.
+func DefaultFileLoaders() map[string]FileLoader {
+	return map[string]FileLoader{
+		".pdf":  func(path string) flowllm.DocumentLoader { return PDF(path, PDFOptions{}) },
+		".docx": func(path string) flowllm.DocumentLoader { return DOCX(path, DOCXOptions{}) },
+		".xlsx": func(path string) flowllm.DocumentLoader { return XLSX(path, XLSXOptions{}) },
+		".pptx": func(path string) flowllm.DocumentLoader { return PPTX(path, PPTXOptions{}) },
+		".epub": func(path string) flowllm.DocumentLoader { return EPUB(path, EPUBOptions{}) },
+	}
+}
+
+// DirectoryOptions for the Directory loader
+type DirectoryOptions struct {
+	// Include are the glob patterns of the files to load, relative to the root directory, using
+	// the syntax of github.com/bmatcuk/doublestar (ex: "**/*.md"). Defaults to all files
+	Include []string
+	// Exclude are the glob patterns of the files and directories to skip, relative to the root directory
+	Exclude []string
+	// GitIgnore skips the files and directories ignored by the .gitignore files found in the tree,
+	// and the .git directories
+	GitIgnore bool
+	// Loaders maps file extensions (including the dot, ex: ".md") to the FileLoader used to load them.
+	// Defaults to DefaultFileLoaders
+	Loaders map[string]FileLoader
+	// DefaultLoader is used for the files with an extension not found in Loaders. A FileLoader that
+	// returns a nil DocumentLoader skips these files. Defaults to TextFile for text files, skipping
+	// the binary ones, like images and archives
+	DefaultLoader FileLoader
+	// OnError is called when a file fails to load. If it returns nil, the file is skipped and the
+	// loading continues, otherwise the returned error stops the loading. Documents already loaded
+	// from the file are kept. Defaults to stopping at the first error
+	OnError func(path string, err error) error
+	// Splitter is used to split the documents loaded from the files. Optional
+	Splitter flowllm.Splitter
+	// MaxParallel is the maximum number of files loaded concurrently. Defaults to 4
+	MaxParallel int
+}
+
+// Directory creates a DocumentLoader that loads all files in a directory tree, using the loader
+// configured for each file extension. Files are loaded concurrently, and their documents are
+// returned as soon as they are loaded, without reading whole files into memory, so the order of
+// the documents is not deterministic. Documents without a "source" metadata get the path of their
+// file as the source.
+//
+// The loading starts on the first call to LoadNext, and is bound to its context. If a file fails
+// to load and DirectoryOptions.OnError does not skip it, the remaining files are skipped and the
+// error is returned after the documents already loaded. Cancel the context to stop loading before
+// all documents are consumed.
+func Directory(root string, opts DirectoryOptions) flowllm.DocumentLoaderFunc {
+	if opts.Loaders == nil {
+		opts.Loaders = DefaultFileLoaders()
+	}
+	if opts.DefaultLoader == nil {
+		opts.DefaultLoader = textFileLoader
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(_ string, err error) error { return err }
+	}
+	if opts.MaxParallel == 0 {
+		opts.MaxParallel = defaultDirectoryMaxParallel
+	}
+
+	return channelLoader(func(ctx context.Context) (chan []flowllm.Document, chan error, context.CancelFunc) {
+		return startDirectory(ctx, root, opts)
+	})
+}
+
+// startDirectory starts walking the directory tree and loading the files. The documents are sent
+// to the returned channel one at a time, and it is closed when all files are loaded. The first
+// error is sent to the error channel after that.
+func startDirectory(ctx context.Context, root string, opts DirectoryOptions) (chan []flowllm.Document, chan error, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	paths := make(chan string)
+	walkErrC := make(chan error, 1)
+	go func() {
+		defer close(paths)
+		walkErrC <- walkDirectory(ctx, root, opts, paths)
+	}()
+
+	docsC := make(chan []flowllm.Document)
+	doneC, errC := pl.Stage(ctx, opts.MaxParallel, paths, func(ctx context.Context, path string) (struct{}, error) {
+		return struct{}{}, loadFile(ctx, path, opts, docsC)
+	})
+	go func() {
+		// The output of the stage is closed when all files are loaded
+		for range doneC {
+		}
+		close(docsC)
+	}()
+
+	finalErrC := make(chan error, 1)
+	go func() {
+		var firstErr error
+		for err := range errC {
+			if firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+		}
+		walkErr := <-walkErrC
+		if firstErr == nil && walkErr != nil && !errors.Is(walkErr, context.Canceled) {
+			firstErr = walkErr
+		}
+		finalErrC <- firstErr
+	}()
+	return docsC, finalErrC, cancel
+}
+
+// walkDirectory sends the paths of the files to load to the paths channel.
+func walkDirectory(ctx context.Context, root string, opts DirectoryOptions, paths chan<- string) error {
+	gitIgnores := map[string]*ignore.GitIgnore{}
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return loadGitIgnore(root, path, opts, gitIgnores)
+		}
+
+		skip := matchAny(opts.Exclude, rel) || (opts.GitIgnore && isGitIgnored(rel, d.IsDir(), gitIgnores))
+		if d.IsDir() {
+			if skip || (opts.GitIgnore && d.Name() == ".git") {
+				return filepath.SkipDir
+			}
+			return loadGitIgnore(root, path, opts, gitIgnores)
+		}
+		if skip || !d.Type().IsRegular() || (len(opts.Include) > 0 && !matchAny(opts.Include, rel)) {
+			return nil
+		}
+		pl.SendOrDone(ctx, paths, path)
+		return nil
+	})
+}
+
+func matchAny(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := doublestar.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// loadGitIgnore compiles the .gitignore file of the directory, if there is one. The matchers are
+// keyed by the path of the directory, relative to the root.
+func loadGitIgnore(root, dir string, opts DirectoryOptions, gitIgnores map[string]*ignore.GitIgnore) error {
+	if !opts.GitIgnore {
+		return nil
+	}
+	gitIgnore, err := ignore.CompileIgnoreFile(filepath.Join(dir, ".gitignore"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading .gitignore in %s: %w", dir, err)
+	}
+	rel, _ := filepath.Rel(root, dir)
+	gitIgnores[filepath.ToSlash(rel)] = gitIgnore
+	return nil
+}
+
+// isGitIgnored checks the path against the .gitignore files of all its parent directories.
+func isGitIgnored(rel string, isDir bool, gitIgnores map[string]*ignore.GitIgnore) bool {
+	if isDir {
+		rel += "/"
+	}
+	dir := rel
+	for dir != "." {
+		dir = filepath.ToSlash(filepath.Dir(strings.TrimSuffix(dir, "/")))
+		gitIgnore := gitIgnores[dir]
+		if gitIgnore == nil {
+			continue
+		}
+		relToDir := rel
+		if dir != "." {
+			relToDir = strings.TrimPrefix(rel, dir+"/")
+		}
+		if gitIgnore.MatchesPath(relToDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadFile loads the documents of the file, using the loader configured for its extension, and
+// sends them to docsC as they are loaded. Errors are passed to the OnError option.
+func loadFile(ctx context.Context, path string, opts DirectoryOptions, docsC chan<- []flowllm.Document) error {
+	newLoader := opts.Loaders[strings.ToLower(filepath.Ext(path))]
+	if newLoader == nil {
+		newLoader = opts.DefaultLoader
+	}
+	loader := newLoader(path)
+	if loader == nil {
+		return nil
+	}
+
+	for {
+		doc, err := loader.LoadNext(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err := opts.OnError(path, err); err != nil {
+				return fmt.Errorf("loading %s: %w", path, err)
+			}
+			return nil
+		}
+		if doc.Metadata == nil {
+			doc.Metadata = map[string]any{}
+		}
+		if _, ok := doc.Metadata["source"]; !ok {
+			doc.Metadata["source"] = path
+		}
+		docs := []flowllm.Document{doc}
+		if opts.Splitter != nil {
+			if docs, err = SplitDocuments(opts.Splitter, docs); err != nil {
+				return fmt.Errorf("splitting %s: %w", path, err)
+			}
+		}
+		select {
+		case docsC <- docs:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// textFileLoader loads the file with TextFile, unless it is a binary file.
+func textFileLoader(path string) flowllm.DocumentLoader {
+	f, err := os.Open(path)
+	if err != nil {
+		// Let TextFile return the error
+		return TextFile(path)
+	}
+	defer f.Close()
+	buf := make([]byte, 8*1024)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return TextFile(path)
+	}
+	if isBinary(buf[:n]) {
+		return nil
+	}
+	return TextFile(path)
+}
+
+// isBinary returns true if the beginning of a file has NUL bytes or is not valid UTF-8.
+func isBinary(data []byte) bool {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+	// The data may end in the middle of a multibyte character
+	for i := 0; i < utf8.UTFMax && len(data) > 0 && !utf8.Valid(data); i++ {
+		data = data[:len(data)-1]
+	}
+	return !utf8.Valid(data)
+}
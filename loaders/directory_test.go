@@ -0,0 +1,214 @@
+package loaders_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Directory", func() {
+	var root string
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+		files := map[string]string{
+			"readme.md":         "Read me",
+			"notes.txt":         "Some notes",
+			"docs/guide.md":     "The guide",
+			"docs/api/ref.txt":  "API reference",
+			"build/output.txt":  "Build output",
+			"logs/app.log":      "Log line",
+			"sub/.gitignore":    "secret.txt\n",
+			"sub/secret.txt":    "Secret",
+			"sub/public.txt":    "Public",
+			".gitignore":        "build/\n*.log\n",
+			".git/HEAD":         "ref: refs/heads/main",
+			"docs/api/big.json": `{"big": true}`,
+		}
+		for name, content := range files {
+			path := filepath.Join(root, filepath.FromSlash(name))
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		}
+	})
+
+	contents := func(docs []flowllm.Document) []string {
+		var result []string
+		for _, doc := range docs {
+			result = append(result, doc.PageContent)
+		}
+		return result
+	}
+
+	It("loads all files in the tree, with their paths as source", func() {
+		png := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0, 0, 0x0d}
+		Expect(os.WriteFile(filepath.Join(root, "docs", "image.png"), png, 0600)).To(Succeed())
+
+		docs, err := flowllm.LoadDocs(100, loaders.Directory(root, loaders.DirectoryOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(12))
+		for _, doc := range docs {
+			Expect(doc.Metadata["source"]).To(HavePrefix(root))
+		}
+	})
+
+	It("filters the files with include and exclude patterns", func() {
+		docs, err := flowllm.LoadDocs(100, loaders.Directory(root, loaders.DirectoryOptions{
+			Include: []string{"**/*.md", "**/*.txt"},
+			Exclude: []string{"build", "sub/secret.txt", "docs/api/**"},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contents(docs)).To(ConsistOf("Read me", "Some notes", "The guide", "Public"))
+	})
+
+	It("skips the files ignored by the .gitignore files", func() {
+		docs, err := flowllm.LoadDocs(100, loaders.Directory(root, loaders.DirectoryOptions{
+			Include:   []string{"**/*.md", "**/*.txt", "**/*.log", "**/HEAD"},
+			GitIgnore: true,
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contents(docs)).To(ConsistOf("Read me", "Some notes", "The guide", "API reference", "Public"))
+	})
+
+	It("dispatches the files to the loader configured for their extension", func() {
+		markdown := func(path string) flowllm.DocumentLoader {
+			loaded := false
+			return flowllm.DocumentLoaderFunc(func(context.Context) (flowllm.Document, error) {
+				if loaded {
+					return flowllm.Document{}, io.EOF
+				}
+				loaded = true
+				return flowllm.Document{PageContent: "markdown: " + filepath.Base(path)}, nil
+			})
+		}
+		skip := func(string) flowllm.DocumentLoader { return nil }
+
+		docs, err := flowllm.LoadDocs(100, loaders.Directory(root, loaders.DirectoryOptions{
+			Loaders: map[string]loaders.FileLoader{
+				".md":  markdown,
+				".txt": func(path string) flowllm.DocumentLoader { return loaders.TextFile(path) },
+			},
+			DefaultLoader: skip,
+			GitIgnore:     true,
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contents(docs)).To(ConsistOf("markdown: readme.md", "markdown: guide.md", "Some notes", "API reference", "Public"))
+		for _, doc := range docs {
+			Expect(doc.Metadata).To(HaveKey("source"))
+		}
+	})
+
+	It("loads the document formats with the DefaultFileLoaders", func() {
+		docx := writeZip("report.docx", map[string]string{
+			"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+				<w:body><w:p><w:r><w:t>Quarterly report</w:t></w:r></w:p></w:body></w:document>`,
+		})
+		Expect(os.Rename(docx, filepath.Join(root, "docs", "report.docx"))).To(Succeed())
+
+		docs, err := flowllm.LoadDocs(100, loaders.Directory(root, loaders.DirectoryOptions{
+			Include: []string{"docs/*"},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contents(docs)).To(ConsistOf("The guide", "Quarterly report"))
+	})
+
+	It("splits the documents with the splitter", func() {
+		docs, err := flowllm.LoadDocs(100, loaders.Directory(root, loaders.DirectoryOptions{
+			Include:  []string{"docs/api/ref.txt"},
+			Splitter: flowllm.RecursiveTextSplitter(flowllm.SplitterOptions{ChunkSize: 10}),
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contents(docs)).To(Equal([]string{"API", "reference"}))
+	})
+
+	It("returns the errors from the file loaders", func() {
+		failing := func(string) flowllm.DocumentLoader {
+			return flowllm.DocumentLoaderFunc(func(context.Context) (flowllm.Document, error) {
+				return flowllm.Document{}, errors.New("boom")
+			})
+		}
+		_, err := flowllm.LoadDocs(100, loaders.Directory(root, loaders.DirectoryOptions{
+			Loaders: map[string]loaders.FileLoader{".md": failing},
+		}))
+		Expect(err).To(MatchError(ContainSubstring("boom")))
+	})
+
+	It("skips the files rejected by OnError", func() {
+		failing := func(string) flowllm.DocumentLoader {
+			return flowllm.DocumentLoaderFunc(func(context.Context) (flowllm.Document, error) {
+				return flowllm.Document{}, loaders.ErrPDFNoText
+			})
+		}
+		var mu sync.Mutex
+		var skipped []string
+		docs, err := flowllm.LoadDocs(100, loaders.Directory(root, loaders.DirectoryOptions{
+			Loaders: map[string]loaders.FileLoader{".md": failing},
+			OnError: func(path string, err error) error {
+				mu.Lock()
+				defer mu.Unlock()
+				Expect(err).To(MatchError(loaders.ErrPDFNoText))
+				skipped = append(skipped, filepath.Base(path))
+				return nil
+			},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(10))
+		Expect(skipped).To(ConsistOf("readme.md", "guide.md"))
+	})
+
+	It("returns the documents of a file as they are loaded", func() {
+		release := make(chan struct{})
+		streaming := func(string) flowllm.DocumentLoader {
+			loaded := false
+			return flowllm.DocumentLoaderFunc(func(ctx context.Context) (flowllm.Document, error) {
+				if !loaded {
+					loaded = true
+					return flowllm.Document{PageContent: "first"}, nil
+				}
+				select {
+				case <-release:
+				case <-ctx.Done():
+				}
+				return flowllm.Document{}, io.EOF
+			})
+		}
+		loader := loaders.Directory(root, loaders.DirectoryOptions{
+			Include: []string{"readme.md"},
+			Loaders: map[string]loaders.FileLoader{".md": streaming},
+		})
+		DeferCleanup(func() { close(release) })
+
+		first := make(chan flowllm.Document, 1)
+		go func() {
+			doc, _ := loader.LoadNext(context.Background())
+			first <- doc
+		}()
+		Eventually(first).Should(Receive(HaveField("PageContent", "first")))
+	})
+
+	It("returns an error if the root does not exist", func() {
+		_, err := flowllm.LoadDocs(100, loaders.Directory(filepath.Join(root, "missing"), loaders.DirectoryOptions{}))
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+
+	It("stops loading when the context is canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		loader := loaders.Directory(root, loaders.DirectoryOptions{MaxParallel: 1})
+		_, err := loader.LoadNext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		cancel()
+		Eventually(func() error {
+			_, err := loader.LoadNext(ctx)
+			return err
+		}).Should(MatchError(context.Canceled))
+	})
+})
@@ -0,0 +1,276 @@
+package loaders
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/deluan/flowllm"
+)
+
+// DOCXOptions for the DOCX loader
+type DOCXOptions struct {
+	// Splitter is used to split the content of the document. Optional
+	Splitter flowllm.Splitter
+}
+
+// DOCX creates a DocumentLoader that loads a Word document as a Document. The content is converted
+// to Markdown-like text: headings are prefixed with "#", list items with "-" and tables are
+// rendered as Markdown tables. The title and author of the document are recorded in the "title"
+// and "author" metadata, when present, and the path of the file in "source".
+func DOCX(path string, opts DOCXOptions) flowllm.DocumentLoaderFunc {
+	return streamDocuments(func() (io.Closer, recordReader, error) {
+		z, err := openZip(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		done := false
+		return z, func() (flowllm.Document, error) {
+			if done {
+				return flowllm.Document{}, io.EOF
+			}
+			done = true
+			content, err := docxContent(z)
+			if err != nil {
+				return flowllm.Document{}, err
+			}
+			return flowllm.Document{PageContent: content, Metadata: z.coreProperties()}, nil
+		}, nil
+	}, opts.Splitter)
+}
+
+type docxParagraph struct {
+	style     string
+	heading   int
+	list      bool
+	listLevel int
+	text      strings.Builder
+}
+
+type docxTable struct {
+	rows [][]string
+	cell []string
+}
+
+// docxBlock is a paragraph or table of the document. Consecutive list items are separated by a
+// single line break, other blocks by a blank line.
+type docxBlock struct {
+	text string
+	list bool
+}
+
+func docxContent(z *zipArchive) (string, error) {
+	headings, err := docxHeadingStyles(z)
+	if err != nil {
+		return "", err
+	}
+	r, err := z.open("word/document.xml")
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	var blocks []docxBlock
+	// Paragraphs can be nested, in text boxes
+	var paras []*docxParagraph
+	var para *docxParagraph
+	var tables []*docxTable
+	inRun, inText := false, false
+	d := xml.NewDecoder(r)
+	for {
+		token, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("%s: parsing word/document.xml: %w", z.path, err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para = &docxParagraph{}
+				paras = append(paras, para)
+			case "r":
+				inRun = true
+			case "pStyle":
+				if para != nil {
+					para.style = attrValue(t, "val")
+				}
+			case "outlineLvl":
+				if para != nil {
+					if level, err := strconv.Atoi(attrValue(t, "val")); err == nil && level < 9 {
+						para.heading = level + 1
+					}
+				}
+			case "numPr":
+				if para != nil {
+					para.list = true
+				}
+			case "ilvl":
+				if para != nil {
+					para.listLevel, _ = strconv.Atoi(attrValue(t, "val"))
+				}
+			case "t":
+				inText = para != nil && inRun
+			case "tab":
+				if para != nil && inRun {
+					para.text.WriteString("\t")
+				}
+			case "br", "cr":
+				if para != nil && inRun {
+					para.text.WriteString("\n")
+				}
+			case "tbl":
+				tables = append(tables, &docxTable{})
+			case "tr":
+				if len(tables) > 0 {
+					table := tables[len(tables)-1]
+					table.rows = append(table.rows, nil)
+				}
+			case "tc":
+				if len(tables) > 0 {
+					tables[len(tables)-1].cell = nil
+				}
+			}
+		case xml.CharData:
+			if inText {
+				para.text.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "r":
+				inRun = false
+			case "p":
+				if para == nil {
+					continue
+				}
+				text := strings.TrimSpace(para.text.String())
+				if text != "" {
+					if len(tables) > 0 {
+						table := tables[len(tables)-1]
+						table.cell = append(table.cell, text)
+					} else {
+						blocks = append(blocks, para.block(headings))
+					}
+				}
+				paras = paras[:len(paras)-1]
+				para = nil
+				if len(paras) > 0 {
+					para = paras[len(paras)-1]
+				}
+			case "tc":
+				if len(tables) > 0 {
+					table := tables[len(tables)-1]
+					if len(table.rows) > 0 {
+						table.rows[len(table.rows)-1] = append(table.rows[len(table.rows)-1], strings.Join(table.cell, " "))
+					}
+				}
+			case "tbl":
+				if len(tables) == 0 {
+					continue
+				}
+				table := tables[len(tables)-1]
+				tables = tables[:len(tables)-1]
+				if len(tables) > 0 {
+					// Nested tables are flattened into the cell of the outer table
+					outer := tables[len(tables)-1]
+					for _, row := range table.rows {
+						outer.cell = append(outer.cell, strings.Join(row, " "))
+					}
+				} else if text := markdownTable(table.rows); text != "" {
+					blocks = append(blocks, docxBlock{text: text})
+				}
+			}
+		}
+	}
+
+	var sb strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			if block.list && blocks[i-1].list {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString("\n\n")
+			}
+		}
+		sb.WriteString(block.text)
+	}
+	return sb.String(), nil
+}
+
+func (p *docxParagraph) block(headings map[string]int) docxBlock {
+	text := strings.TrimSpace(p.text.String())
+	level := p.heading
+	if level == 0 {
+		level = headings[p.style]
+	}
+	if level == 0 {
+		level = docxHeadingLevel(p.style)
+	}
+	switch {
+	case level > 0:
+		if level > 6 {
+			level = 6
+		}
+		return docxBlock{text: strings.Repeat("#", level) + " " + collapseSpaces(text)}
+	case p.list:
+		return docxBlock{text: strings.Repeat("  ", p.listLevel) + "- " + text, list: true}
+	default:
+		return docxBlock{text: text}
+	}
+}
+
+// docxHeadingStyles returns the heading level of the paragraph styles of the document, keyed by
+// their ids. Styles are identified as headings by their names or outline levels, as their ids
+// may be localized.
+func docxHeadingStyles(z *zipArchive) (map[string]int, error) {
+	headings := map[string]int{}
+	if !z.has("word/styles.xml") {
+		return headings, nil
+	}
+	var styles struct {
+		Styles []struct {
+			ID   string `xml:"styleId,attr"`
+			Name struct {
+				Val string `xml:"val,attr"`
+			} `xml:"name"`
+			OutlineLevel *struct {
+				Val int `xml:"val,attr"`
+			} `xml:"pPr>outlineLvl"`
+		} `xml:"style"`
+	}
+	if err := z.decode("word/styles.xml", &styles); err != nil {
+		return nil, err
+	}
+	for _, style := range styles.Styles {
+		if level := docxHeadingLevel(style.Name.Val); level > 0 {
+			headings[style.ID] = level
+		} else if style.OutlineLevel != nil && style.OutlineLevel.Val < 9 {
+			headings[style.ID] = style.OutlineLevel.Val + 1
+		}
+	}
+	return headings, nil
+}
+
+// docxHeadingLevel returns the heading level of the built-in styles "Title" and "Heading N",
+// or 0 for other styles.
+func docxHeadingLevel(style string) int {
+	style = strings.ToLower(strings.ReplaceAll(style, " ", ""))
+	if style == "title" {
+		return 1
+	}
+	if !strings.HasPrefix(style, "heading") {
+		return 0
+	}
+	level, err := strconv.Atoi(strings.TrimPrefix(style, "heading"))
+	if err != nil || level < 1 {
+		return 0
+	}
+	return level
+}
@@ -0,0 +1,71 @@
+package loaders_test
+
+import (
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const docxCoreProperties = `<?xml version="1.0" encoding="UTF-8"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">
+	<dc:title>Fruit Guide</dc:title>
+	<dc:creator>Jane Doe</dc:creator>
+</cp:coreProperties>`
+
+var _ = Describe("DOCX", func() {
+	It("converts paragraphs, headings, lists and tables to Markdown", func() {
+		path := writeZip("fruits.docx", map[string]string{
+			"docProps/core.xml": docxCoreProperties,
+			"word/styles.xml": `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+				<w:style w:styleId="Titre1"><w:name w:val="heading 1"/></w:style>
+				<w:style w:styleId="Sub"><w:name w:val="Subsection"/><w:pPr><w:outlineLvl w:val="1"/></w:pPr></w:style>
+			</w:styles>`,
+			"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+				<w:p><w:pPr><w:pStyle w:val="Titre1"/><w:tabs><w:tab w:pos="100"/></w:tabs></w:pPr><w:r><w:t>Fruits</w:t></w:r></w:p>
+				<w:p><w:r><w:t xml:space="preserve">Fruits are </w:t></w:r><w:r><w:t>delicious.</w:t></w:r></w:p>
+				<w:p><w:pPr><w:pStyle w:val="Sub"/></w:pPr><w:r><w:t>Favorites</w:t></w:r></w:p>
+				<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/></w:numPr></w:pPr><w:r><w:t>Apple</w:t></w:r></w:p>
+				<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/></w:numPr></w:pPr><w:r><w:t>Gala</w:t></w:r></w:p>
+				<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/></w:numPr></w:pPr><w:r><w:t>Banana</w:t></w:r></w:p>
+				<w:tbl>
+					<w:tr><w:tc><w:p><w:r><w:t>Fruit</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Color</w:t></w:r></w:p></w:tc></w:tr>
+					<w:tr><w:tc><w:p><w:r><w:t>Apple</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Red</w:t></w:r></w:p><w:p><w:r><w:t>or green</w:t></w:r></w:p></w:tc></w:tr>
+				</w:tbl>
+				<w:p><w:r><w:t>The end.</w:t></w:r><w:r><w:br/><w:t>Really.</w:t></w:r></w:p>
+			</w:body></w:document>`,
+		})
+
+		docs, err := flowllm.LoadDocs(10, loaders.DOCX(path, loaders.DOCXOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(1))
+		Expect(docs[0].PageContent).To(Equal(strings.Join([]string{
+			"# Fruits",
+			"",
+			"Fruits are delicious.",
+			"",
+			"## Favorites",
+			"",
+			"- Apple",
+			"  - Gala",
+			"- Banana",
+			"",
+			"| Fruit | Color |",
+			"| --- | --- |",
+			"| Apple | Red or green |",
+			"",
+			"The end.",
+			"Really.",
+		}, "\n")))
+		Expect(docs[0].Metadata).To(Equal(map[string]any{"source": path, "title": "Fruit Guide", "author": "Jane Doe"}))
+	})
+
+	It("returns an error if the file is not a Word document", func() {
+		path := writeZip("fruits.docx", map[string]string{"readme.txt": "Not a document"})
+
+		_, err := flowllm.LoadDocs(10, loaders.DOCX(path, loaders.DOCXOptions{}))
+		Expect(err).To(MatchError(ContainSubstring("missing word/document.xml")))
+	})
+})
@@ -0,0 +1,170 @@
+package loaders
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/deluan/flowllm"
+)
+
+// EPUBOptions for the EPUB loader
+type EPUBOptions struct {
+	// Splitter is used to split the content of the chapters. Optional
+	Splitter flowllm.Splitter
+}
+
+// EPUB creates a DocumentLoader that loads each chapter of an EPUB book as a Document, in reading
+// order. The chapters are converted to Markdown-like text like the HTML loader does. The chapter
+// number (starting at 1) is recorded in the "chapter" metadata and its title in "chapter_title".
+// The title, author and language of the book are recorded in the "title", "author" and "language"
+// metadata, when present, and the path of the file in "source". Chapters without text, like covers,
+// are skipped.
+func EPUB(path string, opts EPUBOptions) flowllm.DocumentLoaderFunc {
+	return streamDocuments(func() (io.Closer, recordReader, error) {
+		z, err := openZip(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		book, err := openEPUB(z)
+		if err != nil {
+			_ = z.Close()
+			return nil, nil, err
+		}
+		next, chapter := 0, 0
+		return z, func() (flowllm.Document, error) {
+			for next < len(book.chapters) {
+				next++
+				doc, err := book.chapter(book.chapters[next-1])
+				if err != nil {
+					return flowllm.Document{}, err
+				}
+				if doc.PageContent == "" {
+					continue
+				}
+				chapter++
+				doc.Metadata["chapter"] = chapter
+				return doc, nil
+			}
+			return flowllm.Document{}, io.EOF
+		}, nil
+	}, opts.Splitter)
+}
+
+type epubBook struct {
+	z        *zipArchive
+	metadata map[string]any
+	chapters []string
+}
+
+// openEPUB reads the package document of the book, with its metadata and the parts of the
+// chapters in the order of the spine.
+func openEPUB(z *zipArchive) (*epubBook, error) {
+	var container struct {
+		RootFiles []struct {
+			FullPath  string `xml:"full-path,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := z.decode("META-INF/container.xml", &container); err != nil {
+		return nil, err
+	}
+	var opf string
+	for _, rootFile := range container.RootFiles {
+		if rootFile.MediaType == "" || rootFile.MediaType == "application/oebps-package+xml" {
+			opf = rootFile.FullPath
+			break
+		}
+	}
+	if opf == "" {
+		return nil, fmt.Errorf("%s: missing package document", z.path)
+	}
+
+	var pkg struct {
+		Titles    []string `xml:"metadata>title"`
+		Creators  []string `xml:"metadata>creator"`
+		Languages []string `xml:"metadata>language"`
+		Items     []struct {
+			ID        string `xml:"id,attr"`
+			Href      string `xml:"href,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"manifest>item"`
+		Spine []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+	}
+	if err := z.decode(opf, &pkg); err != nil {
+		return nil, err
+	}
+
+	book := &epubBook{z: z, metadata: map[string]any{"source": z.path}}
+	if len(pkg.Titles) > 0 && strings.TrimSpace(pkg.Titles[0]) != "" {
+		book.metadata["title"] = strings.TrimSpace(pkg.Titles[0])
+	}
+	var authors []string
+	for _, creator := range pkg.Creators {
+		if creator = strings.TrimSpace(creator); creator != "" {
+			authors = append(authors, creator)
+		}
+	}
+	if len(authors) > 0 {
+		book.metadata["author"] = strings.Join(authors, ", ")
+	}
+	if len(pkg.Languages) > 0 && strings.TrimSpace(pkg.Languages[0]) != "" {
+		book.metadata["language"] = strings.TrimSpace(pkg.Languages[0])
+	}
+
+	items := map[string]string{}
+	for _, item := range pkg.Items {
+		if item.MediaType != "application/xhtml+xml" && item.MediaType != "text/html" {
+			continue
+		}
+		href, err := url.PathUnescape(item.Href)
+		if err != nil {
+			href = item.Href
+		}
+		items[item.ID] = path.Join(path.Dir(opf), href)
+	}
+	for _, ref := range pkg.Spine {
+		if part, ok := items[ref.IDRef]; ok {
+			book.chapters = append(book.chapters, part)
+		}
+	}
+	return book, nil
+}
+
+// chapter converts the chapter to a Document. The title of the chapter is taken from its first
+// heading, or from the title of the page, which is often the title of the book.
+func (b *epubBook) chapter(part string) (flowllm.Document, error) {
+	r, err := b.z.open(part)
+	if err != nil {
+		return flowllm.Document{}, err
+	}
+	defer r.Close()
+	// Chapters are not web pages, so their headers hold the chapter title, not a site banner
+	page, err := parseHTML(r, HTMLOptions{KeepHeaders: true})
+	if err != nil {
+		return flowllm.Document{}, fmt.Errorf("%s: parsing %s: %w", b.z.path, part, err)
+	}
+
+	metadata := map[string]any{}
+	for k, v := range b.metadata {
+		metadata[k] = v
+	}
+	var title string
+	for _, line := range strings.Split(page.PageContent, "\n") {
+		if strings.HasPrefix(line, "#") {
+			title = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			break
+		}
+	}
+	if title == "" {
+		title, _ = page.Metadata["title"].(string)
+	}
+	if title != "" {
+		metadata["chapter_title"] = title
+	}
+	return flowllm.Document{PageContent: page.PageContent, Metadata: metadata}, nil
+}
@@ -0,0 +1,50 @@
+package loaders_test
+
+import (
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EPUB", func() {
+	It("loads each chapter with text as a document, in reading order", func() {
+		path := writeZip("fruits.epub", map[string]string{
+			"mimetype": "application/epub+zip",
+			"META-INF/container.xml": `<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+				<rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+			</container>`,
+			"OEBPS/content.opf": `<package xmlns="http://www.idpf.org/2007/opf" xmlns:dc="http://purl.org/dc/elements/1.1/" version="3.0">
+				<metadata><dc:title>Fruit Guide</dc:title><dc:creator>Jane Doe</dc:creator><dc:creator>John Doe</dc:creator><dc:language>en</dc:language></metadata>
+				<manifest>
+					<item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+					<item id="c1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>
+					<item id="c2" href="text/chapter2.xhtml" media-type="application/xhtml+xml"/>
+					<item id="img" href="cover.jpg" media-type="image/jpeg"/>
+				</manifest>
+				<spine><itemref idref="cover"/><itemref idref="c2"/><itemref idref="img"/><itemref idref="c1"/></spine>
+			</package>`,
+			"OEBPS/cover.xhtml":          `<html><body><img src="cover.jpg"/></body></html>`,
+			"OEBPS/text/chapter 1.xhtml": `<html><head><title>Fruit Guide</title></head><body><section><header><h1>Apples</h1></header><p>Apples are red.</p></section></body></html>`,
+			"OEBPS/text/chapter2.xhtml":  `<html><head><title>Bananas</title></head><body><p>Bananas are yellow.</p></body></html>`,
+		})
+
+		docs, err := flowllm.LoadDocs(10, loaders.EPUB(path, loaders.EPUBOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(2))
+		Expect(docs[0].PageContent).To(Equal("Bananas are yellow."))
+		Expect(docs[0].Metadata).To(Equal(map[string]any{
+			"source": path, "chapter": 1, "chapter_title": "Bananas", "title": "Fruit Guide", "author": "Jane Doe, John Doe", "language": "en",
+		}))
+		Expect(docs[1].PageContent).To(Equal("# Apples\n\nApples are red."))
+		Expect(docs[1].Metadata).To(HaveKeyWithValue("chapter", 2))
+		Expect(docs[1].Metadata).To(HaveKeyWithValue("chapter_title", "Apples"))
+	})
+
+	It("returns an error if the file is not an EPUB", func() {
+		path := writeZip("fruits.epub", map[string]string{"readme.txt": "Not a book"})
+
+		_, err := flowllm.LoadDocs(10, loaders.EPUB(path, loaders.EPUBOptions{}))
+		Expect(err).To(MatchError(ContainSubstring("missing META-INF/container.xml")))
+	})
+})
@@ -0,0 +1,208 @@
+package loaders
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/deluan/flowllm"
+)
+
+// GoFileOptions for the GoFile loader
+type GoFileOptions struct {
+	// Splitter is used to split the declarations that are too large. Optional, flowllm.GoSplitter
+	// is a good fit
+	Splitter flowllm.Splitter
+}
+
+// GoFile creates a DocumentLoader that loads a Go source file, returning one Document for each
+// top-level declaration, as split by SplitGoDocuments. The path of the file is recorded as the
+// "source" metadata.
+func GoFile(path string, opts GoFileOptions) flowllm.DocumentLoaderFunc {
+	return streamDocuments(func() (io.Closer, recordReader, error) {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		docs, err := SplitGoDocuments(opts.Splitter, []flowllm.Document{{
+			PageContent: string(src),
+			Metadata:    map[string]any{"source": path},
+		}})
+		if err != nil {
+			return nil, nil, err
+		}
+		return io.NopCloser(nil), func() (flowllm.Document, error) {
+			if len(docs) == 0 {
+				return flowllm.Document{}, io.EOF
+			}
+			doc := docs[0]
+			docs = docs[1:]
+			return doc, nil
+		}, nil
+	}, nil)
+}
+
+// SplitGoDocuments parses the documents as Go source files and splits them by top-level
+// declaration, using go/parser. Each chunk has the declaration with its doc comment, and any
+// comments between it and the previous declaration. The package clause and the imports are
+// returned as the first chunk. If a splitter is provided, it is used to split each chunk further.
+//
+// Each chunk gets a copy of the metadata of its document, and the following metadata about its
+// declaration: "package", "symbol" (ex: "Server", "NewServer", "Server.Start") and "kind"
+// ("package", "func", "method", "type", "var" or "const"). The position of the declaration in the
+// file is recorded like in SplitDocuments. If the document has an ID, it is recorded in the
+// chunk's metadata under the ParentIDKey. An error is returned if a document is not valid Go code.
+func SplitGoDocuments(splitter flowllm.Splitter, documents []flowllm.Document) ([]flowllm.Document, error) {
+	var result []flowllm.Document
+	for _, document := range documents {
+		filename, _ := document.Metadata["source"].(string)
+		chunks, err := goDeclarations(filename, document.PageContent)
+		if err != nil {
+			return nil, err
+		}
+		var docs []flowllm.Document
+		for _, chunk := range chunks {
+			metadata := make(map[string]any, len(document.Metadata)+6)
+			for k, v := range document.Metadata {
+				metadata[k] = v
+			}
+			if document.ID != "" {
+				metadata[ParentIDKey] = document.ID
+			}
+			for k, v := range chunk.metadata {
+				metadata[k] = v
+			}
+			setChunkPosition(metadata, chunk.Chunk)
+			docs = append(docs, flowllm.Document{PageContent: chunk.Text, Metadata: metadata})
+		}
+		if splitter != nil {
+			docs, err = SplitDocuments(splitter, docs)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, docs...)
+	}
+	return result, nil
+}
+
+type goChunk struct {
+	flowllm.Chunk
+	metadata map[string]any
+}
+
+// goDeclarations splits the source code by top-level declaration. The chunks cover all lines of the
+// file: each declaration starts right after the end of the previous one, and the last one goes
+// until the end of the file.
+func goDeclarations(filename, src string) ([]goChunk, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.SplitAfter(src, "\n")
+	pkg := file.Name.Name
+
+	var chunks []goChunk
+	start, offset := 1, 0
+	add := func(end int, symbol, kind string) {
+		if end > len(lines) {
+			end = len(lines)
+		}
+		// Skip the blank lines before the declaration
+		for start < end && strings.TrimSpace(lines[start-1]) == "" {
+			offset += len(lines[start-1])
+			start++
+		}
+		text := strings.Join(lines[start-1:end], "")
+		content := strings.TrimRight(text, " \t\r\n")
+		if content != "" {
+			chunks = append(chunks, goChunk{
+				Chunk: flowllm.Chunk{
+					Text:       content,
+					StartIndex: offset,
+					EndIndex:   offset + len(content),
+					StartLine:  start,
+					EndLine:    start + strings.Count(content, "\n"),
+				},
+				metadata: map[string]any{"package": pkg, "symbol": symbol, "kind": kind},
+			})
+		}
+		start, offset = end+1, offset+len(text)
+	}
+
+	// The package clause, with the imports
+	header := fset.Position(file.Name.End()).Line
+	decls := file.Decls
+	for len(decls) > 0 {
+		gen, ok := decls[0].(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			break
+		}
+		header = fset.Position(gen.End()).Line
+		decls = decls[1:]
+	}
+	if len(decls) == 0 {
+		header = len(lines)
+	}
+	add(header, pkg, "package")
+
+	for i, decl := range decls {
+		end := fset.Position(decl.End()).Line
+		if i == len(decls)-1 {
+			// Comments at the end of the file go with the last declaration
+			end = len(lines)
+		}
+		symbol, kind := goSymbol(decl)
+		add(end, symbol, kind)
+	}
+	return chunks, nil
+}
+
+// goSymbol returns the name and kind of the declaration. Methods are named after their receiver
+// type, and declarations of groups of names have all of them, separated by commas.
+func goSymbol(decl ast.Decl) (string, string) {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil || len(d.Recv.List) == 0 {
+			return d.Name.Name, "func"
+		}
+		return goReceiverType(d.Recv.List[0].Type) + "." + d.Name.Name, "method"
+	case *ast.GenDecl:
+		var names []string
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					names = append(names, name.Name)
+				}
+			case *ast.ImportSpec:
+				names = append(names, strings.Trim(s.Path.Value, `"`))
+			}
+		}
+		return strings.Join(names, ", "), d.Tok.String()
+	default:
+		return "", fmt.Sprintf("%T", decl)
+	}
+}
+
+func goReceiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return goReceiverType(t.X)
+	case *ast.IndexExpr:
+		return goReceiverType(t.X)
+	case *ast.IndexListExpr:
+		return goReceiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,95 @@
+package loaders_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GoFile", func() {
+	const src = `// Package server does things.
+package server
+
+import "net/http"
+
+// Server serves.
+type Server struct {
+	mux *http.ServeMux
+}
+
+// NewServer creates a Server.
+func NewServer() *Server {
+	return &Server{mux: http.NewServeMux()}
+}
+
+// Start starts the server.
+func (s *Server) Start(addr string) error {
+	return http.ListenAndServe(addr, s.mux)
+}
+
+const (
+	A = 1
+	B = 2
+)
+
+// The end.
+`
+
+	write := func(content string) string {
+		path := filepath.Join(GinkgoT().TempDir(), "server.go")
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	It("returns a document for each top-level declaration, with its doc comment", func() {
+		path := write(src)
+
+		docs, err := flowllm.LoadDocs(10, loaders.GoFile(path, loaders.GoFileOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(5))
+
+		Expect(docs[0].PageContent).To(Equal("// Package server does things.\npackage server\n\nimport \"net/http\""))
+		Expect(docs[0].Metadata).To(Equal(map[string]any{
+			"source": path, "package": "server", "symbol": "server", "kind": "package",
+			"start_index": 0, "end_index": 64, "start_line": 1, "end_line": 4,
+		}))
+		Expect(docs[1].PageContent).To(Equal("// Server serves.\ntype Server struct {\n\tmux *http.ServeMux\n}"))
+		Expect(docs[1].Metadata).To(Equal(map[string]any{
+			"source": path, "package": "server", "symbol": "Server", "kind": "type",
+			"start_index": 66, "end_index": 126, "start_line": 6, "end_line": 9,
+		}))
+		Expect(docs[2].PageContent).To(HavePrefix("// NewServer creates a Server.\nfunc NewServer()"))
+		Expect(docs[2].Metadata).To(HaveKeyWithValue("symbol", "NewServer"))
+		Expect(docs[2].Metadata).To(HaveKeyWithValue("kind", "func"))
+		Expect(docs[3].Metadata).To(HaveKeyWithValue("symbol", "Server.Start"))
+		Expect(docs[3].Metadata).To(HaveKeyWithValue("kind", "method"))
+		Expect(docs[3].Metadata).To(HaveKeyWithValue("start_line", 16))
+		Expect(docs[3].Metadata).To(HaveKeyWithValue("end_line", 19))
+		Expect(docs[4].PageContent).To(HaveSuffix("// The end."))
+		Expect(docs[4].Metadata).To(HaveKeyWithValue("symbol", "A, B"))
+		Expect(docs[4].Metadata).To(HaveKeyWithValue("kind", "const"))
+	})
+
+	It("splits large declarations with the splitter", func() {
+		path := write(src)
+		splitter := flowllm.GoSplitter(flowllm.SplitterOptions{ChunkSize: 40})
+
+		docs, err := flowllm.LoadDocs(10, loaders.GoFile(path, loaders.GoFileOptions{Splitter: splitter}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(docs)).To(BeNumerically(">", 5))
+		for _, doc := range docs {
+			Expect(doc.Metadata).To(HaveKey("symbol"))
+		}
+	})
+
+	It("returns an error for invalid Go code", func() {
+		path := write("package server\n\nfunc {")
+
+		_, err := flowllm.LoadDocs(10, loaders.GoFile(path, loaders.GoFileOptions{}))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,382 @@
+package loaders
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLOptions for the HTML loader
+type HTMLOptions struct {
+	// ExcludeTags are additional tags removed from the content, besides scripts, styles,
+	// navigation, headers, footers, forms and other boilerplate
+	ExcludeTags []string
+	// KeepHeaders keeps all header elements. By default, only the headers of articles and of the
+	// main content are kept, as the others are usually the banner of the site
+	KeepHeaders bool
+	// Splitter is used to split the content of the page. Optional
+	Splitter flowllm.Splitter
+}
+
+// boilerplateTags are removed from the content, along with all their children. Headers inside an
+// article or the main content are kept, as they usually hold its title and byline
+var boilerplateTags = []atom.Atom{
+	atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Iframe, atom.Svg, atom.Canvas,
+	atom.Nav, atom.Header, atom.Footer, atom.Aside, atom.Form, atom.Button, atom.Select, atom.Dialog,
+}
+
+// boilerplateRoles and boilerplateNames are ARIA roles and class/id names that mark an element as
+// boilerplate
+var (
+	boilerplateRoles = []string{"navigation", "banner", "contentinfo", "search", "complementary", "dialog"}
+	boilerplateNames = []string{"nav", "navbar", "menu", "sidebar", "footer", "header", "breadcrumb", "breadcrumbs",
+		"cookie-banner", "cookies", "advertisement", "ads", "share", "social"}
+)
+
+// HTML creates a DocumentLoader that loads an HTML file as a Document. Scripts, navigation and
+// other boilerplate are removed, and the remaining content is converted to Markdown-like text,
+// suitable for the MarkdownSplitter. The title, meta description and canonical URL of the page
+// are recorded in the "title", "description" and "canonical" metadata, and the targets of its
+// links in "links". The path of the file is recorded as the "source" metadata.
+func HTML(path string, opts HTMLOptions) flowllm.DocumentLoaderFunc {
+	return streamDocuments(func() (io.Closer, recordReader, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f, htmlDocument(f, path, opts), nil
+	}, opts.Splitter)
+}
+
+// HTMLReader creates a DocumentLoader that loads the HTML read from r as a Document, like HTML.
+// The source is recorded as the "source" metadata.
+func HTMLReader(r io.Reader, source string, opts HTMLOptions) flowllm.DocumentLoaderFunc {
+	return streamDocuments(func() (io.Closer, recordReader, error) {
+		return io.NopCloser(r), htmlDocument(r, source, opts), nil
+	}, opts.Splitter)
+}
+
+func htmlDocument(r io.Reader, source string, opts HTMLOptions) recordReader {
+	done := false
+	return func() (flowllm.Document, error) {
+		if done {
+			return flowllm.Document{}, io.EOF
+		}
+		done = true
+		doc, err := parseHTML(r, opts)
+		if err != nil {
+			return flowllm.Document{}, err
+		}
+		doc.Metadata["source"] = source
+		return doc, nil
+	}
+}
+
+// parseHTML converts the HTML to a Document, with the metadata extracted from the page.
+func parseHTML(r io.Reader, opts HTMLOptions) (flowllm.Document, error) {
+	root, err := html.Parse(r)
+	if err != nil {
+		return flowllm.Document{}, err
+	}
+
+	metadata := map[string]any{}
+	var links []string
+	var body, main *html.Node
+	var articles []*html.Node
+	walkHTML(root, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		switch n.DataAtom {
+		case atom.Title:
+			if _, ok := metadata["title"]; !ok {
+				metadata["title"] = collapseSpaces(textContent(n))
+			}
+		case atom.Meta:
+			name := strings.ToLower(attr(n, "name") + attr(n, "property"))
+			if (name == "description" || name == "og:description") && metadata["description"] == nil {
+				metadata["description"] = strings.TrimSpace(attr(n, "content"))
+			}
+		case atom.Link:
+			if strings.EqualFold(attr(n, "rel"), "canonical") {
+				metadata["canonical"] = strings.TrimSpace(attr(n, "href"))
+			}
+		case atom.Html:
+			if lang := attr(n, "lang"); lang != "" {
+				metadata["language"] = lang
+			}
+		case atom.Body:
+			body = n
+		case atom.Main:
+			if main == nil {
+				main = n
+			}
+		case atom.Article:
+			articles = append(articles, n)
+		case atom.A:
+			href := strings.TrimSpace(attr(n, "href"))
+			if href != "" && !strings.HasPrefix(href, "#") && !strings.HasPrefix(href, "javascript:") && !slices.Contains(links, href) {
+				links = append(links, href)
+			}
+		}
+		return true
+	})
+	if len(links) > 0 {
+		metadata["links"] = links
+	}
+
+	// Prefer the main content of the page, if it is marked up
+	content := body
+	switch {
+	case main != nil:
+		content = main
+	case len(articles) == 1:
+		content = articles[0]
+	case content == nil:
+		content = root
+	}
+
+	w := &markdownWriter{excludeTags: opts.ExcludeTags, keepHeaders: opts.KeepHeaders, lineStart: true}
+	w.render(content)
+	return flowllm.Document{PageContent: w.String(), Metadata: metadata}, nil
+}
+
+// walkHTML calls fn for each node of the tree, in depth-first order. If fn returns false, the
+// children of the node are skipped.
+func walkHTML(n *html.Node, fn func(*html.Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walkHTML(c, fn)
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	walkHTML(n, func(n *html.Node) bool {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		return true
+	})
+	return sb.String()
+}
+
+var spaces = regexp.MustCompile(`\s+`)
+
+func collapseSpaces(s string) string {
+	return strings.TrimSpace(spaces.ReplaceAllString(s, " "))
+}
+
+// markdownWriter renders the HTML nodes as Markdown-like text.
+type markdownWriter struct {
+	sb           strings.Builder
+	excludeTags  []string
+	keepHeaders  bool
+	listDepth    int
+	quoteDepth   int
+	contentDepth int
+	tableRows    int
+	lineStart    bool
+}
+
+// String returns the rendered text. Consecutive blank lines are collapsed into one, keeping the
+// one with the fewest blockquote markers, so quotes are properly closed.
+func (w *markdownWriter) String() string {
+	var result []string
+	var blank *string
+	for _, line := range strings.Split(w.sb.String(), "\n") {
+		line = strings.TrimRight(line, " ")
+		if strings.Trim(line, ">") == "" {
+			if blank == nil || len(line) < len(*blank) {
+				l := line
+				blank = &l
+			}
+			continue
+		}
+		if blank != nil && len(result) > 0 {
+			result = append(result, *blank)
+		}
+		blank = nil
+		result = append(result, line)
+	}
+	return strings.Join(result, "\n")
+}
+
+func (w *markdownWriter) isBoilerplate(n *html.Node) bool {
+	contentHeader := n.DataAtom == atom.Header && (w.keepHeaders || w.contentDepth > 0)
+	if (slices.Contains(boilerplateTags, n.DataAtom) && !contentHeader) || slices.Contains(w.excludeTags, n.Data) {
+		return true
+	}
+	if attr(n, "aria-hidden") == "true" || slices.ContainsFunc(n.Attr, func(a html.Attribute) bool { return a.Key == "hidden" }) {
+		return true
+	}
+	if slices.Contains(boilerplateRoles, attr(n, "role")) {
+		return true
+	}
+	names := strings.Fields(strings.ToLower(attr(n, "class") + " " + attr(n, "id")))
+	return slices.ContainsFunc(names, func(name string) bool { return slices.Contains(boilerplateNames, name) })
+}
+
+// newLine starts a new line, prefixed with the blockquote markers.
+func (w *markdownWriter) newLine() {
+	w.sb.WriteString("\n" + strings.Repeat("> ", w.quoteDepth))
+	w.lineStart = true
+}
+
+func (w *markdownWriter) write(s string) {
+	if s != "" {
+		w.sb.WriteString(s)
+		w.lineStart = false
+	}
+}
+
+// newBlock separates the following content from the previous one with a blank line.
+func (w *markdownWriter) newBlock() {
+	w.newLine()
+	w.newLine()
+}
+
+func (w *markdownWriter) text(s string) {
+	s = spaces.ReplaceAllString(s, " ")
+	if w.lineStart {
+		s = strings.TrimLeft(s, " ")
+	}
+	w.write(s)
+}
+
+func (w *markdownWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.render(c)
+	}
+}
+
+func (w *markdownWriter) render(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.DocumentNode:
+		w.children(n)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if w.isBoilerplate(n) || n.DataAtom == atom.Head {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level, _ := strconv.Atoi(n.Data[1:])
+		if text := collapseSpaces(textContent(n)); text != "" {
+			w.newBlock()
+			w.write(strings.Repeat("#", level) + " " + text)
+			w.newBlock()
+		}
+	case atom.Ul, atom.Ol:
+		if w.listDepth == 0 {
+			w.newBlock()
+		}
+		w.listDepth++
+		idx := 0
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || c.DataAtom != atom.Li || w.isBoilerplate(c) {
+				continue
+			}
+			idx++
+			marker := "- "
+			if n.DataAtom == atom.Ol {
+				marker = strconv.Itoa(idx) + ". "
+			}
+			w.newLine()
+			w.write(strings.Repeat("  ", w.listDepth-1) + marker)
+			w.children(c)
+		}
+		w.listDepth--
+		if w.listDepth == 0 {
+			w.newBlock()
+		}
+	case atom.Pre:
+		w.newBlock()
+		w.write("```")
+		for _, line := range strings.Split(strings.Trim(textContent(n), "\n"), "\n") {
+			w.newLine()
+			w.write(line)
+		}
+		w.newLine()
+		w.write("```")
+		w.newBlock()
+	case atom.Code:
+		w.write("`" + textContent(n) + "`")
+	case atom.Blockquote:
+		w.newBlock()
+		w.quoteDepth++
+		w.newLine()
+		w.children(n)
+		w.quoteDepth--
+		w.newBlock()
+	case atom.Br:
+		w.newLine()
+	case atom.Hr:
+		w.newBlock()
+		w.write("---")
+		w.newBlock()
+	case atom.Tr:
+		w.newLine()
+		w.write("|")
+		cells := 0
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && (c.DataAtom == atom.Td || c.DataAtom == atom.Th) {
+				w.write(" " + markdownCell(textContent(c)) + " |")
+				cells++
+			}
+		}
+		// The first row is the header of the table, followed by the separator row
+		if w.tableRows == 0 {
+			w.newLine()
+			w.write("|" + strings.Repeat(" --- |", cells))
+		}
+		w.tableRows++
+	case atom.Table:
+		rows := w.tableRows
+		w.tableRows = 0
+		w.newBlock()
+		w.children(n)
+		w.newBlock()
+		w.tableRows = rows
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Dl, atom.Dt, atom.Dd,
+		atom.Figure, atom.Figcaption, atom.Details, atom.Summary, atom.Address:
+		if n.DataAtom == atom.Article || n.DataAtom == atom.Main {
+			w.contentDepth++
+			defer func() { w.contentDepth-- }()
+		}
+		if w.listDepth > 0 {
+			w.children(n)
+			return
+		}
+		w.newBlock()
+		w.children(n)
+		w.newBlock()
+	default:
+		w.children(n)
+	}
+}
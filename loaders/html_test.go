@@ -0,0 +1,151 @@
+package loaders_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const samplePage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+	<title>  Fruit   Guide </title>
+	<meta name="description" content="All about fruits">
+	<link rel="canonical" href="https://example.com/fruits">
+	<style>body { color: red; }</style>
+	<script>console.log("hi")</script>
+</head>
+<body>
+	<header><a href="/">Home</a></header>
+	<nav><ul><li><a href="/about">About</a></li></ul></nav>
+	<div class="sidebar">Popular posts</div>
+	<div>
+		<h1>Fruits</h1>
+		<p>Fruits are   <strong>delicious</strong>.<br>Eat them <code>daily</code>.</p>
+		<h2>Favorites</h2>
+		<ul>
+			<li>Apple
+				<ol><li>Gala</li><li>Fuji</li></ol>
+			</li>
+			<li><a href="https://example.com/banana">Banana</a></li>
+		</ul>
+		<blockquote><p>An apple a day keeps the doctor away.</p></blockquote>
+		<pre>func main() {
+	eat("apple")
+}</pre>
+		<table><tr><th>Fruit</th><th>Color</th></tr><tr><td>Apple</td><td>Red</td></tr></table>
+		<p hidden>Hidden text</p>
+	</div>
+	<footer>Copyright</footer>
+</body>
+</html>`
+
+var _ = Describe("HTML", func() {
+	It("converts the content to Markdown-like text, removing boilerplate", func() {
+		docs, err := flowllm.LoadDocs(10, loaders.HTMLReader(strings.NewReader(samplePage), "fruits.html", loaders.HTMLOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(1))
+		Expect(docs[0].PageContent).To(Equal(strings.Join([]string{
+			"# Fruits",
+			"",
+			"Fruits are delicious.",
+			"Eat them `daily`.",
+			"",
+			"## Favorites",
+			"",
+			"- Apple",
+			"  1. Gala",
+			"  2. Fuji",
+			"- Banana",
+			"",
+			"> An apple a day keeps the doctor away.",
+			"",
+			"```",
+			"func main() {",
+			"\teat(\"apple\")",
+			"}",
+			"```",
+			"",
+			"| Fruit | Color |",
+			"| --- | --- |",
+			"| Apple | Red |",
+		}, "\n")))
+	})
+
+	It("records the page metadata", func() {
+		docs, err := flowllm.LoadDocs(10, loaders.HTMLReader(strings.NewReader(samplePage), "fruits.html", loaders.HTMLOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs[0].Metadata).To(Equal(map[string]any{
+			"source":      "fruits.html",
+			"title":       "Fruit Guide",
+			"description": "All about fruits",
+			"canonical":   "https://example.com/fruits",
+			"language":    "en",
+			"links":       []string{"/", "/about", "https://example.com/banana"},
+		}))
+	})
+
+	It("keeps the headers of articles and of the main content", func() {
+		page := `<html><body><header>Site</header><main><header><h1>Title</h1><p>By Jane</p></header>` +
+			`<article><header><h2>Post</h2></header><p>Text</p></article></main></body></html>`
+		docs, err := flowllm.LoadDocs(10, loaders.HTMLReader(strings.NewReader(page), "page.html", loaders.HTMLOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs[0].PageContent).To(Equal("# Title\n\nBy Jane\n\n## Post\n\nText"))
+
+		page = `<html><body><header>Site</header><div><p>Content</p></div></body></html>`
+		docs, err = flowllm.LoadDocs(10, loaders.HTMLReader(strings.NewReader(page), "page.html", loaders.HTMLOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs[0].PageContent).To(Equal("Content"))
+	})
+
+	It("uses only the main content, when available", func() {
+		page := `<html><body><div>Menu</div><main><p>Main content</p></main><div>Related</div></body></html>`
+		docs, err := flowllm.LoadDocs(10, loaders.HTMLReader(strings.NewReader(page), "page.html", loaders.HTMLOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs[0].PageContent).To(Equal("Main content"))
+	})
+
+	It("escapes the pipes in the table cells", func() {
+		page := `<html><body><table><tr><th>Operator</th><th>Meaning</th></tr><tr><td><code>a | b</code></td><td>or</td></tr></table></body></html>`
+		docs, err := flowllm.LoadDocs(10, loaders.HTMLReader(strings.NewReader(page), "page.html", loaders.HTMLOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs[0].PageContent).To(Equal("| Operator | Meaning |\n| --- | --- |\n| a \\| b | or |"))
+	})
+
+	It("removes the excluded tags", func() {
+		page := `<html><body><p>Keep</p><aside>Aside</aside><table><tr><td>Drop</td></tr></table></body></html>`
+		docs, err := flowllm.LoadDocs(10, loaders.HTMLReader(strings.NewReader(page), "page.html", loaders.HTMLOptions{
+			ExcludeTags: []string{"table"},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs[0].PageContent).To(Equal("Keep"))
+	})
+
+	It("loads HTML files, splitting the content", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "fruits.html")
+		Expect(os.WriteFile(path, []byte(samplePage), 0600)).To(Succeed())
+
+		loader := loaders.HTML(path, loaders.HTMLOptions{
+			Splitter: flowllm.MarkdownSplitter(flowllm.SplitterOptions{ChunkSize: 60}),
+		})
+		doc, err := loader.LoadNext(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(doc.PageContent).To(HavePrefix("# Fruits"))
+		Expect(doc.Metadata).To(HaveKeyWithValue("source", path))
+		Expect(doc.Metadata).To(HaveKeyWithValue("title", "Fruit Guide"))
+
+		docs, err := flowllm.LoadDocs(10, loader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(docs)).To(BeNumerically(">", 1))
+
+		_, err = loader.LoadNext(context.Background())
+		Expect(err).To(MatchError(io.EOF))
+	})
+})
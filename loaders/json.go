@@ -0,0 +1,265 @@
+package loaders
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/deluan/flowllm"
+)
+
+// JSONOptions for the JSON and JSONL loaders.
+//
+// Paths can be written as JSON pointers (ex: "/data/0/text") or in a jq-like syntax
+// (ex: ".data[0].text"). An empty path selects the whole value.
+type JSONOptions struct {
+	// ContentPath selects the content of the documents in each record. Strings are used as they
+	// are, other values are encoded as JSON. The other fields of the record are copied to the
+	// metadata. Defaults to the whole record
+	ContentPath string
+	// RecordsPath selects the array of records in the file. If the selected value is not an array,
+	// it is loaded as a single record. Only used by the JSON loader. Defaults to the whole file
+	RecordsPath string
+	// Splitter is used to split the content of each record. Optional
+	Splitter flowllm.Splitter
+}
+
+// JSON creates a DocumentLoader that loads the records of a JSON file as Documents. The documents
+// have the path of the file as the "source" metadata, and the index of the record as "index",
+// unless the record has fields with these names.
+//
+// When the file is an array and no RecordsPath is set, the records are decoded one at a time,
+// otherwise the whole file is decoded at once.
+func JSON(path string, opts JSONOptions) flowllm.DocumentLoaderFunc {
+	return streamDocuments(func() (io.Closer, recordReader, error) {
+		contentPath, recordsPath, err := parseJSONPaths(opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		next, err := jsonRecords(f, recordsPath)
+		if err != nil {
+			_ = f.Close()
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return f, recordDocuments(path, contentPath, next), nil
+	}, opts.Splitter)
+}
+
+// JSONL creates a DocumentLoader that loads each line of a JSON Lines file as a Document. The
+// documents have the path of the file as the "source" metadata, and the index of the line as "index",
+// unless the record has fields with these names.
+func JSONL(path string, opts JSONOptions) flowllm.DocumentLoaderFunc {
+	return streamDocuments(func() (io.Closer, recordReader, error) {
+		contentPath, _, err := parseJSONPaths(opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		decoder := json.NewDecoder(f)
+		next := func() (any, error) {
+			var record any
+			err := decoder.Decode(&record)
+			return record, err
+		}
+		return f, recordDocuments(path, contentPath, next), nil
+	}, opts.Splitter)
+}
+
+func parseJSONPaths(opts JSONOptions) (contentPath []string, recordsPath []string, err error) {
+	if contentPath, err = parseJSONPath(opts.ContentPath); err != nil {
+		return nil, nil, err
+	}
+	if recordsPath, err = parseJSONPath(opts.RecordsPath); err != nil {
+		return nil, nil, err
+	}
+	return contentPath, recordsPath, nil
+}
+
+// jsonRecords returns a function that decodes the records of the file, one at a time.
+func jsonRecords(r io.Reader, recordsPath []string) (func() (any, error), error) {
+	reader := bufio.NewReader(r)
+	decoder := json.NewDecoder(reader)
+
+	// Stream the elements of a top level array
+	if len(recordsPath) == 0 && firstNonSpace(reader) == '[' {
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return func() (any, error) {
+			if !decoder.More() {
+				return nil, io.EOF
+			}
+			var record any
+			err := decoder.Decode(&record)
+			return record, err
+		}, nil
+	}
+
+	var file any
+	if err := decoder.Decode(&file); err != nil {
+		return nil, err
+	}
+	value, ok := lookupJSONPath(file, recordsPath)
+	if !ok {
+		return nil, errors.New("records path not found")
+	}
+	records, ok := value.([]any)
+	if !ok {
+		records = []any{value}
+	}
+	return func() (any, error) {
+		if len(records) == 0 {
+			return nil, io.EOF
+		}
+		record := records[0]
+		records = records[1:]
+		return record, nil
+	}, nil
+}
+
+// firstNonSpace returns the first non whitespace byte of the reader, without consuming it.
+func firstNonSpace(reader *bufio.Reader) byte {
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return 0
+		}
+		if !unicode.IsSpace(rune(b[0])) {
+			return b[0]
+		}
+		_, _ = reader.ReadByte()
+	}
+}
+
+// recordDocuments converts the records returned by next into Documents.
+func recordDocuments(path string, contentPath []string, next func() (any, error)) recordReader {
+	index := 0
+	return func() (flowllm.Document, error) {
+		record, err := next()
+		if err != nil {
+			return flowllm.Document{}, err
+		}
+		content, ok := lookupJSONPath(record, contentPath)
+		if !ok {
+			return flowllm.Document{}, fmt.Errorf("%s: content path not found in record %d", path, index)
+		}
+		text, ok := content.(string)
+		if !ok {
+			buf, err := json.Marshal(content)
+			if err != nil {
+				return flowllm.Document{}, err
+			}
+			text = string(buf)
+		}
+
+		// The fields of the record take precedence over the metadata added by the loader
+		metadata := map[string]any{"source": path, "index": index}
+		if fields, ok := withoutJSONPath(record, contentPath).(map[string]any); ok {
+			for k, v := range fields {
+				metadata[k] = v
+			}
+		}
+		index++
+		return flowllm.Document{PageContent: text, Metadata: metadata}, nil
+	}
+}
+
+// parseJSONPath parses a JSON pointer (RFC 6901) or a jq-like path into its reference tokens.
+func parseJSONPath(path string) ([]string, error) {
+	switch {
+	case path == "" || path == "." || path == "/":
+		return nil, nil
+	case strings.HasPrefix(path, "/"):
+		tokens := strings.Split(path[1:], "/")
+		for i, token := range tokens {
+			tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		}
+		return tokens, nil
+	case strings.HasPrefix(path, "."):
+		var tokens []string
+		rest := path
+		for rest != "" {
+			switch {
+			case strings.HasPrefix(rest, "."):
+				rest = rest[1:]
+				end := strings.IndexAny(rest, ".[")
+				if end == -1 {
+					end = len(rest)
+				}
+				if end == 0 {
+					return nil, fmt.Errorf("invalid path %q", path)
+				}
+				tokens = append(tokens, rest[:end])
+				rest = rest[end:]
+			case strings.HasPrefix(rest, "["):
+				end := strings.Index(rest, "]")
+				if end == -1 {
+					return nil, fmt.Errorf("invalid path %q", path)
+				}
+				tokens = append(tokens, strings.Trim(rest[1:end], `"`))
+				rest = rest[end+1:]
+			default:
+				return nil, fmt.Errorf("invalid path %q", path)
+			}
+		}
+		return tokens, nil
+	default:
+		return nil, fmt.Errorf("invalid path %q: must start with '/' or '.'", path)
+	}
+}
+
+func lookupJSONPath(value any, tokens []string) (any, bool) {
+	for _, token := range tokens {
+		switch v := value.(type) {
+		case map[string]any:
+			var ok bool
+			if value, ok = v[token]; !ok {
+				return nil, false
+			}
+		case []any:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			value = v[idx]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// withoutJSONPath returns a copy of the value without the field selected by the tokens. Only
+// objects in the path are copied.
+func withoutJSONPath(value any, tokens []string) any {
+	if len(tokens) == 0 {
+		return nil
+	}
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+	result := make(map[string]any, len(obj))
+	for k, v := range obj {
+		result[k] = v
+	}
+	if len(tokens) == 1 {
+		delete(result, tokens[0])
+	} else if child, ok := result[tokens[0]]; ok {
+		result[tokens[0]] = withoutJSONPath(child, tokens[1:])
+	}
+	return result
+}
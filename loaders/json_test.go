@@ -0,0 +1,126 @@
+package loaders_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSON", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	It("loads each element of a top level array as a document", func() {
+		path := writeFile("posts.json", ` [
+			{"id": 1, "text": "First post", "author": {"name": "Alice", "email": "alice@example.com"}},
+			{"id": 2, "text": "Second post", "author": {"name": "Bob"}}
+		]`)
+		loader := loaders.JSON(path, loaders.JSONOptions{ContentPath: ".text"})
+
+		doc, err := loader.LoadNext(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(doc.PageContent).To(Equal("First post"))
+		Expect(doc.Metadata).To(Equal(map[string]any{
+			"id":     float64(1),
+			"author": map[string]any{"name": "Alice", "email": "alice@example.com"},
+			"source": path,
+			"index":  0,
+		}))
+
+		doc, err = loader.LoadNext(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(doc.PageContent).To(Equal("Second post"))
+		Expect(doc.Metadata).To(HaveKeyWithValue("index", 1))
+
+		_, err = loader.LoadNext(context.Background())
+		Expect(err).To(MatchError(io.EOF))
+	})
+
+	It("selects the records and content with JSON pointers", func() {
+		path := writeFile("data.json", `{"data": {"items": [{"body": {"text": "Hello", "lang": "en"}, "id": "a"}]}}`)
+		docs, err := flowllm.LoadDocs(10, loaders.JSON(path, loaders.JSONOptions{
+			RecordsPath: "/data/items",
+			ContentPath: "/body/text",
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(1))
+		Expect(docs[0].PageContent).To(Equal("Hello"))
+		Expect(docs[0].Metadata).To(HaveKeyWithValue("id", "a"))
+		Expect(docs[0].Metadata).To(HaveKeyWithValue("body", map[string]any{"lang": "en"}))
+	})
+
+	It("loads the whole file as a single record if it is not an array", func() {
+		path := writeFile("single.json", `{"title": "Report", "sections": ["Intro", "Results"]}`)
+		docs, err := flowllm.LoadDocs(10, loaders.JSON(path, loaders.JSONOptions{ContentPath: ".sections[1]"}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(1))
+		Expect(docs[0].PageContent).To(Equal("Results"))
+		Expect(docs[0].Metadata).To(HaveKeyWithValue("title", "Report"))
+	})
+
+	It("keeps the fields of the record with the same names as the loader metadata", func() {
+		path := writeFile("articles.json", `[{"text": "News", "source": "Reuters"}, {"text": "Blog"}]`)
+		docs, err := flowllm.LoadDocs(10, loaders.JSON(path, loaders.JSONOptions{ContentPath: ".text"}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs[0].Metadata).To(Equal(map[string]any{"source": "Reuters", "index": 0}))
+		Expect(docs[1].Metadata).To(Equal(map[string]any{"source": path, "index": 1}))
+	})
+
+	It("encodes non string contents as JSON", func() {
+		path := writeFile("numbers.json", `[{"values": [1, 2, 3]}]`)
+		docs, err := flowllm.LoadDocs(10, loaders.JSON(path, loaders.JSONOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs[0].PageContent).To(Equal(`{"values":[1,2,3]}`))
+		Expect(docs[0].Metadata).To(Equal(map[string]any{"source": path, "index": 0}))
+	})
+
+	It("returns an error if the content path is not found", func() {
+		path := writeFile("posts.json", `[{"text": "First post"}, {"body": "Second post"}]`)
+		docs, err := flowllm.LoadDocs(10, loaders.JSON(path, loaders.JSONOptions{ContentPath: "/text"}))
+		Expect(err).To(MatchError(ContainSubstring("content path not found in record 1")))
+		Expect(docs).To(BeEmpty())
+	})
+
+	It("returns an error for invalid paths", func() {
+		path := writeFile("posts.json", `[]`)
+		_, err := flowllm.LoadDocs(10, loaders.JSON(path, loaders.JSONOptions{ContentPath: "text"}))
+		Expect(err).To(MatchError(ContainSubstring("invalid path")))
+	})
+})
+
+var _ = Describe("JSONL", func() {
+	It("loads each line as a document, splitting the content", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "logs.jsonl")
+		Expect(os.WriteFile(path, []byte(`{"level": "info", "msg": "Server started"}
+{"level": "error", "msg": "Connection lost"}
+`), 0600)).To(Succeed())
+
+		docs, err := flowllm.LoadDocs(10, loaders.JSONL(path, loaders.JSONOptions{
+			ContentPath: ".msg",
+			Splitter:    flowllm.RecursiveTextSplitter(flowllm.SplitterOptions{ChunkSize: 12}),
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(4))
+		Expect(docs[0].PageContent).To(Equal("Server"))
+		Expect(docs[0].Metadata).To(Equal(map[string]any{
+			"level": "info", "source": path, "index": 0, "start_index": 0, "end_index": 6, "start_line": 1, "end_line": 1,
+		}))
+		Expect(docs[3].PageContent).To(Equal("lost"))
+		Expect(docs[3].Metadata).To(HaveKeyWithValue("level", "error"))
+	})
+})
@@ -1,43 +1,139 @@
 package loaders
 
-import "github.com/deluan/flowllm"
+import (
+	"context"
 
-func SplitDocuments(splitter flowllm.Splitter, documents []flowllm.Document) ([]flowllm.Document, error) {
-	var texts []string
-	var metadatas []map[string]any
-	for _, document := range documents {
-		texts = append(texts, document.PageContent)
-		metadatas = append(metadatas, document.Metadata)
-	}
+	"github.com/deluan/flowllm"
+)
 
-	return createDocuments(splitter, texts, metadatas)
-}
+// ParentIDKey is the metadata key used by SplitDocuments to record the ID of the document a chunk
+// was split from.
+const ParentIDKey = "parent_id"
 
-func createDocuments(splitter flowllm.Splitter, texts []string, metadatas []map[string]any) ([]flowllm.Document, error) {
-	if len(metadatas) == 0 {
-		metadatas = make([]map[string]any, len(texts))
-		for i := range metadatas {
-			metadatas[i] = make(map[string]any)
-		}
-	}
+// Metadata keys used by SplitDocuments to record the position of a chunk in its document, to be
+// used in citations. If the document was itself split from a larger text and has these keys in its
+// metadata, the positions of its chunks are relative to that text.
+const (
+	// StartIndexKey is the byte offset where the chunk starts
+	StartIndexKey = "start_index"
+	// EndIndexKey is the byte offset where the chunk ends (exclusive)
+	EndIndexKey = "end_index"
+	// StartLineKey is the line where the chunk starts, starting at 1
+	StartLineKey = "start_line"
+	// EndLineKey is the line where the chunk ends, starting at 1
+	EndLineKey = "end_line"
+)
 
-	var documents []flowllm.Document
-	for i, text := range texts {
-		chunks, err := splitter(text)
+// SplitDocuments splits the documents into chunks using the splitter. Each chunk gets a copy of the
+// metadata of its document, and its position in the document, as located by flowllm.LocateChunks.
+// If the document has an ID, it is recorded in the chunk's metadata under the ParentIDKey.
+func SplitDocuments(splitter flowllm.Splitter, documents []flowllm.Document) ([]flowllm.Document, error) {
+	return SplitDocumentChunks(flowllm.ToChunkSplitter(splitter), documents)
+}
+
+// SplitDocumentChunks splits the documents into chunks using the splitter. Each chunk gets a copy of
+// the metadata of its document, and its position in the document, under the StartIndexKey,
+// EndIndexKey, StartLineKey and EndLineKey. Chunks the splitter could not locate keep the position
+// of their document, if it has one. If the document has an ID, it is recorded in the chunk's
+// metadata under the ParentIDKey.
+func SplitDocumentChunks(splitter flowllm.ChunkSplitter, documents []flowllm.Document) ([]flowllm.Document, error) {
+	var result []flowllm.Document
+	for _, document := range documents {
+		chunks, err := splitter(document.PageContent)
 		if err != nil {
 			return nil, err
 		}
 		for _, chunk := range chunks {
-			metadata := make(map[string]any)
-			for k, v := range metadatas[i] {
+			metadata := make(map[string]any, len(document.Metadata)+5)
+			for k, v := range document.Metadata {
 				metadata[k] = v
 			}
-			documents = append(documents, flowllm.Document{
-				PageContent: chunk,
+			if document.ID != "" {
+				metadata[ParentIDKey] = document.ID
+			}
+			setChunkPosition(metadata, chunk)
+			result = append(result, flowllm.Document{
+				PageContent: chunk.Text,
 				Metadata:    metadata,
 			})
 		}
 	}
+	return result, nil
+}
 
-	return documents, nil
+// SplitDocumentsContext splits the documents into chunks like SplitDocuments, using a splitter that
+// takes a context, like the flowllm.SemanticSplitter.
+func SplitDocumentsContext(ctx context.Context, splitter flowllm.ContextSplitter, documents []flowllm.Document) ([]flowllm.Document, error) {
+	return SplitDocumentChunks(func(text string) ([]flowllm.Chunk, error) {
+		chunks, err := splitter(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		return flowllm.LocateChunks(text, chunks), nil
+	}, documents)
+}
+
+// SplitLoader creates a DocumentLoader that splits the documents of another loader, as they are
+// loaded, with SplitDocumentsContext. The context of each call is passed to the splitter. Errors
+// returned by the loader or the splitter are returned by the call, and by all calls after it.
+func SplitLoader(loader flowllm.DocumentLoader, splitter flowllm.ContextSplitter) flowllm.DocumentLoaderFunc {
+	var (
+		pending []flowllm.Document
+		done    error
+	)
+	return func(ctx context.Context) (flowllm.Document, error) {
+		for len(pending) == 0 {
+			if done != nil {
+				return flowllm.Document{}, done
+			}
+			doc, err := loader.LoadNext(ctx)
+			if err == nil {
+				pending, err = SplitDocumentsContext(ctx, splitter, []flowllm.Document{doc})
+			}
+			if err != nil {
+				pending = nil
+				done = err
+			}
+		}
+		doc := pending[0]
+		pending = pending[1:]
+		return doc, nil
+	}
+}
+
+// setChunkPosition records the position of the chunk in the metadata, which is a copy of the
+// metadata of the document it was split from. Positions already in the metadata are the position
+// of that document in a larger text, so the position of the chunk is made relative to it.
+func setChunkPosition(metadata map[string]any, chunk flowllm.Chunk) {
+	if chunk.StartIndex < 0 {
+		return
+	}
+	parentIndex := metadataInt(metadata[StartIndexKey])
+	parentLine := metadataInt(metadata[StartLineKey])
+	if parentLine == 0 {
+		parentLine = 1
+	}
+	metadata[StartIndexKey] = parentIndex + chunk.StartIndex
+	metadata[EndIndexKey] = parentIndex + chunk.EndIndex
+	metadata[StartLineKey] = parentLine + chunk.StartLine - 1
+	metadata[EndLineKey] = parentLine + chunk.EndLine - 1
+}
+
+// metadataInt converts a numeric metadata value to an int. Documents loaded from JSON, like the ones
+// from JSON-backed stores, have float64 numbers, and other stores may return int64.
+func metadataInt(value any) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float32:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
 }
@@ -1,6 +1,9 @@
 package loaders_test
 
 import (
+	"archive/zip"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -11,3 +14,20 @@ func TestLoaders(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Loaders Suite")
 }
+
+// writeZip creates a zip file in a temporary directory, with the given files.
+func writeZip(name string, files map[string]string) string {
+	path := filepath.Join(GinkgoT().TempDir(), name)
+	f, err := os.Create(path)
+	Expect(err).ToNot(HaveOccurred())
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = fw.Write([]byte(content))
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(w.Close()).To(Succeed())
+	return path
+}
@@ -0,0 +1,114 @@
+package loaders_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SplitDocuments", func() {
+	var splitter flowllm.Splitter
+
+	BeforeEach(func() {
+		splitter = flowllm.RecursiveTextSplitter(flowllm.SplitterOptions{ChunkSize: 10})
+	})
+
+	It("copies the metadata of the document to each chunk, with its position", func() {
+		docs, err := loaders.SplitDocuments(splitter, []flowllm.Document{
+			{PageContent: "This is a small text", Metadata: map[string]any{"source": "small.txt"}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(2))
+		Expect(docs[0].Metadata).To(Equal(map[string]any{
+			"source": "small.txt", "start_index": 0, "end_index": 9, "start_line": 1, "end_line": 1,
+		}))
+		Expect(docs[1].Metadata).To(Equal(map[string]any{
+			"source": "small.txt", "start_index": 10, "end_index": 20, "start_line": 1, "end_line": 1,
+		}))
+	})
+
+	It("records the lines of each chunk, relative to the position of the document", func() {
+		splitter = flowllm.RecursiveTextSplitter(flowllm.SplitterOptions{ChunkSize: 12})
+		docs, err := loaders.SplitDocuments(splitter, []flowllm.Document{{
+			PageContent: "First line\nSecond line\n\nThird line",
+			Metadata:    map[string]any{"start_index": 100, "start_line": 10},
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(3))
+		Expect(docs[1].PageContent).To(Equal("Second line"))
+		Expect(docs[1].Metadata).To(Equal(map[string]any{"start_index": 111, "end_index": 122, "start_line": 11, "end_line": 11}))
+		Expect(docs[2].Metadata).To(Equal(map[string]any{"start_index": 124, "end_index": 134, "start_line": 13, "end_line": 13}))
+	})
+
+	It("re-splits documents with positions loaded from JSON, as float64", func() {
+		splitter = flowllm.RecursiveTextSplitter(flowllm.SplitterOptions{ChunkSize: 12})
+		docs, err := loaders.SplitDocuments(splitter, []flowllm.Document{{
+			PageContent: "First line\nSecond line",
+			Metadata:    map[string]any{"start_index": float64(100), "end_index": float64(122), "start_line": float64(10), "end_line": float64(11)},
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(2))
+		Expect(docs[1].Metadata).To(Equal(map[string]any{"start_index": 111, "end_index": 122, "start_line": 11, "end_line": 11}))
+	})
+
+	It("records the ID of the parent document in the chunks", func() {
+		parent := flowllm.Document{ID: "doc-1", PageContent: "This is a small text", Metadata: map[string]any{"source": "small.txt"}}
+		docs, err := loaders.SplitDocuments(splitter, []flowllm.Document{parent})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(2))
+		for _, doc := range docs {
+			Expect(doc.Metadata).To(HaveKeyWithValue(loaders.ParentIDKey, "doc-1"))
+			Expect(doc.Metadata).To(HaveKeyWithValue("source", "small.txt"))
+		}
+		Expect(parent.Metadata).ToNot(HaveKey(loaders.ParentIDKey))
+	})
+})
+
+var _ = Describe("SplitLoader", func() {
+	type ctxKey struct{}
+
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "words.txt")
+		Expect(os.WriteFile(path, []byte("one two\nthree"), 0600)).To(Succeed())
+	})
+
+	It("splits the documents of the loader, passing the context to the splitter", func() {
+		splitter := func(ctx context.Context, text string) ([]string, error) {
+			Expect(ctx.Value(ctxKey{})).To(Equal("value"))
+			return strings.Fields(text), nil
+		}
+		loader := loaders.SplitLoader(loaders.TextFile(path), splitter)
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+		var docs []flowllm.Document
+		for {
+			doc, err := loader.LoadNext(ctx)
+			if err != nil {
+				Expect(err).To(MatchError(io.EOF))
+				break
+			}
+			docs = append(docs, doc)
+		}
+		Expect(docs).To(HaveLen(3))
+		Expect(docs[2].PageContent).To(Equal("three"))
+		Expect(docs[2].Metadata).To(Equal(map[string]any{
+			"source": path, "start_index": 8, "end_index": 13, "start_line": 2, "end_line": 2,
+		}))
+	})
+
+	It("returns the errors of the splitter", func() {
+		splitter := func(context.Context, string) ([]string, error) { return nil, errors.New("boom") }
+		_, err := flowllm.LoadDocs(10, loaders.SplitLoader(loaders.TextFile(path), splitter))
+		Expect(err).To(MatchError("boom"))
+	})
+})
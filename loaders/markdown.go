@@ -0,0 +1,159 @@
+package loaders
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/deluan/flowllm"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// HeadingsKey is the metadata key used by SplitMarkdownDocuments to record the titles of the
+	// headings a chunk is nested under, as a []string, from the top level down
+	HeadingsKey = "headings"
+	// HeadingPathKey is the metadata key used by SplitMarkdownDocuments to record the titles of the
+	// headings a chunk is nested under, joined by " > " (ex: "Guide > Install > Linux")
+	HeadingPathKey = "heading_path"
+)
+
+// MarkdownOptions for the Markdown loader
+type MarkdownOptions struct {
+	// Splitter is used to split the sections of the document. Optional
+	Splitter flowllm.Splitter
+}
+
+// Markdown creates a DocumentLoader that loads a Markdown file, returning one Document for each
+// section of the file, as split by SplitMarkdownDocuments. The YAML (delimited by "---") or TOML
+// (delimited by "+++") front matter of the file is parsed and its fields are added to the metadata
+// of all documents, and the path of the file is recorded as the "source" metadata.
+func Markdown(path string, opts MarkdownOptions) flowllm.DocumentLoaderFunc {
+	return streamDocuments(func() (io.Closer, recordReader, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		metadata, content, err := parseFrontMatter(string(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		metadata["source"] = path
+		// Positions of the chunks are relative to the file, not to the content after the front matter
+		offset := len(data) - len(content)
+		metadata[StartIndexKey] = offset
+		metadata[EndIndexKey] = len(data)
+		metadata[StartLineKey] = strings.Count(string(data[:offset]), "\n") + 1
+		metadata[EndLineKey] = strings.Count(string(data), "\n") + 1
+
+		docs, err := SplitMarkdownDocuments(opts.Splitter, []flowllm.Document{{PageContent: content, Metadata: metadata}})
+		if err != nil {
+			return nil, nil, err
+		}
+		return io.NopCloser(nil), func() (flowllm.Document, error) {
+			if len(docs) == 0 {
+				return flowllm.Document{}, io.EOF
+			}
+			doc := docs[0]
+			docs = docs[1:]
+			return doc, nil
+		}, nil
+	}, nil)
+}
+
+// SplitMarkdownDocuments splits the Markdown documents into their sections, using
+// flowllm.MarkdownSections, and then splits each section with the splitter, if one is provided.
+// Each chunk gets a copy of the metadata of its document, and the headings its section is nested
+// under, in the HeadingsKey and HeadingPathKey metadata, and its position in the document, like in
+// SplitDocuments. If the document has an ID, it is recorded in the chunk's metadata under the
+// ParentIDKey. Sections with only a heading are skipped, as their title is already part of the
+// headings of the following sections.
+func SplitMarkdownDocuments(splitter flowllm.Splitter, documents []flowllm.Document) ([]flowllm.Document, error) {
+	var result []flowllm.Document
+	for _, document := range documents {
+		markdownSections := flowllm.MarkdownSections(document.PageContent)
+		contents := make([]string, len(markdownSections))
+		for i, section := range markdownSections {
+			contents[i] = section.Content
+		}
+		positions := flowllm.LocateChunks(document.PageContent, contents)
+
+		var sections []flowllm.Document
+		for i, section := range markdownSections {
+			if strings.TrimSpace(section.Body) == "" {
+				continue
+			}
+			metadata := make(map[string]any, len(document.Metadata)+7)
+			for k, v := range document.Metadata {
+				metadata[k] = v
+			}
+			if document.ID != "" {
+				metadata[ParentIDKey] = document.ID
+			}
+			if len(section.Headings) > 0 {
+				metadata[HeadingsKey] = section.Headings
+				metadata[HeadingPathKey] = strings.Join(section.Headings, " > ")
+			}
+			setChunkPosition(metadata, positions[i])
+			sections = append(sections, flowllm.Document{PageContent: section.Content, Metadata: metadata})
+		}
+		if splitter == nil {
+			result = append(result, sections...)
+			continue
+		}
+		chunks, err := SplitDocuments(splitter, sections)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, chunks...)
+	}
+	return result, nil
+}
+
+// parseFrontMatter extracts the YAML or TOML front matter of a Markdown document, returning its
+// fields and the content after it.
+func parseFrontMatter(text string) (map[string]any, string, error) {
+	metadata := map[string]any{}
+	text = strings.TrimPrefix(text, "\ufeff")
+	var delimiter string
+	switch {
+	case strings.HasPrefix(text, "---\n"), strings.HasPrefix(text, "---\r\n"):
+		delimiter = "---"
+	case strings.HasPrefix(text, "+++\n"), strings.HasPrefix(text, "+++\r\n"):
+		delimiter = "+++"
+	default:
+		return metadata, text, nil
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+		if line == delimiter || (delimiter == "---" && line == "...") {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		// Without a closing delimiter, the first line is a horizontal rule
+		return metadata, text, nil
+	}
+	frontMatter := strings.Join(lines[1:end], "")
+	content := strings.Join(lines[end+1:], "")
+
+	var err error
+	if delimiter == "+++" {
+		_, err = toml.Decode(frontMatter, &metadata)
+	} else {
+		err = yaml.Unmarshal([]byte(frontMatter), &metadata)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid front matter: %w", err)
+	}
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	return metadata, content, nil
+}
@@ -0,0 +1,104 @@
+package loaders_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Markdown", func() {
+	const body = `# Guide
+
+Intro.
+
+## Install
+
+Run the installer.
+
+` + "```sh\n# not a heading\nmake install\n```"
+
+	write := func(content string) string {
+		path := filepath.Join(GinkgoT().TempDir(), "guide.md")
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	It("returns a document for each section, with the YAML front matter and heading path as metadata", func() {
+		path := write("---\ntitle: The Guide\ntags: [setup, linux]\n---\n" + body)
+
+		docs, err := flowllm.LoadDocs(10, loaders.Markdown(path, loaders.MarkdownOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(2))
+		Expect(docs[0].PageContent).To(Equal("# Guide\n\nIntro."))
+		Expect(docs[0].Metadata).To(Equal(map[string]any{
+			"source":       path,
+			"title":        "The Guide",
+			"tags":         []any{"setup", "linux"},
+			"headings":     []string{"Guide"},
+			"heading_path": "Guide",
+			"start_index":  46,
+			"end_index":    61,
+			"start_line":   5,
+			"end_line":     7,
+		}))
+		Expect(docs[1].PageContent).To(Equal("## Install\n\nRun the installer.\n\n```sh\n# not a heading\nmake install\n```"))
+		Expect(docs[1].Metadata).To(HaveKeyWithValue("headings", []string{"Guide", "Install"}))
+		Expect(docs[1].Metadata).To(HaveKeyWithValue("heading_path", "Guide > Install"))
+	})
+
+	It("parses TOML front matter", func() {
+		path := write("+++\ntitle = \"The Guide\"\ndraft = true\n+++\n" + body)
+
+		docs, err := flowllm.LoadDocs(10, loaders.Markdown(path, loaders.MarkdownOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs[0].Metadata).To(HaveKeyWithValue("title", "The Guide"))
+		Expect(docs[0].Metadata).To(HaveKeyWithValue("draft", true))
+	})
+
+	It("returns an error for invalid front matter", func() {
+		path := write("---\ntitle: [unclosed\n---\n" + body)
+
+		_, err := flowllm.LoadDocs(10, loaders.Markdown(path, loaders.MarkdownOptions{}))
+		Expect(err).To(MatchError(ContainSubstring("invalid front matter")))
+	})
+
+	It("splits the sections, keeping their heading path in the chunks", func() {
+		path := write(body)
+		splitter := flowllm.RecursiveTextSplitter(flowllm.SplitterOptions{ChunkSize: 30})
+
+		docs, err := flowllm.LoadDocs(10, loaders.Markdown(path, loaders.MarkdownOptions{Splitter: splitter}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(docs)).To(BeNumerically(">", 2))
+		for _, doc := range docs[1:] {
+			Expect(doc.Metadata).To(HaveKeyWithValue("heading_path", "Guide > Install"))
+		}
+	})
+})
+
+var _ = Describe("SplitMarkdownDocuments", func() {
+	It("splits documents from other loaders, recording their IDs as parent", func() {
+		docs, err := loaders.SplitMarkdownDocuments(nil, []flowllm.Document{{
+			ID:          "doc1",
+			PageContent: "Title\n=====\n\n# Only a heading\n\n## Section\n\nText.",
+			Metadata:    map[string]any{"source": "page.html"},
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(Equal([]flowllm.Document{{
+			PageContent: "## Section\n\nText.",
+			Metadata: map[string]any{
+				"source":       "page.html",
+				"parent_id":    "doc1",
+				"headings":     []string{"Only a heading", "Section"},
+				"heading_path": "Only a heading > Section",
+				"start_index":  31,
+				"end_index":    48,
+				"start_line":   6,
+				"end_line":     8,
+			},
+		}}))
+	})
+})
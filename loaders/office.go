@@ -0,0 +1,154 @@
+package loaders
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// relationshipsNamespace is the namespace of the attributes that reference the relationships of
+// an OOXML part, like r:id
+const relationshipsNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+
+// zipArchive gives access to the parts of the zip based formats, like OOXML and EPUB.
+type zipArchive struct {
+	*zip.ReadCloser
+	path  string
+	files map[string]*zip.File
+}
+
+func openZip(filePath string) (*zipArchive, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	z := &zipArchive{ReadCloser: zr, path: filePath, files: map[string]*zip.File{}}
+	for _, f := range zr.File {
+		z.files[f.Name] = f
+	}
+	return z, nil
+}
+
+func (z *zipArchive) has(name string) bool {
+	return z.files[name] != nil
+}
+
+func (z *zipArchive) open(name string) (io.ReadCloser, error) {
+	f := z.files[name]
+	if f == nil {
+		return nil, fmt.Errorf("%s: missing %s", z.path, name)
+	}
+	return f.Open()
+}
+
+// decode unmarshals the XML part into v.
+func (z *zipArchive) decode(name string, v any) error {
+	r, err := z.open(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if err := xml.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("%s: parsing %s: %w", z.path, name, err)
+	}
+	return nil
+}
+
+// relationships returns the targets of the relationships of an OOXML part, keyed by their ids.
+// The targets are resolved to the names of the parts in the archive.
+func (z *zipArchive) relationships(part string) (map[string]string, error) {
+	var rels struct {
+		Relationships []struct {
+			ID         string `xml:"Id,attr"`
+			Target     string `xml:"Target,attr"`
+			TargetMode string `xml:"TargetMode,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := z.decode(relationshipsPart(part), &rels); err != nil {
+		return nil, err
+	}
+	dir := path.Dir(part)
+	targets := map[string]string{}
+	for _, rel := range rels.Relationships {
+		if rel.TargetMode == "External" {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			targets[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			targets[rel.ID] = path.Join(dir, rel.Target)
+		}
+	}
+	return targets, nil
+}
+
+// relationshipsPart returns the name of the part with the relationships of an OOXML part.
+func relationshipsPart(part string) string {
+	dir, file := path.Split(part)
+	return path.Join(dir, "_rels", file+".rels")
+}
+
+// coreProperties returns the metadata of the OOXML document, with the "source", and the "title"
+// and "author" from its core properties, when present.
+func (z *zipArchive) coreProperties() map[string]any {
+	metadata := map[string]any{"source": z.path}
+	var props struct {
+		Title   string `xml:"title"`
+		Creator string `xml:"creator"`
+	}
+	if !z.has("docProps/core.xml") || z.decode("docProps/core.xml", &props) != nil {
+		return metadata
+	}
+	if title := strings.TrimSpace(props.Title); title != "" {
+		metadata["title"] = title
+	}
+	if author := strings.TrimSpace(props.Creator); author != "" {
+		metadata["author"] = author
+	}
+	return metadata
+}
+
+// attrValue returns the value of the attribute with the given local name, ignoring its namespace.
+func attrValue(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// markdownCell returns the text of a Markdown table cell, in a single line, with the pipes escaped.
+func markdownCell(text string) string {
+	return strings.ReplaceAll(collapseSpaces(text), "|", `\|`)
+}
+
+// markdownTable renders the rows as a Markdown table, using the first row as the header.
+func markdownTable(rows [][]string) string {
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	if columns == 0 {
+		return ""
+	}
+	var lines []string
+	for i, row := range rows {
+		cells := make([]string, columns)
+		for j := range cells {
+			if j < len(row) {
+				cells[j] = markdownCell(row[j])
+			}
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
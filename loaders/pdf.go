@@ -0,0 +1,220 @@
+package loaders
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/ledongthuc/pdf"
+)
+
+var (
+	// ErrPDFEncrypted is returned when a PDF is encrypted and can't be decrypted with PDFOptions.Password
+	ErrPDFEncrypted = errors.New("pdf is encrypted")
+	// ErrPDFNoText is returned when no text can be extracted from any page of a PDF. This usually
+	// means the document is scanned, and would need OCR to be loaded
+	ErrPDFNoText = errors.New("pdf has no extractable text, it may be a scanned document")
+)
+
+// PDFOptions for the PDF loader
+type PDFOptions struct {
+	// Password used to decrypt encrypted files. Files encrypted with an empty user password are
+	// decrypted without it
+	Password string
+	// MergePages returns all pages as a single document, with the pages separated by blank lines.
+	// The Splitter is applied to the merged document
+	MergePages bool
+	// Splitter is used to split the content of the pages. Optional
+	Splitter flowllm.Splitter
+}
+
+// PDF creates a DocumentLoader that extracts the text of a PDF file, returning one Document per
+// page. The page number (starting at 1) is recorded in the "page" metadata and the number of pages
+// of the file in "total_pages". The title and author from the document info are recorded in the
+// "title" and "author" metadata, when present, and the path of the file in "source".
+//
+// Pages without text are skipped. If no page has text, ErrPDFNoText is returned, and if the file
+// can't be decrypted, ErrPDFEncrypted.
+func PDF(path string, opts PDFOptions) flowllm.DocumentLoaderFunc {
+	return streamDocuments(func() (io.Closer, recordReader, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			return nil, nil, err
+		}
+		r, err := openPDF(f, info.Size(), opts.Password)
+		if err != nil {
+			_ = f.Close()
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return f, pdfDocuments(r, path, opts), nil
+	}, opts.Splitter)
+}
+
+// PDFReader creates a DocumentLoader that extracts the text of the PDF read from r, like PDF.
+// The source is recorded as the "source" metadata.
+func PDFReader(r io.ReaderAt, size int64, source string, opts PDFOptions) flowllm.DocumentLoaderFunc {
+	return streamDocuments(func() (io.Closer, recordReader, error) {
+		pr, err := openPDF(r, size, opts.Password)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", source, err)
+		}
+		return io.NopCloser(nil), pdfDocuments(pr, source, opts), nil
+	}, opts.Splitter)
+}
+
+func openPDF(f io.ReaderAt, size int64, password string) (r *pdf.Reader, err error) {
+	// The pdf package panics on some malformed files
+	defer func() {
+		if p := recover(); p != nil {
+			r, err = nil, fmt.Errorf("malformed PDF: %v", p)
+		}
+	}()
+	tried := false
+	r, err = pdf.NewReaderEncrypted(f, size, func() string {
+		if tried {
+			return ""
+		}
+		tried = true
+		return password
+	})
+	// Unsupported encryption methods are reported as malformed or unsupported files
+	if err != nil && (errors.Is(err, pdf.ErrInvalidPassword) || pdfEncrypted(f, size)) {
+		return nil, fmt.Errorf("%w: %v", ErrPDFEncrypted, err)
+	}
+	return r, err
+}
+
+// pdfTrailerSize is how much of the file is read to find the trailer dictionary
+const pdfTrailerSize = 4096
+
+// pdfEncrypted reports whether the trailer of the PDF has an /Encrypt entry. The trailer is the
+// last "trailer" dictionary of the file or, in files with cross-reference streams, the dictionary
+// of the stream pointed by "startxref".
+func pdfEncrypted(f io.ReaderAt, size int64) bool {
+	readAt := func(offset int64) []byte {
+		if offset < 0 {
+			offset = 0
+		}
+		buf := make([]byte, pdfTrailerSize)
+		n, _ := f.ReadAt(buf, offset)
+		return buf[:n]
+	}
+	tail := readAt(size - pdfTrailerSize)
+	start := bytes.LastIndex(tail, []byte("startxref"))
+	if start < 0 {
+		return false
+	}
+	if trailer := bytes.LastIndex(tail[:start], []byte("trailer")); trailer >= 0 {
+		return bytes.Contains(tail[trailer:start], []byte("/Encrypt"))
+	}
+	fields := bytes.Fields(tail[start+len("startxref"):])
+	if len(fields) == 0 {
+		return false
+	}
+	offset, err := strconv.ParseInt(string(fields[0]), 10, 64)
+	if err != nil || offset >= size {
+		return false
+	}
+	dict := readAt(offset)
+	if end := bytes.Index(dict, []byte("stream")); end >= 0 {
+		dict = dict[:end]
+	}
+	return bytes.Contains(dict, []byte("/Encrypt"))
+}
+
+func pdfDocuments(r *pdf.Reader, source string, opts PDFOptions) recordReader {
+	total := r.NumPage()
+	page := 0
+	found := false
+	return func() (flowllm.Document, error) {
+		var pages []string
+		for page < total {
+			page++
+			text, err := pdfPageText(r.Page(page))
+			if err != nil {
+				return flowllm.Document{}, fmt.Errorf("%s: page %d: %w", source, page, err)
+			}
+			if text == "" {
+				continue
+			}
+			found = true
+			if opts.MergePages {
+				pages = append(pages, text)
+				continue
+			}
+			doc := flowllm.Document{PageContent: text, Metadata: pdfMetadata(r, source)}
+			doc.Metadata["page"] = page
+			return doc, nil
+		}
+		if len(pages) > 0 {
+			return flowllm.Document{PageContent: strings.Join(pages, "\n\n"), Metadata: pdfMetadata(r, source)}, nil
+		}
+		if !found {
+			return flowllm.Document{}, fmt.Errorf("%s: %w", source, ErrPDFNoText)
+		}
+		return flowllm.Document{}, io.EOF
+	}
+}
+
+func pdfMetadata(r *pdf.Reader, source string) map[string]any {
+	metadata := map[string]any{"source": source, "total_pages": r.NumPage()}
+	info := r.Trailer().Key("Info")
+	if title := strings.TrimSpace(info.Key("Title").Text()); title != "" {
+		metadata["title"] = title
+	}
+	if author := strings.TrimSpace(info.Key("Author").Text()); author != "" {
+		metadata["author"] = author
+	}
+	return metadata
+}
+
+// pdfPageText reconstructs the lines of text of the page from the position of its glyphs, in the
+// order they are drawn. Glyphs separated by a horizontal gap are separated by a space, and lines
+// separated by a vertical gap larger than the usual line spacing by a blank line.
+func pdfPageText(p pdf.Page) (text string, err error) {
+	if p.V.IsNull() {
+		return "", nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			text, err = "", fmt.Errorf("extracting text: %v", r)
+		}
+	}()
+
+	var sb strings.Builder
+	texts := p.Content().Text
+	var last *pdf.Text
+	for i, t := range texts {
+		if last != nil {
+			size := math.Max(math.Abs(last.FontSize), 1)
+			dy := math.Abs(t.Y - last.Y)
+			switch {
+			case dy > size*1.5:
+				sb.WriteString("\n\n")
+			case dy > size*0.5:
+				sb.WriteString("\n")
+			case t.X-(last.X+last.W) > size*0.2 && t.S != " " && last.S != " ":
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString(t.S)
+		last = &texts[i]
+	}
+
+	var lines []string
+	for _, line := range strings.Split(sb.String(), "\n") {
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
@@ -0,0 +1,145 @@
+package loaders_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PDF", func() {
+	info := "<< /Title (Fruit Guide) /Author (Jane Doe) >>"
+	pages := []string{
+		pdfText("Fruits", "", "Apples are red.", "Bananas are yellow."),
+		"0 0 100 100 re f",
+		pdfText("Grapes are purple."),
+	}
+
+	It("returns one document per page with text, with the page number and document info", func() {
+		path := writePDF(buildPDF(info, "", pages...))
+
+		docs, err := flowllm.LoadDocs(10, loaders.PDF(path, loaders.PDFOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(2))
+		Expect(docs[0].PageContent).To(Equal("Fruits\n\nApples are red.\nBananas are yellow."))
+		Expect(docs[0].Metadata).To(Equal(map[string]any{
+			"source": path, "page": 1, "total_pages": 3, "title": "Fruit Guide", "author": "Jane Doe",
+		}))
+		Expect(docs[1].PageContent).To(Equal("Grapes are purple."))
+		Expect(docs[1].Metadata).To(HaveKeyWithValue("page", 3))
+	})
+
+	It("merges the pages before splitting them", func() {
+		data := buildPDF("", "", pages...)
+		splitter := flowllm.RecursiveTextSplitter(flowllm.SplitterOptions{ChunkSize: 100})
+
+		docs, err := flowllm.LoadDocs(10, loaders.PDFReader(bytes.NewReader(data), int64(len(data)), "fruits.pdf",
+			loaders.PDFOptions{MergePages: true, Splitter: splitter}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(1))
+		Expect(docs[0].PageContent).To(Equal("Fruits\n\nApples are red.\nBananas are yellow.\n\nGrapes are purple."))
+		Expect(docs[0].Metadata).To(Equal(map[string]any{
+			"source": "fruits.pdf", "total_pages": 3, "start_index": 0, "end_index": 63, "start_line": 1, "end_line": 6,
+		}))
+	})
+
+	It("returns ErrPDFNoText when no page has text", func() {
+		path := writePDF(buildPDF("", "", "0 0 100 100 re f"))
+
+		_, err := flowllm.LoadDocs(10, loaders.PDF(path, loaders.PDFOptions{}))
+		Expect(err).To(MatchError(loaders.ErrPDFNoText))
+	})
+
+	It("returns ErrPDFEncrypted when the file can't be decrypted", func() {
+		encrypt := fmt.Sprintf("<< /Filter /Standard /V 1 /R 2 /O <%s> /U <%s> /P -4 >>", strings.Repeat("ab", 32), strings.Repeat("cd", 32))
+		path := writePDF(buildPDF("", encrypt, pages...))
+
+		_, err := flowllm.LoadDocs(10, loaders.PDF(path, loaders.PDFOptions{Password: "secret"}))
+		Expect(err).To(MatchError(loaders.ErrPDFEncrypted))
+	})
+
+	It("returns ErrPDFEncrypted when the encryption method is not supported", func() {
+		encrypt := fmt.Sprintf("<< /Filter /Standard /V 5 /R 6 /Length 256 /O <%s> /U <%s> /P -4 >>", strings.Repeat("ab", 48), strings.Repeat("cd", 48))
+		path := writePDF(buildPDF("", encrypt, pages...))
+
+		_, err := flowllm.LoadDocs(10, loaders.PDF(path, loaders.PDFOptions{}))
+		Expect(err).To(MatchError(loaders.ErrPDFEncrypted))
+	})
+
+	It("returns an error for files that are not PDFs", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "fruits.pdf")
+		Expect(os.WriteFile(path, []byte("not a pdf"), 0600)).To(Succeed())
+
+		_, err := flowllm.LoadDocs(10, loaders.PDF(path, loaders.PDFOptions{}))
+		Expect(err).To(MatchError(ContainSubstring("not a PDF file")))
+	})
+})
+
+// pdfText returns a content stream that draws the lines with 12pt Helvetica. Empty lines
+// add vertical space between the lines.
+func pdfText(lines ...string) string {
+	var sb strings.Builder
+	sb.WriteString("BT /F1 12 Tf 72 720 Td")
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteString(" 0 -14 Td")
+		}
+		if line != "" {
+			sb.WriteString(" (" + line + ") Tj")
+		}
+	}
+	sb.WriteString(" ET")
+	return sb.String()
+}
+
+// buildPDF generates a PDF file with a page for each content stream.
+func buildPDF(info, encrypt string, pages ...string) []byte {
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", ""}
+	var kids []string
+	for _, content := range pages {
+		n := len(objects)
+		kids = append(kids, fmt.Sprintf("%d 0 R", n+1))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents %d 0 R "+
+				"/Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>", n+2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+	var trailer string
+	if info != "" {
+		objects = append(objects, info)
+		trailer += fmt.Sprintf(" /Info %d 0 R", len(objects))
+	}
+	if encrypt != "" {
+		objects = append(objects, encrypt)
+		trailer += fmt.Sprintf(" /Encrypt %d 0 R /ID [<%s> <%s>]", len(objects), strings.Repeat("01", 16), strings.Repeat("01", 16))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R%s >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailer, xref)
+	return buf.Bytes()
+}
+
+func writePDF(data []byte) string {
+	path := filepath.Join(GinkgoT().TempDir(), "fruits.pdf")
+	Expect(os.WriteFile(path, data, 0600)).To(Succeed())
+	return path
+}
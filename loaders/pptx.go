@@ -0,0 +1,218 @@
+package loaders
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"golang.org/x/exp/slices"
+)
+
+// PPTXOptions for the PPTX loader
+type PPTXOptions struct {
+	// Notes includes the speaker notes of the slides in the content, after the text of the slide
+	Notes bool
+	// Splitter is used to split the content of the slides. Optional
+	Splitter flowllm.Splitter
+}
+
+// PPTX creates a DocumentLoader that loads each slide of a PowerPoint presentation as a Document.
+// The title of the slide is rendered as a "#" heading, followed by the text of the other shapes,
+// with tables rendered as Markdown tables. The slide number (starting at 1) is recorded in the
+// "slide" metadata, the number of slides in "total_slides" and the title of the slide in
+// "slide_title". The title and author of the presentation are recorded in the "title" and "author"
+// metadata, when present, and the path of the file in "source". Slides without text are skipped.
+func PPTX(path string, opts PPTXOptions) flowllm.DocumentLoaderFunc {
+	return streamDocuments(func() (io.Closer, recordReader, error) {
+		z, err := openZip(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		slides, err := pptxSlides(z)
+		if err != nil {
+			_ = z.Close()
+			return nil, nil, err
+		}
+		next := 0
+		return z, func() (flowllm.Document, error) {
+			for next < len(slides) {
+				next++
+				slide, err := parsePPTXSlide(z, slides[next-1])
+				if err != nil {
+					return flowllm.Document{}, err
+				}
+				content := slide.content()
+				if opts.Notes {
+					notes, err := pptxNotes(z, slides[next-1])
+					if err != nil {
+						return flowllm.Document{}, err
+					}
+					if notes != "" {
+						content = strings.TrimSpace(content + "\n\nNotes:\n" + notes)
+					}
+				}
+				if content == "" {
+					continue
+				}
+				metadata := z.coreProperties()
+				metadata["slide"] = next
+				metadata["total_slides"] = len(slides)
+				if slide.title != "" {
+					metadata["slide_title"] = slide.title
+				}
+				return flowllm.Document{PageContent: content, Metadata: metadata}, nil
+			}
+			return flowllm.Document{}, io.EOF
+		}, nil
+	}, opts.Splitter)
+}
+
+// pptxSlides returns the parts of the slides, in the order of the presentation.
+func pptxSlides(z *zipArchive) ([]string, error) {
+	var presentation struct {
+		Slides []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sldIdLst>sldId"`
+	}
+	if err := z.decode("ppt/presentation.xml", &presentation); err != nil {
+		return nil, err
+	}
+	rels, err := z.relationships("ppt/presentation.xml")
+	if err != nil {
+		return nil, err
+	}
+	var slides []string
+	for _, slide := range presentation.Slides {
+		if part, ok := rels[slide.ID]; ok {
+			slides = append(slides, part)
+		}
+	}
+	return slides, nil
+}
+
+// pptxIgnoredPlaceholders are the types of the placeholders with text that is not part of the
+// content, like slide numbers and footers
+var pptxIgnoredPlaceholders = []string{"sldNum", "dt", "ftr", "hdr"}
+
+type pptxSlide struct {
+	title  string
+	blocks []string
+}
+
+func (s pptxSlide) content() string {
+	blocks := s.blocks
+	if s.title != "" {
+		blocks = append([]string{"# " + s.title}, blocks...)
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// parsePPTXSlide extracts the text of the shapes and tables of a slide. The text of the title
+// placeholder is the title of the slide.
+func parsePPTXSlide(z *zipArchive, part string) (pptxSlide, error) {
+	r, err := z.open(part)
+	if err != nil {
+		return pptxSlide{}, err
+	}
+	defer r.Close()
+
+	var slide pptxSlide
+	var paragraphs []string
+	var para strings.Builder
+	var rows [][]string
+	var cell []string
+	var placeholder string
+	inTable, inText := false, false
+	d := xml.NewDecoder(r)
+	for {
+		token, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return slide, nil
+		}
+		if err != nil {
+			return pptxSlide{}, fmt.Errorf("%s: parsing %s: %w", z.path, part, err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "sp":
+				paragraphs, placeholder = nil, ""
+			case "ph":
+				placeholder = attrValue(t, "type")
+			case "p":
+				para.Reset()
+			case "t":
+				inText = true
+			case "br":
+				para.WriteString("\n")
+			case "tbl":
+				rows, inTable = nil, true
+			case "tr":
+				rows = append(rows, nil)
+			case "tc":
+				cell = nil
+			}
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text := strings.TrimSpace(para.String())
+				if text == "" {
+					continue
+				}
+				if inTable {
+					cell = append(cell, text)
+				} else {
+					paragraphs = append(paragraphs, text)
+				}
+			case "tc":
+				if len(rows) > 0 {
+					rows[len(rows)-1] = append(rows[len(rows)-1], strings.Join(cell, " "))
+				}
+			case "tbl":
+				inTable = false
+				if table := markdownTable(rows); table != "" {
+					slide.blocks = append(slide.blocks, table)
+				}
+			case "sp":
+				switch {
+				case len(paragraphs) == 0 || slices.Contains(pptxIgnoredPlaceholders, placeholder):
+				case (placeholder == "title" || placeholder == "ctrTitle") && slide.title == "":
+					slide.title = collapseSpaces(strings.Join(paragraphs, " "))
+				default:
+					slide.blocks = append(slide.blocks, strings.Join(paragraphs, "\n"))
+				}
+			}
+		}
+	}
+}
+
+// pptxNotes returns the text of the speaker notes of the slide, if it has any.
+func pptxNotes(z *zipArchive, slidePart string) (string, error) {
+	if !z.has(relationshipsPart(slidePart)) {
+		return "", nil
+	}
+	rels, err := z.relationships(slidePart)
+	if err != nil {
+		return "", err
+	}
+	for _, part := range rels {
+		if !strings.Contains(part, "notesSlide") {
+			continue
+		}
+		notes, err := parsePPTXSlide(z, part)
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(notes.blocks, "\n\n"), nil
+	}
+	return "", nil
+}
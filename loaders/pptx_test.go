@@ -0,0 +1,71 @@
+package loaders_test
+
+import (
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PPTX", func() {
+	var path string
+
+	slide := func(shapes string) string {
+		return `<p:sld xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main">
+			<p:cSld><p:spTree>` + shapes + `</p:spTree></p:cSld></p:sld>`
+	}
+	shape := func(placeholder string, paragraphs ...string) string {
+		s := `<p:sp><p:nvSpPr><p:nvPr>`
+		if placeholder != "" {
+			s += `<p:ph type="` + placeholder + `"/>`
+		}
+		s += `</p:nvPr></p:nvSpPr><p:txBody>`
+		for _, p := range paragraphs {
+			s += `<a:p><a:r><a:t>` + p + `</a:t></a:r></a:p>`
+		}
+		return s + `</p:txBody></p:sp>`
+	}
+
+	BeforeEach(func() {
+		path = writeZip("fruits.pptx", map[string]string{
+			"docProps/core.xml": docxCoreProperties,
+			"ppt/presentation.xml": `<p:presentation xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+				<p:sldIdLst><p:sldId id="256" r:id="rId3"/><p:sldId id="257" r:id="rId2"/><p:sldId id="258" r:id="rId4"/></p:sldIdLst>
+			</p:presentation>`,
+			"ppt/_rels/presentation.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+				<Relationship Id="rId2" Target="slides/slide2.xml"/>
+				<Relationship Id="rId3" Target="slides/slide1.xml"/>
+				<Relationship Id="rId4" Target="slides/slide3.xml"/>
+			</Relationships>`,
+			"ppt/slides/slide1.xml": slide(shape("ctrTitle", "Fruits") + shape("", "Apples", "Bananas") + shape("sldNum", "1")),
+			"ppt/slides/slide2.xml": slide(`<p:pic><p:nvPicPr><p:nvPr/></p:nvPicPr></p:pic>`),
+			"ppt/slides/slide3.xml": slide(shape("title", "Colors") + `<p:graphicFrame><a:graphic><a:graphicData><a:tbl>
+				<a:tr><a:tc><a:txBody><a:p><a:r><a:t>Fruit</a:t></a:r></a:p></a:txBody></a:tc><a:tc><a:txBody><a:p><a:r><a:t>Color</a:t></a:r></a:p></a:txBody></a:tc></a:tr>
+				<a:tr><a:tc><a:txBody><a:p><a:r><a:t>Apple</a:t></a:r></a:p></a:txBody></a:tc><a:tc><a:txBody><a:p><a:r><a:t>Red</a:t></a:r></a:p></a:txBody></a:tc></a:tr>
+			</a:tbl></a:graphicData></a:graphic></p:graphicFrame>`),
+			"ppt/slides/_rels/slide3.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+				<Relationship Id="rId1" Target="../notesSlides/notesSlide1.xml"/>
+			</Relationships>`,
+			"ppt/notesSlides/notesSlide1.xml": slide(shape("sldImg") + shape("body", "Mention the green apples") + shape("sldNum", "3")),
+		})
+	})
+
+	It("loads each slide with text as a document", func() {
+		docs, err := flowllm.LoadDocs(10, loaders.PPTX(path, loaders.PPTXOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(2))
+		Expect(docs[0].PageContent).To(Equal("# Fruits\n\nApples\nBananas"))
+		Expect(docs[0].Metadata).To(Equal(map[string]any{
+			"source": path, "slide": 1, "total_slides": 3, "slide_title": "Fruits", "title": "Fruit Guide", "author": "Jane Doe",
+		}))
+		Expect(docs[1].PageContent).To(Equal("# Colors\n\n| Fruit | Color |\n| --- | --- |\n| Apple | Red |"))
+		Expect(docs[1].Metadata).To(HaveKeyWithValue("slide", 3))
+	})
+
+	It("includes the speaker notes", func() {
+		docs, err := flowllm.LoadDocs(10, loaders.PPTX(path, loaders.PPTXOptions{Notes: true}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(2))
+		Expect(docs[1].PageContent).To(HaveSuffix("| Apple | Red |\n\nNotes:\nMention the green apples"))
+	})
+})
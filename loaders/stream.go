@@ -0,0 +1,106 @@
+package loaders
+
+import (
+	"context"
+	"io"
+
+	"github.com/deluan/flowllm"
+)
+
+// recordReader returns the next document of a file, or io.EOF when there are no more documents.
+type recordReader func() (flowllm.Document, error)
+
+// streamDocuments creates a DocumentLoaderFunc that reads the documents one at a time, opening the
+// file on the first call. The file is closed when all documents are read or on the first error.
+// If a splitter is provided, each document is split into chunks.
+func streamDocuments(open func() (io.Closer, recordReader, error), splitter flowllm.Splitter) flowllm.DocumentLoaderFunc {
+	var (
+		closer  io.Closer
+		next    recordReader
+		pending []flowllm.Document
+		done    error
+	)
+	return func(ctx context.Context) (flowllm.Document, error) {
+		for len(pending) == 0 {
+			if done != nil {
+				return flowllm.Document{}, done
+			}
+			if err := ctx.Err(); err != nil {
+				return flowllm.Document{}, err
+			}
+			if next == nil {
+				var err error
+				closer, next, err = open()
+				if err != nil {
+					done = err
+					continue
+				}
+			}
+			doc, err := next()
+			if err == nil && splitter != nil {
+				pending, err = SplitDocuments(splitter, []flowllm.Document{doc})
+			} else {
+				pending = []flowllm.Document{doc}
+			}
+			if err != nil {
+				pending = nil
+				done = err
+				_ = closer.Close()
+			}
+		}
+		doc := pending[0]
+		pending = pending[1:]
+		return doc, nil
+	}
+}
+
+// channelLoader creates a DocumentLoaderFunc that returns the documents sent to a channel by a
+// pipeline, started on the first call with its context. The pipeline must close the documents
+// channel when done, and then send its first error (or nil) to the error channel. Cancelling the
+// context of the calls stops the pipeline.
+func channelLoader(start func(ctx context.Context) (chan []flowllm.Document, chan error, context.CancelFunc)) flowllm.DocumentLoaderFunc {
+	var (
+		docsC     chan []flowllm.Document
+		finalErrC chan error
+		cancel    context.CancelFunc
+		pending   []flowllm.Document
+		finalErr  error
+	)
+	return func(ctx context.Context) (flowllm.Document, error) {
+		if docsC == nil && finalErr == nil {
+			docsC, finalErrC, cancel = start(ctx)
+		}
+		for len(pending) == 0 || ctx.Err() != nil {
+			if finalErr != nil {
+				return flowllm.Document{}, finalErr
+			}
+			if ctx.Err() != nil {
+				// Drain the pipeline, so no worker is left blocked
+				cancel()
+				go func(docsC chan []flowllm.Document) {
+					for range docsC {
+					}
+				}(docsC)
+				pending = nil
+				finalErr = ctx.Err()
+				continue
+			}
+			select {
+			case docs, ok := <-docsC:
+				if !ok {
+					cancel()
+					finalErr = <-finalErrC
+					if finalErr == nil {
+						finalErr = io.EOF
+					}
+					continue
+				}
+				pending = docs
+			case <-ctx.Done():
+			}
+		}
+		doc := pending[0]
+		pending = pending[1:]
+		return doc, nil
+	}
+}
@@ -0,0 +1,428 @@
+package loaders
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deluan/flowllm"
+	"golang.org/x/exp/slices"
+)
+
+// XLSXOptions for the XLSX loader
+type XLSXOptions struct {
+	// RowPerDocument loads each row as a Document, as "column: value" lines, like the CSV loader.
+	// The first non-empty row of each sheet is the header, with the names of the columns. By
+	// default, each sheet is loaded as a Document, rendered as a Markdown table
+	RowPerDocument bool
+	// Sheets are the names of the sheets to load. Defaults to all sheets
+	Sheets []string
+	// Splitter is used to split the content of the documents. Optional
+	Splitter flowllm.Splitter
+}
+
+// XLSX creates a DocumentLoader that loads the sheets of an Excel workbook. By default, each sheet
+// is loaded as a Document, with its rows rendered as a Markdown table. With
+// XLSXOptions.RowPerDocument, each row is loaded as a Document, with the row number recorded in
+// the "row" metadata. The name of the sheet is recorded in the "sheet" metadata, the title and
+// author of the workbook in "title" and "author", when present, and the path of the file in "source".
+// Empty sheets and rows are skipped. Cells formatted as dates or times are rendered as
+// "2006-01-02", "15:04:05" or "2006-01-02 15:04:05", depending on their number format.
+func XLSX(path string, opts XLSXOptions) flowllm.DocumentLoaderFunc {
+	return streamDocuments(func() (io.Closer, recordReader, error) {
+		z, err := openZip(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		wb, err := openWorkbook(z, opts.Sheets)
+		if err != nil {
+			_ = z.Close()
+			return nil, nil, err
+		}
+		if opts.RowPerDocument {
+			return z, wb.rowDocuments(), nil
+		}
+		return z, wb.sheetDocuments(), nil
+	}, opts.Splitter)
+}
+
+type xlsxSheet struct {
+	name string
+	part string
+}
+
+type xlsxWorkbook struct {
+	z       *zipArchive
+	sheets  []xlsxSheet
+	strings []string
+	// formats has the date format of each cell style, indexed by the style of the cells
+	formats []xlsxDateFormat
+	epoch   time.Time
+}
+
+// xlsxDateFormat is the layout used to render the cells with a date number format. Empty for
+// cells that are not dates.
+type xlsxDateFormat string
+
+const (
+	xlsxDate     xlsxDateFormat = "2006-01-02"
+	xlsxTime     xlsxDateFormat = "15:04:05"
+	xlsxDateTime xlsxDateFormat = "2006-01-02 15:04:05"
+)
+
+// xlsxBuiltinFormats are the built-in number formats for dates and times. 46 ([h]:mm:ss) is an
+// elapsed time, not a time of the day, so it is kept as a number.
+var xlsxBuiltinFormats = map[int]xlsxDateFormat{
+	14: xlsxDate, 15: xlsxDate, 16: xlsxDate, 17: xlsxDate,
+	18: xlsxTime, 19: xlsxTime, 20: xlsxTime, 21: xlsxTime,
+	22: xlsxDateTime,
+	45: xlsxTime, 47: xlsxTime,
+}
+
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	s := t.Text
+	for _, run := range t.Runs {
+		s += run.Text
+	}
+	return s
+}
+
+type xlsxRow struct {
+	Number int `xml:"r,attr"`
+	Cells  []struct {
+		Ref    string   `xml:"r,attr"`
+		Type   string   `xml:"t,attr"`
+		Style  int      `xml:"s,attr"`
+		Value  string   `xml:"v"`
+		Inline xlsxText `xml:"is"`
+	} `xml:"c"`
+}
+
+func openWorkbook(z *zipArchive, names []string) (*xlsxWorkbook, error) {
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+		Properties struct {
+			Date1904 string `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+	}
+	if err := z.decode("xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	rels, err := z.relationships("xl/workbook.xml")
+	if err != nil {
+		return nil, err
+	}
+	wb := &xlsxWorkbook{z: z, epoch: time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)}
+	if p := workbook.Properties.Date1904; p == "1" || p == "true" {
+		wb.epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	for _, sheet := range workbook.Sheets {
+		if len(names) > 0 && !slices.Contains(names, sheet.Name) {
+			continue
+		}
+		if part, ok := rels[sheet.ID]; ok {
+			wb.sheets = append(wb.sheets, xlsxSheet{name: sheet.Name, part: part})
+		}
+	}
+	for _, name := range names {
+		if !wb.hasSheet(name) {
+			return nil, fmt.Errorf("%s: sheet %q not found", z.path, name)
+		}
+	}
+
+	if z.has("xl/sharedStrings.xml") {
+		var shared struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := z.decode("xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+		for _, item := range shared.Items {
+			wb.strings = append(wb.strings, item.String())
+		}
+	}
+	if z.has("xl/styles.xml") {
+		if err := wb.readStyles(); err != nil {
+			return nil, err
+		}
+	}
+	return wb, nil
+}
+
+// readStyles reads the number formats of the cell styles, to find the cells that are dates.
+func (wb *xlsxWorkbook) readStyles() error {
+	var styles struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if err := wb.z.decode("xl/styles.xml", &styles); err != nil {
+		return err
+	}
+	formats := make(map[int]xlsxDateFormat, len(xlsxBuiltinFormats)+len(styles.NumFmts))
+	for id, format := range xlsxBuiltinFormats {
+		formats[id] = format
+	}
+	for _, numFmt := range styles.NumFmts {
+		formats[numFmt.ID] = xlsxCustomFormat(numFmt.Code)
+	}
+	wb.formats = make([]xlsxDateFormat, len(styles.CellXfs))
+	for i, xf := range styles.CellXfs {
+		wb.formats[i] = formats[xf.NumFmtID]
+	}
+	return nil
+}
+
+// xlsxCustomFormat returns the date format of a custom number format code, like "dd/mm/yyyy hh:mm".
+// Only the first section of the code is considered, ignoring the literal text and the colors and
+// conditions in brackets.
+func xlsxCustomFormat(code string) xlsxDateFormat {
+	var hasDate, hasTime, hasMonth bool
+	quoted := false
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '\\' || c == '_' || c == '*':
+			i++
+		case c == '[':
+			end := strings.IndexByte(code[i:], ']')
+			if end < 0 {
+				return ""
+			}
+			if elapsed := strings.ToLower(code[i+1 : i+end]); strings.Trim(elapsed, "hms") == "" {
+				return ""
+			}
+			i += end
+		case c == ';':
+			i = len(code)
+		default:
+			switch c | 0x20 {
+			case 'y', 'd':
+				hasDate = true
+			case 'h', 's':
+				hasTime = true
+			case 'm':
+				hasMonth = true
+			}
+		}
+	}
+	// "m" is the minutes when used with hours or seconds, and the month otherwise
+	hasDate = hasDate || (hasMonth && !hasTime)
+	switch {
+	case hasDate && hasTime:
+		return xlsxDateTime
+	case hasDate:
+		return xlsxDate
+	case hasTime:
+		return xlsxTime
+	}
+	return ""
+}
+
+func (wb *xlsxWorkbook) hasSheet(name string) bool {
+	for _, sheet := range wb.sheets {
+		if sheet.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sheetDocuments returns each non-empty sheet as a Document.
+func (wb *xlsxWorkbook) sheetDocuments() recordReader {
+	next := 0
+	return func() (flowllm.Document, error) {
+		for next < len(wb.sheets) {
+			sheet := wb.sheets[next]
+			next++
+			var rows [][]string
+			err := wb.readRows(sheet, func(_ int, values []string) {
+				rows = append(rows, values)
+			})
+			if err != nil {
+				return flowllm.Document{}, err
+			}
+			if len(rows) == 0 {
+				continue
+			}
+			metadata := wb.z.coreProperties()
+			metadata["sheet"] = sheet.name
+			return flowllm.Document{PageContent: markdownTable(rows), Metadata: metadata}, nil
+		}
+		return flowllm.Document{}, io.EOF
+	}
+}
+
+// rowDocuments returns each non-empty row after the header as a Document. The rows of each sheet
+// are read all at once, when the first row of the sheet is requested.
+func (wb *xlsxWorkbook) rowDocuments() recordReader {
+	next := 0
+	var pending []flowllm.Document
+	return func() (flowllm.Document, error) {
+		for len(pending) == 0 {
+			if next >= len(wb.sheets) {
+				return flowllm.Document{}, io.EOF
+			}
+			sheet := wb.sheets[next]
+			next++
+			var header []string
+			err := wb.readRows(sheet, func(number int, values []string) {
+				if header == nil {
+					header = values
+					return
+				}
+				var content []string
+				for i, value := range values {
+					content = append(content, xlsxColumnName(header, i)+": "+value)
+				}
+				metadata := wb.z.coreProperties()
+				metadata["sheet"] = sheet.name
+				metadata["row"] = number
+				pending = append(pending, flowllm.Document{PageContent: strings.Join(content, "\n"), Metadata: metadata})
+			})
+			if err != nil {
+				return flowllm.Document{}, err
+			}
+		}
+		doc := pending[0]
+		pending = pending[1:]
+		return doc, nil
+	}
+}
+
+// xlsxColumnName returns the name of the column from the header, or its letter if the header is empty.
+func xlsxColumnName(header []string, i int) string {
+	if i < len(header) && strings.TrimSpace(header[i]) != "" {
+		return strings.TrimSpace(header[i])
+	}
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// readRows calls fn for each non-empty row of the sheet, with the row number (starting at 1) and
+// the values of its cells, placed in the position of their columns.
+func (wb *xlsxWorkbook) readRows(sheet xlsxSheet, fn func(number int, values []string)) error {
+	r, err := wb.z.open(sheet.part)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	d := xml.NewDecoder(r)
+	number := 0
+	for {
+		token, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: parsing sheet %q: %w", wb.z.path, sheet.name, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row xlsxRow
+		if err := d.DecodeElement(&row, &start); err != nil {
+			return fmt.Errorf("%s: parsing sheet %q: %w", wb.z.path, sheet.name, err)
+		}
+		number++
+		if row.Number > 0 {
+			number = row.Number
+		}
+
+		var values []string
+		for _, cell := range row.Cells {
+			column := len(values)
+			if i := xlsxColumnIndex(cell.Ref); i >= 0 {
+				column = i
+			}
+			for len(values) <= column {
+				values = append(values, "")
+			}
+			values[column] = wb.cellValue(cell.Type, cell.Style, cell.Value, cell.Inline)
+		}
+		for len(values) > 0 && strings.TrimSpace(values[len(values)-1]) == "" {
+			values = values[:len(values)-1]
+		}
+		if len(values) == 0 {
+			continue
+		}
+		fn(number, values)
+	}
+}
+
+func (wb *xlsxWorkbook) cellValue(typ string, style int, value string, inline xlsxText) string {
+	switch typ {
+	case "s":
+		i, err := strconv.Atoi(value)
+		if err != nil || i < 0 || i >= len(wb.strings) {
+			return ""
+		}
+		return wb.strings[i]
+	case "inlineStr":
+		return inline.String()
+	case "b":
+		if value == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	case "", "n":
+		if style >= 0 && style < len(wb.formats) && wb.formats[style] != "" {
+			return wb.formatDate(value, wb.formats[style])
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// formatDate converts a date serial number, the days since the epoch of the workbook, with the
+// time as the fraction, to the given format. Invalid numbers are returned as they are.
+func (wb *xlsxWorkbook) formatDate(value string, format xlsxDateFormat) string {
+	serial, err := strconv.ParseFloat(value, 64)
+	if err != nil || serial < 0 || serial > 2958465 { // 9999-12-31
+		return value
+	}
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 86400)
+	t := wb.epoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+	return t.Format(string(format))
+}
+
+// xlsxColumnIndex returns the index of the column of a cell reference, like "C12" (2).
+func xlsxColumnIndex(ref string) int {
+	column := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		column = column*26 + int(c-'A'+1)
+	}
+	return column - 1
+}
@@ -0,0 +1,113 @@
+package loaders_test
+
+import (
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("XLSX", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = writeZip("fruits.xlsx", map[string]string{
+			"docProps/core.xml": docxCoreProperties,
+			"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+				<sheets>
+					<sheet name="Fruits" sheetId="1" r:id="rId2"/>
+					<sheet name="Empty" sheetId="2" r:id="rId3"/>
+					<sheet name="Prices" sheetId="3" r:id="rId1"/>
+				</sheets>
+			</workbook>`,
+			"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+				<Relationship Id="rId1" Target="worksheets/sheet3.xml"/>
+				<Relationship Id="rId2" Target="worksheets/sheet1.xml"/>
+				<Relationship Id="rId3" Target="/xl/worksheets/sheet2.xml"/>
+			</Relationships>`,
+			"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+				<si><t>name</t></si><si><t>color</t></si><si><t>apple</t></si><si><r><t>re</t></r><r><t>d</t></r></si>
+			</sst>`,
+			"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+				<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>ripe</t></is></c></row>
+				<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2" t="s"><v>3</v></c><c r="C2" t="b"><v>1</v></c></row>
+				<row r="4"><c r="A4" t="inlineStr"><is><t>banana</t></is></c><c r="C4" t="b"><v>0</v></c><c r="D4"><v>12</v></c></row>
+			</sheetData></worksheet>`,
+			"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData/></worksheet>`,
+			"xl/worksheets/sheet3.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+				<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="inlineStr"><is><t>price</t></is></c></row>
+				<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>1.5</v></c></row>
+			</sheetData></worksheet>`,
+		})
+	})
+
+	It("loads each sheet as a Markdown table", func() {
+		docs, err := flowllm.LoadDocs(10, loaders.XLSX(path, loaders.XLSXOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(2))
+		Expect(docs[0].PageContent).To(Equal(strings.Join([]string{
+			"| name | color | ripe |  |",
+			"| --- | --- | --- | --- |",
+			"| apple | red | TRUE |  |",
+			"| banana |  | FALSE | 12 |",
+		}, "\n")))
+		Expect(docs[0].Metadata).To(Equal(map[string]any{"source": path, "sheet": "Fruits", "title": "Fruit Guide", "author": "Jane Doe"}))
+		Expect(docs[1].Metadata).To(HaveKeyWithValue("sheet", "Prices"))
+	})
+
+	It("loads each row as a document", func() {
+		docs, err := flowllm.LoadDocs(10, loaders.XLSX(path, loaders.XLSXOptions{RowPerDocument: true}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(3))
+		Expect(docs[0].PageContent).To(Equal("name: apple\ncolor: red\nripe: TRUE"))
+		Expect(docs[0].Metadata).To(HaveKeyWithValue("row", 2))
+		Expect(docs[1].PageContent).To(Equal("name: banana\ncolor: \nripe: FALSE\nD: 12"))
+		Expect(docs[1].Metadata).To(HaveKeyWithValue("row", 4))
+		Expect(docs[2].PageContent).To(Equal("name: apple\nprice: 1.5"))
+		Expect(docs[2].Metadata).To(HaveKeyWithValue("sheet", "Prices"))
+	})
+
+	It("renders the cells formatted as dates", func() {
+		path := writeZip("orders.xlsx", map[string]string{
+			"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+				<sheets><sheet name="Orders" sheetId="1" r:id="rId1"/></sheets>
+			</workbook>`,
+			"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+				<Relationship Id="rId1" Target="worksheets/sheet1.xml"/>
+			</Relationships>`,
+			"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+				<numFmts count="3">
+					<numFmt numFmtId="164" formatCode="dd/mm/yyyy\ hh:mm"/>
+					<numFmt numFmtId="165" formatCode="[Red]#,##0.00&quot; days&quot;"/>
+					<numFmt numFmtId="166" formatCode="[h]:mm"/>
+				</numFmts>
+				<cellXfs count="6">
+					<xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="164"/><xf numFmtId="20"/><xf numFmtId="165"/><xf numFmtId="166"/>
+				</cellXfs>
+			</styleSheet>`,
+			"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+				<row r="1"><c r="A1" s="1"><v>45292</v></c><c r="B1" s="2"><v>45292.75</v></c><c r="C1" s="3"><v>0.5</v></c></row>
+				<row r="2"><c r="A2" s="4"><v>45292</v></c><c r="B2" s="5"><v>1.5</v></c><c r="C2" s="1" t="inlineStr"><is><t>soon</t></is></c></row>
+			</sheetData></worksheet>`,
+		})
+		docs, err := flowllm.LoadDocs(10, loaders.XLSX(path, loaders.XLSXOptions{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs[0].PageContent).To(Equal(strings.Join([]string{
+			"| 2024-01-01 | 2024-01-01 18:00:00 | 12:00:00 |",
+			"| --- | --- | --- |",
+			"| 45292 | 1.5 | soon |",
+		}, "\n")))
+	})
+
+	It("loads only the selected sheets", func() {
+		docs, err := flowllm.LoadDocs(10, loaders.XLSX(path, loaders.XLSXOptions{Sheets: []string{"Prices"}}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(docs).To(HaveLen(1))
+		Expect(docs[0].Metadata).To(HaveKeyWithValue("sheet", "Prices"))
+
+		_, err = flowllm.LoadDocs(10, loaders.XLSX(path, loaders.XLSXOptions{Sheets: []string{"Vegetables"}}))
+		Expect(err).To(MatchError(ContainSubstring(`sheet "Vegetables" not found`)))
+	})
+})
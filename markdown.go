@@ -0,0 +1,198 @@
+package flowllm
+
+import (
+	"strings"
+)
+
+// MarkdownSection is a section of a Markdown document, started by a heading.
+type MarkdownSection struct {
+	// Headings are the titles of the headings the section is nested under, from the top level
+	// down to the heading of the section itself. It is empty for the text before the first heading
+	Headings []string
+	// Content of the section, including its heading
+	Content string
+	// Body is the content of the section without its heading
+	Body string
+}
+
+// MarkdownSections splits a Markdown document into sections at its headings, both ATX
+// ("## Title") and setext (a paragraph underlined with "===" or "---") headings. Lines inside
+// fenced code blocks are never considered headings. The text before the first heading, if any,
+// is returned as a section without headings.
+func MarkdownSections(text string) []MarkdownSection {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var sections []MarkdownSection
+	var path [6]string
+	var headings []string
+	start, bodyStart := 0, 0
+	flush := func(end int) {
+		content := strings.Trim(strings.Join(lines[start:end], "\n"), "\n")
+		if strings.TrimSpace(content) == "" {
+			return
+		}
+		body := ""
+		if bodyStart < end {
+			body = strings.Trim(strings.Join(lines[bodyStart:end], "\n"), "\n")
+		}
+		sections = append(sections, MarkdownSection{Headings: headings, Content: content, Body: body})
+	}
+	newSection := func(level int, title string, headingStart, headingEnd int) {
+		flush(headingStart)
+		path[level-1] = title
+		for i := level; i < len(path); i++ {
+			path[i] = ""
+		}
+		headings = nil
+		for _, h := range path[:level] {
+			if h != "" {
+				headings = append(headings, h)
+			}
+		}
+		start, bodyStart = headingStart, headingEnd
+	}
+
+	var fence string
+	paragraphStart := -1
+	for i, line := range lines {
+		if fence != "" {
+			if isClosingFence(line, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if f := openingFence(line); f != "" {
+			fence = f
+			paragraphStart = -1
+			continue
+		}
+		if level, title := atxHeading(line); level > 0 {
+			newSection(level, title, i, i+1)
+			paragraphStart = -1
+			continue
+		}
+		if level := setextUnderline(line); level > 0 {
+			// Without a paragraph above it, the line is a horizontal rule or plain text
+			if paragraphStart >= 0 {
+				var titleLines []string
+				for _, l := range lines[paragraphStart:i] {
+					titleLines = append(titleLines, strings.TrimSpace(l))
+				}
+				newSection(level, strings.Join(titleLines, " "), paragraphStart, i+1)
+			}
+			paragraphStart = -1
+			continue
+		}
+		switch {
+		case strings.TrimSpace(line) == "":
+			paragraphStart = -1
+		case paragraphStart < 0 && !strings.HasPrefix(line, "    ") && !strings.HasPrefix(line, "\t"):
+			paragraphStart = i
+		}
+	}
+	flush(len(lines))
+	return sections
+}
+
+// markdownBlocks splits the text at its blank lines, keeping fenced code blocks whole.
+func markdownBlocks(text string) []string {
+	var blocks []string
+	var current []string
+	var fence string
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case fence != "":
+			if isClosingFence(line, fence) {
+				fence = ""
+			}
+		case openingFence(line) != "":
+			fence = openingFence(line)
+		case strings.TrimSpace(line) == "":
+			if len(current) > 0 {
+				blocks = append(blocks, strings.Join(current, "\n"))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, strings.Join(current, "\n"))
+	}
+	return blocks
+}
+
+// trimIndent removes up to 3 spaces of indentation, returning false if the line is indented more
+// than that, which makes it an indented code block.
+func trimIndent(line string) (string, bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || strings.HasPrefix(trimmed, "\t") {
+		return line, false
+	}
+	return trimmed, true
+}
+
+// openingFence returns the fence that opens a fenced code block (3 or more backticks or tildes),
+// or "" if the line doesn't open one.
+func openingFence(line string) string {
+	line, ok := trimIndent(line)
+	if !ok || len(line) < 3 || (line[0] != '`' && line[0] != '~') {
+		return ""
+	}
+	fence := line[:len(line)-len(strings.TrimLeft(line, line[:1]))]
+	if len(fence) < 3 || (fence[0] == '`' && strings.Contains(line[len(fence):], "`")) {
+		return ""
+	}
+	return fence
+}
+
+func isClosingFence(line, fence string) bool {
+	line, ok := trimIndent(line)
+	if !ok {
+		return false
+	}
+	line = strings.TrimRight(line, " \t")
+	return len(line) >= len(fence) && strings.Trim(line, fence[:1]) == ""
+}
+
+// atxHeading returns the level and title of an ATX heading ("## Title"), or level 0 if the line
+// is not a heading.
+func atxHeading(line string) (int, string) {
+	line, ok := trimIndent(line)
+	if !ok {
+		return 0, ""
+	}
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level < 1 || level > 6 {
+		return 0, ""
+	}
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, ""
+	}
+	title := strings.TrimSpace(rest)
+	// Remove the optional closing sequence of #s
+	if trimmed := strings.TrimRight(title, "#"); trimmed == "" || strings.HasSuffix(trimmed, " ") {
+		title = strings.TrimSpace(trimmed)
+	}
+	return level, title
+}
+
+// setextUnderline returns the level of the heading underlined by the line, 1 for "===" and 2 for
+// "---", or 0 if the line is not a setext underline.
+func setextUnderline(line string) int {
+	line, ok := trimIndent(line)
+	if !ok {
+		return 0
+	}
+	line = strings.TrimRight(line, " \t")
+	switch {
+	case line == "":
+		return 0
+	case strings.Trim(line, "=") == "":
+		return 1
+	case strings.Trim(line, "-") == "":
+		return 2
+	}
+	return 0
+}
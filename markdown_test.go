@@ -0,0 +1,46 @@
+package flowllm_test
+
+import (
+	. "github.com/deluan/flowllm"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MarkdownSections", func() {
+	It("splits the document at its headings, with the path of headings of each section", func() {
+		sections := MarkdownSections(`Preamble.
+
+# Guide
+
+Intro.
+
+Install
+-------
+
+Run the installer.
+
+### Linux ###
+
+` + "```sh\n# comment, not a heading\nmake install\n```" + `
+
+## Usage
+
+---
+
+Use it.
+
+Multi-line
+title
+=========
+
+The end.`)
+		Expect(sections).To(Equal([]MarkdownSection{
+			{Content: "Preamble.", Body: "Preamble."},
+			{Headings: []string{"Guide"}, Content: "# Guide\n\nIntro.", Body: "Intro."},
+			{Headings: []string{"Guide", "Install"}, Content: "Install\n-------\n\nRun the installer.", Body: "Run the installer."},
+			{Headings: []string{"Guide", "Install", "Linux"}, Content: "### Linux ###\n\n```sh\n# comment, not a heading\nmake install\n```", Body: "```sh\n# comment, not a heading\nmake install\n```"},
+			{Headings: []string{"Guide", "Usage"}, Content: "## Usage\n\n---\n\nUse it.", Body: "---\n\nUse it."},
+			{Headings: []string{"Multi-line title"}, Content: "Multi-line\ntitle\n=========\n\nThe end.", Body: "The end."},
+		}))
+	})
+})
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/deluan/flowllm"
+	"go.etcd.io/bbolt"
+)
+
+// BoltChatHistory is a ChatMessageHistory persisted in a BoltDB database. Each session is kept in
+// its own nested bucket, inside the bucket given to NewBoltChatHistory.
+type BoltChatHistory struct {
+	db     *bbolt.DB
+	bucket string
+}
+
+// NewBoltChatHistory creates a ChatMessageHistory persisted in the given bucket of a BoltDB
+// database, creating the bucket if it does not exist. The database is not closed by the history.
+func NewBoltChatHistory(db *bbolt.DB, bucket string) (*BoltChatHistory, error) {
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return fmt.Errorf("create bucket: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &BoltChatHistory{db: db, bucket: bucket}, nil
+}
+
+func (h *BoltChatHistory) Messages(_ context.Context, sessionID string) (flowllm.ChatMessages, error) {
+	messages := flowllm.ChatMessages{}
+	err := h.db.View(func(tx *bbolt.Tx) error {
+		session := tx.Bucket([]byte(h.bucket)).Bucket([]byte(sessionID))
+		if session == nil {
+			return nil
+		}
+		return session.ForEach(func(_, v []byte) error {
+			var msg flowllm.ChatMessage
+			if err := json.Unmarshal(v, &msg); err != nil {
+				return err
+			}
+			messages = append(messages, msg)
+			return nil
+		})
+	})
+	return messages, err
+}
+
+func (h *BoltChatHistory) AddMessages(_ context.Context, sessionID string, messages ...flowllm.ChatMessage) error {
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		return h.putMessages(tx, sessionID, messages)
+	})
+}
+
+// ReplaceMessages replaces the messages of the session in a single transaction.
+func (h *BoltChatHistory) ReplaceMessages(_ context.Context, sessionID string, messages ...flowllm.ChatMessage) error {
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		err := tx.Bucket([]byte(h.bucket)).DeleteBucket([]byte(sessionID))
+		if err != nil && !errors.Is(err, bbolt.ErrBucketNotFound) {
+			return err
+		}
+		return h.putMessages(tx, sessionID, messages)
+	})
+}
+
+// putMessages appends the messages to the session bucket, creating it if it does not exist.
+func (h *BoltChatHistory) putMessages(tx *bbolt.Tx, sessionID string, messages []flowllm.ChatMessage) error {
+	session, err := tx.Bucket([]byte(h.bucket)).CreateBucketIfNotExists([]byte(sessionID))
+	if err != nil {
+		return fmt.Errorf("create session bucket: %w", err)
+	}
+	for _, msg := range messages {
+		seq, err := session.NextSequence()
+		if err != nil {
+			return err
+		}
+		buf, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		// Keys are big endian sequence numbers, so messages are iterated in insertion order
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		if err := session.Put(key, buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *BoltChatHistory) Clear(_ context.Context, sessionID string) error {
+	return h.db.Update(func(tx *bbolt.Tx) error {
+		err := tx.Bucket([]byte(h.bucket)).DeleteBucket([]byte(sessionID))
+		if errors.Is(err, bbolt.ErrBucketNotFound) {
+			return nil
+		}
+		return err
+	})
+}
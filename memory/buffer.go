@@ -6,29 +6,40 @@ import (
 	"github.com/deluan/flowllm"
 )
 
+// Buffer is a memory that keeps the last windowSize question/answer pairs (windowSize*2 messages)
+// of each session.
+// The session is taken from the context, see flowllm.WithSessionID.
 type Buffer struct {
-	chatHistory *ChatMessageHistory
+	chatHistory ChatMessageHistory
 	windowSize  int
 }
 
+// NewBuffer creates a new Buffer memory, keeping the messages in memory. If history is not nil,
+// it is used as the initial conversation of the DefaultSessionID session.
 func NewBuffer(windowSize int, history *flowllm.ChatMessages) *Buffer {
-	chatHistory := &ChatMessageHistory{}
+	chatHistory := NewInMemoryChatHistory()
 	if history != nil {
-		chatHistory.messages = *history
+		_ = chatHistory.AddMessages(context.Background(), DefaultSessionID, *history...)
 	}
+	return NewBufferWithHistory(windowSize, chatHistory)
+}
+
+// NewBufferWithHistory creates a new Buffer memory, keeping the messages in the given ChatMessageHistory.
+func NewBufferWithHistory(windowSize int, chatHistory ChatMessageHistory) *Buffer {
 	return &Buffer{windowSize: windowSize, chatHistory: chatHistory}
 }
 
-func (b *Buffer) Load(_ context.Context) (flowllm.ChatMessages, error) {
-	messages := b.chatHistory.GetMessages()
+func (b *Buffer) Load(ctx context.Context, _ string) (flowllm.ChatMessages, error) {
+	messages, err := b.chatHistory.Messages(ctx, sessionID(ctx))
+	if err != nil {
+		return nil, err
+	}
 	if b.windowSize > 0 {
 		messages = messages.Last(b.windowSize * 2)
 	}
 	return messages, nil
 }
 
-func (b *Buffer) Save(_ context.Context, input, output string) error {
-	b.chatHistory.AddUserMessage(input)
-	b.chatHistory.AddAssistantMessage(output)
-	return nil
+func (b *Buffer) Save(ctx context.Context, messages flowllm.ChatMessages) error {
+	return b.chatHistory.AddMessages(ctx, sessionID(ctx), messages...)
 }
@@ -22,10 +22,10 @@ var _ = Describe("Buffer", func() {
 		buf = memory.NewBuffer(0, nil)
 		input := "User input message"
 		output := "Assistant output message"
-		err := buf.Save(ctx, input, output)
+		err := buf.Save(ctx, exchange(input, output))
 		Expect(err).NotTo(HaveOccurred())
 
-		messages, err := buf.Load(ctx)
+		messages, err := buf.Load(ctx, "")
 		Expect(err).NotTo(HaveOccurred())
 		Expect(messages).To(HaveLen(2))
 		Expect(messages[0]).To(Equal(flowllm.ChatMessage{Content: "User input message", Role: "user"}))
@@ -38,10 +38,10 @@ var _ = Describe("Buffer", func() {
 			{Content: "Assistant output message 0", Role: "assistant"},
 		}
 		buf = memory.NewBuffer(0, &msgs)
-		err := buf.Save(ctx, "User input message 1", "Assistant output message 1")
+		err := buf.Save(ctx, exchange("User input message 1", "Assistant output message 1"))
 		Expect(err).NotTo(HaveOccurred())
 
-		messages, err := buf.Load(ctx)
+		messages, err := buf.Load(ctx, "")
 		Expect(err).NotTo(HaveOccurred())
 		Expect(messages).To(HaveLen(4))
 		Expect(messages[0]).To(Equal(flowllm.ChatMessage{Content: "User input message 0", Role: "user"}))
@@ -53,11 +53,11 @@ var _ = Describe("Buffer", func() {
 	It("truncates history with windowSize", func() {
 		buf = memory.NewBuffer(2, nil)
 		for i := 1; i <= 3; i++ {
-			err := buf.Save(ctx, "User message "+strconv.Itoa(i), "Assistant message "+strconv.Itoa(i))
+			err := buf.Save(ctx, exchange("User message "+strconv.Itoa(i), "Assistant message "+strconv.Itoa(i)))
 			Expect(err).NotTo(HaveOccurred())
 		}
 
-		messages, err := buf.Load(ctx)
+		messages, err := buf.Load(ctx, "")
 		Expect(err).NotTo(HaveOccurred())
 		Expect(messages).To(HaveLen(4))
 		Expect(messages[0].Content).To(Equal("User message 2"))
@@ -65,4 +65,47 @@ var _ = Describe("Buffer", func() {
 		Expect(messages[2].Content).To(Equal("User message 3"))
 		Expect(messages[3].Content).To(Equal("Assistant message 3"))
 	})
+
+	It("keeps the conversations of each session apart", func() {
+		history, err := memory.NewJSONLChatHistory(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		buf = memory.NewBufferWithHistory(0, history)
+
+		aliceCtx := flowllm.WithSessionID(ctx, "alice")
+		bobCtx := flowllm.WithSessionID(ctx, "bob")
+		Expect(buf.Save(aliceCtx, exchange("I'm Alice", "Hi Alice"))).To(Succeed())
+		Expect(buf.Save(bobCtx, exchange("I'm Bob", "Hi Bob"))).To(Succeed())
+
+		messages, err := buf.Load(aliceCtx, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(messages).To(Equal(flowllm.ChatMessages{
+			{Content: "I'm Alice", Role: "user"},
+			{Content: "Hi Alice", Role: "assistant"},
+		}))
+
+		messages, err = buf.Load(bobCtx, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(messages).To(HaveLen(2))
+		Expect(messages[0].Content).To(Equal("I'm Bob"))
+
+		messages, err = buf.Load(ctx, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(messages).To(BeEmpty())
+	})
+
+	It("serves multiple sessions in a chain, using the session from the values", func() {
+		buf = memory.NewBuffer(0, nil)
+		chain := flowllm.WithMemory(buf, flowllm.HandlerFunc(func(_ context.Context, values ...flowllm.Values) (flowllm.Values, error) {
+			history := values[0][flowllm.DefaultChatKey].(flowllm.ChatMessages)
+			return flowllm.Values{flowllm.DefaultKey: strconv.Itoa(len(history))}, nil
+		}))
+
+		for i := 0; i < 2; i++ {
+			for _, session := range []string{"alice", "bob"} {
+				res, err := chain.Call(ctx, flowllm.Values{"input": "hello", flowllm.DefaultSessionKey: session})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.Get(flowllm.DefaultKey)).To(Equal(strconv.Itoa(i * 2)))
+			}
+		}
+	})
 })
@@ -1,27 +1,73 @@
 package memory
 
 import (
+	"context"
+	"sync"
+
 	"github.com/deluan/flowllm"
 )
 
-type ChatMessageHistory struct {
-	messages []flowllm.ChatMessage
+// DefaultSessionID is the session used by the memories when the context does not carry one.
+const DefaultSessionID = "default"
+
+// ChatMessageHistory stores the messages of multiple conversations, keyed by session ID.
+// Implementations must be safe for concurrent use.
+type ChatMessageHistory interface {
+	// Messages returns a copy of all messages of the session, in the order they were added
+	Messages(ctx context.Context, sessionID string) (flowllm.ChatMessages, error)
+	// AddMessages appends the messages to the session
+	AddMessages(ctx context.Context, sessionID string, messages ...flowllm.ChatMessage) error
+	// Clear removes all messages of the session
+	Clear(ctx context.Context, sessionID string) error
+	// ReplaceMessages replaces all messages of the session with the given ones, atomically: if it
+	// fails, the session keeps its previous messages
+	ReplaceMessages(ctx context.Context, sessionID string, messages ...flowllm.ChatMessage) error
+}
+
+// sessionID returns the session ID carried by the context, or DefaultSessionID if there is none.
+func sessionID(ctx context.Context) string {
+	if id := flowllm.SessionID(ctx); id != "" {
+		return id
+	}
+	return DefaultSessionID
+}
+
+// InMemoryChatHistory is a ChatMessageHistory that keeps the messages in memory.
+type InMemoryChatHistory struct {
+	mu       sync.RWMutex
+	sessions map[string]flowllm.ChatMessages
+}
+
+// NewInMemoryChatHistory creates a new empty InMemoryChatHistory.
+func NewInMemoryChatHistory() *InMemoryChatHistory {
+	return &InMemoryChatHistory{sessions: map[string]flowllm.ChatMessages{}}
 }
 
-func (h *ChatMessageHistory) GetMessages() flowllm.ChatMessages {
-	copyMessages := make(flowllm.ChatMessages, len(h.messages))
-	copy(copyMessages, h.messages)
-	return copyMessages
+func (h *InMemoryChatHistory) Messages(_ context.Context, sessionID string) (flowllm.ChatMessages, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	messages := make(flowllm.ChatMessages, len(h.sessions[sessionID]))
+	copy(messages, h.sessions[sessionID])
+	return messages, nil
 }
 
-func (h *ChatMessageHistory) AddUserMessage(message string) {
-	h.messages = append(h.messages, flowllm.ChatMessage{Content: message, Role: "user"})
+func (h *InMemoryChatHistory) AddMessages(_ context.Context, sessionID string, messages ...flowllm.ChatMessage) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sessions[sessionID] = append(h.sessions[sessionID], messages...)
+	return nil
 }
 
-func (h *ChatMessageHistory) AddAssistantMessage(message string) {
-	h.messages = append(h.messages, flowllm.ChatMessage{Content: message, Role: "assistant"})
+func (h *InMemoryChatHistory) ReplaceMessages(_ context.Context, sessionID string, messages ...flowllm.ChatMessage) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sessions[sessionID] = append(flowllm.ChatMessages(nil), messages...)
+	return nil
 }
 
-func (h *ChatMessageHistory) Clear() {
-	h.messages = flowllm.ChatMessages{}
+func (h *InMemoryChatHistory) Clear(_ context.Context, sessionID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.sessions, sessionID)
+	return nil
 }
@@ -1,67 +1,157 @@
 package memory_test
 
 import (
+	"context"
+	"path/filepath"
+
 	"github.com/deluan/flowllm"
 	"github.com/deluan/flowllm/memory"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"go.etcd.io/bbolt"
 )
 
 var _ = Describe("ChatMessageHistory", func() {
-	var history *memory.ChatMessageHistory
+	implementations := []struct {
+		name       string
+		newHistory func() memory.ChatMessageHistory
+	}{
+		{"InMemoryChatHistory", func() memory.ChatMessageHistory {
+			return memory.NewInMemoryChatHistory()
+		}},
+		{"BoltChatHistory", func() memory.ChatMessageHistory {
+			db, err := bbolt.Open(filepath.Join(GinkgoT().TempDir(), "history.db"), 0600, nil)
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(db.Close)
+			history, err := memory.NewBoltChatHistory(db, "history")
+			Expect(err).ToNot(HaveOccurred())
+			return history
+		}},
+		{"JSONLChatHistory", func() memory.ChatMessageHistory {
+			history, err := memory.NewJSONLChatHistory(filepath.Join(GinkgoT().TempDir(), "history"))
+			Expect(err).ToNot(HaveOccurred())
+			return history
+		}},
+	}
 
-	BeforeEach(func() {
-		history = &memory.ChatMessageHistory{}
-	})
+	for _, impl := range implementations {
+		newHistory := impl.newHistory
+		Describe(impl.name, func() {
+			var (
+				ctx     context.Context
+				history memory.ChatMessageHistory
+			)
 
-	Context("GetMessages", func() {
-		It("returns an empty slice when there are no messages", func() {
-			Expect(history.GetMessages()).To(BeEmpty())
-		})
+			BeforeEach(func() {
+				ctx = context.Background()
+				history = newHistory()
+			})
 
-		It("returns a copy of messages in the history", func() {
-			history.AddUserMessage("Test user message")
-			history.AddAssistantMessage("Test assistant message")
+			Context("Messages", func() {
+				It("returns an empty slice when there are no messages", func() {
+					messages, err := history.Messages(ctx, "session")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(messages).To(BeEmpty())
+				})
 
-			messages := history.GetMessages()
-			Expect(messages).To(HaveLen(2))
-			Expect(messages[0]).To(Equal(flowllm.ChatMessage{Content: "Test user message", Role: "user"}))
-			Expect(messages[1]).To(Equal(flowllm.ChatMessage{Content: "Test assistant message", Role: "assistant"}))
+				It("returns a copy of messages in the history", func() {
+					Expect(history.AddMessages(ctx, "session",
+						flowllm.ChatMessage{Content: "Test user message", Role: "user"},
+						flowllm.ChatMessage{Content: "Test assistant message", Role: "assistant"},
+					)).To(Succeed())
 
-			// Modify the messages slice and ensure it doesn't affect the original history
-			messages[0].Content = "Modified message"
-			Expect(history.GetMessages()[0]).To(Equal(flowllm.ChatMessage{Content: "Test user message", Role: "user"}))
-		})
-	})
+					messages, err := history.Messages(ctx, "session")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(messages).To(HaveLen(2))
+					Expect(messages[0]).To(Equal(flowllm.ChatMessage{Content: "Test user message", Role: "user"}))
+					Expect(messages[1]).To(Equal(flowllm.ChatMessage{Content: "Test assistant message", Role: "assistant"}))
 
-	Context("AddUserMessage", func() {
-		It("adds a user message to the history", func() {
-			history.AddUserMessage("Test user message")
-			messages := history.GetMessages()
+					// Modify the messages slice and ensure it doesn't affect the original history
+					messages[0].Content = "Modified message"
+					messages, err = history.Messages(ctx, "session")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(messages[0]).To(Equal(flowllm.ChatMessage{Content: "Test user message", Role: "user"}))
+				})
+			})
 
-			Expect(messages).To(HaveLen(1))
-			Expect(messages[0]).To(Equal(flowllm.ChatMessage{Content: "Test user message", Role: "user"}))
-		})
-	})
+			Context("AddMessages", func() {
+				It("appends the messages to the session, keeping the order", func() {
+					for _, content := range []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"} {
+						Expect(history.AddMessages(ctx, "session", flowllm.ChatMessage{Content: content, Role: "user"})).To(Succeed())
+					}
+					messages, err := history.Messages(ctx, "session")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(messages).To(HaveLen(10))
+					Expect(messages[0].Content).To(Equal("1"))
+					Expect(messages[9].Content).To(Equal("10"))
+				})
 
-	Context("AddAssistantMessage", func() {
-		It("adds an assistant message to the history", func() {
-			history.AddAssistantMessage("Test assistant message")
-			messages := history.GetMessages()
+				It("keeps the sessions apart", func() {
+					Expect(history.AddMessages(ctx, "alice", flowllm.ChatMessage{Content: "Hi, I'm Alice", Role: "user"})).To(Succeed())
+					Expect(history.AddMessages(ctx, "bob/../bob", flowllm.ChatMessage{Content: "Hi, I'm Bob", Role: "user"})).To(Succeed())
 
-			Expect(messages).To(HaveLen(1))
-			Expect(messages[0]).To(Equal(flowllm.ChatMessage{Content: "Test assistant message", Role: "assistant"}))
-		})
-	})
+					messages, err := history.Messages(ctx, "alice")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(messages).To(Equal(flowllm.ChatMessages{{Content: "Hi, I'm Alice", Role: "user"}}))
+
+					messages, err = history.Messages(ctx, "bob/../bob")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(messages).To(Equal(flowllm.ChatMessages{{Content: "Hi, I'm Bob", Role: "user"}}))
+				})
+			})
+
+			Context("ReplaceMessages", func() {
+				It("replaces the messages of the session", func() {
+					Expect(history.AddMessages(ctx, "session",
+						flowllm.ChatMessage{Content: "Test user message", Role: "user"},
+						flowllm.ChatMessage{Content: "Test assistant message", Role: "assistant"},
+					)).To(Succeed())
+					Expect(history.AddMessages(ctx, "other", flowllm.ChatMessage{Content: "Other message", Role: "user"})).To(Succeed())
+
+					Expect(history.ReplaceMessages(ctx, "session", flowllm.ChatMessage{Content: "Summary", Role: "summary"})).To(Succeed())
+					Expect(history.AddMessages(ctx, "session", flowllm.ChatMessage{Content: "New message", Role: "user"})).To(Succeed())
+					messages, err := history.Messages(ctx, "session")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(messages).To(Equal(flowllm.ChatMessages{
+						{Content: "Summary", Role: "summary"},
+						{Content: "New message", Role: "user"},
+					}))
+
+					messages, err = history.Messages(ctx, "other")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(messages).To(HaveLen(1))
+				})
+
+				It("creates the session when it does not exist", func() {
+					Expect(history.ReplaceMessages(ctx, "new", flowllm.ChatMessage{Content: "Summary", Role: "summary"})).To(Succeed())
+					messages, err := history.Messages(ctx, "new")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(messages).To(HaveLen(1))
+				})
+			})
+
+			Context("Clear", func() {
+				It("clears the history of the session", func() {
+					Expect(history.AddMessages(ctx, "session",
+						flowllm.ChatMessage{Content: "Test user message", Role: "user"},
+						flowllm.ChatMessage{Content: "Test assistant message", Role: "assistant"},
+					)).To(Succeed())
+					Expect(history.AddMessages(ctx, "other", flowllm.ChatMessage{Content: "Other message", Role: "user"})).To(Succeed())
+
+					Expect(history.Clear(ctx, "session")).To(Succeed())
+					messages, err := history.Messages(ctx, "session")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(messages).To(BeEmpty())
 
-	Context("Clear", func() {
-		It("clears the history", func() {
-			history.AddUserMessage("Test user message")
-			history.AddAssistantMessage("Test assistant message")
-			Expect(history.GetMessages()).To(HaveLen(2))
+					messages, err = history.Messages(ctx, "other")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(messages).To(HaveLen(1))
+				})
 
-			history.Clear()
-			Expect(history.GetMessages()).To(BeEmpty())
+				It("does nothing when the session does not exist", func() {
+					Expect(history.Clear(ctx, "missing")).To(Succeed())
+				})
+			})
 		})
-	})
+	}
 })
@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/deluan/flowllm"
+)
+
+const (
+	// DefaultEntityExtractionPrompt is the default prompt used to extract the entities mentioned in
+	// the conversation. It has access to the variables {history} and {input}.
+	DefaultEntityExtractionPrompt = flowllm.Template(`You are reading the transcript of a conversation between a user and an assistant.
+Extract all proper nouns (people, places, organizations, products and other named things) mentioned in the last lines of the conversation.
+Use the conversation history only to resolve references, do not extract entities mentioned only in the history.
+Answer with a comma-separated list of the entities, or NONE if there are none.
+
+EXAMPLE
+Conversation history:
+user: How's it going today?
+assistant: Great! How about you?
+
+Last lines:
+user: I'm working on the FlowLLM project with Alice.
+
+Output: FlowLLM, Alice
+END OF EXAMPLE
+
+Conversation history:
+{history}
+
+Last lines:
+{input}
+
+Output:`)
+
+	// DefaultEntitySummaryPrompt is the default prompt used to update the summary of an entity. It
+	// has access to the variables {entity}, {summary}, {history} and {input}.
+	DefaultEntitySummaryPrompt = flowllm.Template(`You are keeping notes about the entities mentioned in a conversation between a user and an assistant.
+Update the summary of the entity "{entity}" with the facts about it learned in the last lines of the conversation.
+Write the summary in the third person, keeping all relevant facts from the existing summary.
+If there is nothing new to add, return the existing summary unchanged.
+
+Conversation history:
+{history}
+
+Existing summary of {entity}:
+{summary}
+
+Last lines:
+{input}
+
+Updated summary:`)
+
+	defaultEntityWindowSize = 3
+	noEntities              = "NONE"
+)
+
+// EntityOptions for the Entity memory
+type EntityOptions struct {
+	// ExtractionPrompt is used to extract the entities from the conversation. Defaults to
+	// DefaultEntityExtractionPrompt
+	ExtractionPrompt flowllm.Template
+	// SummaryPrompt is used to update the summary of each entity. Defaults to DefaultEntitySummaryPrompt
+	SummaryPrompt flowllm.Template
+	// WindowSize is the number of recent question/answer pairs returned by Load and used as context
+	// by the prompts. Defaults to 3
+	WindowSize int
+	// Store is where the summaries of the entities are kept. Defaults to an InMemoryEntityStore
+	Store EntityStore
+	// ChatHistory is where the messages are kept. Defaults to an InMemoryChatHistory
+	ChatHistory ChatMessageHistory
+}
+
+// Entity is a memory that keeps track of facts about the entities (people, places, things)
+// mentioned in the conversation. Every time an exchange is saved, a LanguageModel
+// extracts the entities mentioned in it and updates their summaries in an EntityStore. Load returns
+// the summaries of the entities mentioned in the input as a system message, followed by the most
+// recent messages. The session is taken from the context, see flowllm.WithSessionID.
+type Entity struct {
+	model flowllm.LanguageModel
+	opts  EntityOptions
+}
+
+// NewEntity creates a new Entity memory, using the given model to extract and summarize the entities.
+func NewEntity(model flowllm.LanguageModel, opts EntityOptions) *Entity {
+	if opts.ExtractionPrompt == "" {
+		opts.ExtractionPrompt = DefaultEntityExtractionPrompt
+	}
+	if opts.SummaryPrompt == "" {
+		opts.SummaryPrompt = DefaultEntitySummaryPrompt
+	}
+	if opts.WindowSize == 0 {
+		opts.WindowSize = defaultEntityWindowSize
+	}
+	if opts.Store == nil {
+		opts.Store = NewInMemoryEntityStore()
+	}
+	if opts.ChatHistory == nil {
+		opts.ChatHistory = NewInMemoryChatHistory()
+	}
+	return &Entity{model: model, opts: opts}
+}
+
+// Load returns the summaries of the entities mentioned in the input as a system message, followed
+// by the most recent messages of the conversation.
+func (e *Entity) Load(ctx context.Context, input string) (flowllm.ChatMessages, error) {
+	messages, err := e.recentMessages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if input == "" {
+		return messages, nil
+	}
+	entities, err := e.extract(ctx, messages, flowllm.ChatMessages{{Role: "user", Content: input}})
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := e.opts.Store.Get(ctx, sessionID(ctx), entities...)
+	if err != nil {
+		return nil, err
+	}
+
+	var facts []string
+	for _, entity := range entities {
+		if summary, ok := summaries[entity]; ok {
+			facts = append(facts, entity+": "+summary)
+		}
+	}
+	if len(facts) == 0 {
+		return messages, nil
+	}
+	return summaryMessages("Context about the entities in the conversation:\n"+strings.Join(facts, "\n"), messages), nil
+}
+
+// Save updates the summaries of the entities mentioned in the messages of the last exchange, and
+// adds the messages to the conversation.
+func (e *Entity) Save(ctx context.Context, newLines flowllm.ChatMessages) error {
+	history, err := e.recentMessages(ctx)
+	if err != nil {
+		return err
+	}
+	entities, err := e.extract(ctx, history, newLines)
+	if err != nil {
+		return err
+	}
+	summaries, err := e.opts.Store.Get(ctx, sessionID(ctx), entities...)
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		summary, err := e.call(ctx, e.opts.SummaryPrompt, flowllm.Values{
+			"entity":  entity,
+			"summary": summaries[entity],
+			"history": history.String(),
+			"input":   newLines.String(),
+		})
+		if err != nil {
+			return err
+		}
+		if summary == "" {
+			continue
+		}
+		if err := e.opts.Store.Set(ctx, sessionID(ctx), entity, summary); err != nil {
+			return err
+		}
+	}
+	return e.opts.ChatHistory.AddMessages(ctx, sessionID(ctx), newLines...)
+}
+
+func (e *Entity) recentMessages(ctx context.Context) (flowllm.ChatMessages, error) {
+	messages, err := e.opts.ChatHistory.Messages(ctx, sessionID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return messages.Last(e.opts.WindowSize * 2), nil
+}
+
+// extract returns the entities mentioned in the new lines, in the order returned by the model,
+// without duplicates.
+func (e *Entity) extract(ctx context.Context, history, newLines flowllm.ChatMessages) ([]string, error) {
+	answer, err := e.call(ctx, e.opts.ExtractionPrompt, flowllm.Values{
+		"history": history.String(),
+		"input":   newLines.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var entities []string
+	seen := map[string]bool{}
+	for _, entity := range strings.Split(answer, ",") {
+		entity = strings.TrimSpace(entity)
+		if entity == "" || strings.EqualFold(entity, noEntities) || seen[entity] {
+			continue
+		}
+		seen[entity] = true
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
+
+func (e *Entity) call(ctx context.Context, prompt flowllm.Template, values flowllm.Values) (string, error) {
+	vals, err := prompt.Call(ctx, values)
+	if err != nil {
+		return "", err
+	}
+	answer, err := e.model.Call(ctx, vals.Get(flowllm.DefaultKey))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
+}
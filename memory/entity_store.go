@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.etcd.io/bbolt"
+)
+
+// EntityStore keeps the summaries of the entities mentioned in multiple conversations, keyed by
+// session ID and entity name. Implementations must be safe for concurrent use.
+type EntityStore interface {
+	// Get returns the summaries of the given entities in the session. Entities without a summary
+	// are not included in the result
+	Get(ctx context.Context, sessionID string, entities ...string) (map[string]string, error)
+	// Set stores the summary of the entity in the session, replacing the previous one
+	Set(ctx context.Context, sessionID string, entity string, summary string) error
+	// Delete removes the summary of the entity from the session
+	Delete(ctx context.Context, sessionID string, entity string) error
+	// Clear removes all entities of the session
+	Clear(ctx context.Context, sessionID string) error
+}
+
+// InMemoryEntityStore is an EntityStore that keeps the summaries in memory.
+type InMemoryEntityStore struct {
+	mu       sync.RWMutex
+	sessions map[string]map[string]string
+}
+
+// NewInMemoryEntityStore creates a new empty InMemoryEntityStore.
+func NewInMemoryEntityStore() *InMemoryEntityStore {
+	return &InMemoryEntityStore{sessions: map[string]map[string]string{}}
+}
+
+func (s *InMemoryEntityStore) Get(_ context.Context, sessionID string, entities ...string) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	summaries := map[string]string{}
+	for _, entity := range entities {
+		if summary, ok := s.sessions[sessionID][entity]; ok {
+			summaries[entity] = summary
+		}
+	}
+	return summaries, nil
+}
+
+func (s *InMemoryEntityStore) Set(_ context.Context, sessionID string, entity string, summary string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions[sessionID] == nil {
+		s.sessions[sessionID] = map[string]string{}
+	}
+	s.sessions[sessionID][entity] = summary
+	return nil
+}
+
+func (s *InMemoryEntityStore) Delete(_ context.Context, sessionID string, entity string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions[sessionID], entity)
+	return nil
+}
+
+func (s *InMemoryEntityStore) Clear(_ context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+// BoltEntityStore is an EntityStore persisted in a BoltDB database. Each session is kept in its
+// own nested bucket, inside the bucket given to NewBoltEntityStore.
+type BoltEntityStore struct {
+	db     *bbolt.DB
+	bucket string
+}
+
+// NewBoltEntityStore creates an EntityStore persisted in the given bucket of a BoltDB database,
+// creating the bucket if it does not exist. The database is not closed by the store.
+func NewBoltEntityStore(db *bbolt.DB, bucket string) (*BoltEntityStore, error) {
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return fmt.Errorf("create bucket: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &BoltEntityStore{db: db, bucket: bucket}, nil
+}
+
+func (s *BoltEntityStore) Get(_ context.Context, sessionID string, entities ...string) (map[string]string, error) {
+	summaries := map[string]string{}
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		session := tx.Bucket([]byte(s.bucket)).Bucket([]byte(sessionID))
+		if session == nil {
+			return nil
+		}
+		for _, entity := range entities {
+			if summary := session.Get([]byte(entity)); summary != nil {
+				summaries[entity] = string(summary)
+			}
+		}
+		return nil
+	})
+	return summaries, err
+}
+
+func (s *BoltEntityStore) Set(_ context.Context, sessionID string, entity string, summary string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		session, err := tx.Bucket([]byte(s.bucket)).CreateBucketIfNotExists([]byte(sessionID))
+		if err != nil {
+			return fmt.Errorf("create session bucket: %w", err)
+		}
+		return session.Put([]byte(entity), []byte(summary))
+	})
+}
+
+func (s *BoltEntityStore) Delete(_ context.Context, sessionID string, entity string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		session := tx.Bucket([]byte(s.bucket)).Bucket([]byte(sessionID))
+		if session == nil {
+			return nil
+		}
+		return session.Delete([]byte(entity))
+	})
+}
+
+func (s *BoltEntityStore) Clear(_ context.Context, sessionID string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		err := tx.Bucket([]byte(s.bucket)).DeleteBucket([]byte(sessionID))
+		if errors.Is(err, bbolt.ErrBucketNotFound) {
+			return nil
+		}
+		return err
+	})
+}
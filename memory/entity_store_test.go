@@ -0,0 +1,94 @@
+package memory_test
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/deluan/flowllm/memory"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.etcd.io/bbolt"
+)
+
+var _ = Describe("EntityStore", func() {
+	implementations := []struct {
+		name     string
+		newStore func() memory.EntityStore
+	}{
+		{"InMemoryEntityStore", func() memory.EntityStore {
+			return memory.NewInMemoryEntityStore()
+		}},
+		{"BoltEntityStore", func() memory.EntityStore {
+			db, err := bbolt.Open(filepath.Join(GinkgoT().TempDir(), "entities.db"), 0600, nil)
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(db.Close)
+			store, err := memory.NewBoltEntityStore(db, "entities")
+			Expect(err).ToNot(HaveOccurred())
+			return store
+		}},
+	}
+
+	for _, impl := range implementations {
+		newStore := impl.newStore
+		Describe(impl.name, func() {
+			var (
+				ctx   context.Context
+				store memory.EntityStore
+			)
+
+			BeforeEach(func() {
+				ctx = context.Background()
+				store = newStore()
+			})
+
+			It("returns only the entities with a summary", func() {
+				Expect(store.Set(ctx, "session", "Alice", "Alice likes apples")).To(Succeed())
+				Expect(store.Set(ctx, "session", "Bob", "Bob likes bananas")).To(Succeed())
+
+				summaries, err := store.Get(ctx, "session", "Alice", "Carol")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(summaries).To(Equal(map[string]string{"Alice": "Alice likes apples"}))
+
+				summaries, err = store.Get(ctx, "missing", "Alice")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(summaries).To(BeEmpty())
+			})
+
+			It("replaces the summary of an entity", func() {
+				Expect(store.Set(ctx, "session", "Alice", "Alice likes apples")).To(Succeed())
+				Expect(store.Set(ctx, "session", "Alice", "Alice likes apples and bananas")).To(Succeed())
+
+				summaries, err := store.Get(ctx, "session", "Alice")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(summaries).To(HaveKeyWithValue("Alice", "Alice likes apples and bananas"))
+			})
+
+			It("keeps the sessions apart", func() {
+				Expect(store.Set(ctx, "session1", "Alice", "Alice likes apples")).To(Succeed())
+				Expect(store.Set(ctx, "session2", "Alice", "Alice is a doctor")).To(Succeed())
+
+				summaries, err := store.Get(ctx, "session2", "Alice")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(summaries).To(HaveKeyWithValue("Alice", "Alice is a doctor"))
+			})
+
+			It("deletes entities and clears sessions", func() {
+				Expect(store.Set(ctx, "session", "Alice", "Alice likes apples")).To(Succeed())
+				Expect(store.Set(ctx, "session", "Bob", "Bob likes bananas")).To(Succeed())
+
+				Expect(store.Delete(ctx, "session", "Alice")).To(Succeed())
+				summaries, err := store.Get(ctx, "session", "Alice", "Bob")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(summaries).To(Equal(map[string]string{"Bob": "Bob likes bananas"}))
+
+				Expect(store.Clear(ctx, "session")).To(Succeed())
+				summaries, err = store.Get(ctx, "session", "Alice", "Bob")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(summaries).To(BeEmpty())
+
+				Expect(store.Delete(ctx, "missing", "Alice")).To(Succeed())
+				Expect(store.Clear(ctx, "missing")).To(Succeed())
+			})
+		})
+	}
+})
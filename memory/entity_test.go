@@ -0,0 +1,123 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/memory"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Entity", func() {
+	var (
+		ctx   context.Context
+		model *fakeEntityModel
+		mem   *memory.Entity
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		model = &fakeEntityModel{}
+		mem = memory.NewEntity(model, memory.EntityOptions{})
+	})
+
+	It("keeps summaries of the entities mentioned in the conversation", func() {
+		Expect(mem.Save(ctx, exchange("My friend Alice likes apples", "Good for Alice!"))).To(Succeed())
+		Expect(mem.Save(ctx, exchange("Bob prefers bananas", "Noted"))).To(Succeed())
+		Expect(mem.Save(ctx, exchange("Alice is a doctor", "Interesting"))).To(Succeed())
+
+		messages, err := mem.Load(ctx, "What does Alice like?")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages[0]).To(Equal(flowllm.ChatMessage{
+			Role:    "system",
+			Content: "Context about the entities in the conversation:\nAlice: [user: My friend Alice likes apples | assistant: Good for Alice!] [user: Alice is a doctor | assistant: Interesting]",
+		}))
+		Expect(messages[1:]).To(HaveLen(6))
+		Expect(messages[1].Content).To(Equal("My friend Alice likes apples"))
+	})
+
+	It("returns only the recent messages when no known entity is mentioned", func() {
+		Expect(mem.Save(ctx, exchange("Alice likes apples", "Ok"))).To(Succeed())
+
+		messages, err := mem.Load(ctx, "What about Carol?")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(Equal(flowllm.ChatMessages{
+			{Role: "user", Content: "Alice likes apples"},
+			{Role: "assistant", Content: "Ok"},
+		}))
+	})
+
+	It("limits the recent messages to the window size", func() {
+		mem = memory.NewEntity(model, memory.EntityOptions{WindowSize: 1})
+		Expect(mem.Save(ctx, exchange("Alice likes apples", "Ok"))).To(Succeed())
+		Expect(mem.Save(ctx, exchange("Bob likes bananas", "Ok"))).To(Succeed())
+
+		messages, err := mem.Load(ctx, "Tell me about Bob and Alice")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(Equal(flowllm.ChatMessages{
+			{Role: "system", Content: "Context about the entities in the conversation:\nBob: [user: Bob likes bananas | assistant: Ok]\nAlice: [user: Alice likes apples | assistant: Ok]"},
+			{Role: "user", Content: "Bob likes bananas"},
+			{Role: "assistant", Content: "Ok"},
+		}))
+	})
+
+	It("keeps the entities of each session apart", func() {
+		aliceCtx := flowllm.WithSessionID(ctx, "alice")
+		Expect(mem.Save(aliceCtx, exchange("Bob is my brother", "Nice"))).To(Succeed())
+
+		messages, err := mem.Load(ctx, "Who is Bob?")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(BeEmpty())
+
+		messages, err = mem.Load(aliceCtx, "Who is Bob?")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages[0].Content).To(ContainSubstring("Bob: [user: Bob is my brother | assistant: Nice]"))
+	})
+
+	It("uses the given entity store", func() {
+		store := memory.NewInMemoryEntityStore()
+		mem = memory.NewEntity(model, memory.EntityOptions{Store: store})
+		Expect(mem.Save(ctx, exchange("Alice likes apples", "Ok"))).To(Succeed())
+
+		summaries, err := store.Get(ctx, memory.DefaultSessionID, "Alice")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(summaries).To(HaveKeyWithValue("Alice", "[user: Alice likes apples | assistant: Ok]"))
+	})
+
+	It("returns the errors from the model", func() {
+		model.err = errors.New("model failed")
+		Expect(mem.Save(ctx, exchange("Alice likes apples", "Ok"))).To(MatchError("model failed"))
+		_, err := mem.Load(ctx, "Who is Alice?")
+		Expect(err).To(MatchError("model failed"))
+	})
+})
+
+// fakeEntityModel extracts the known names mentioned in the last lines as entities, and appends
+// the last lines of the conversation to the entity summaries, in a compact form
+type fakeEntityModel struct {
+	err error
+}
+
+var knownNames = regexp.MustCompile(`Alice|Bob|Carol`)
+
+func (f *fakeEntityModel) Call(_ context.Context, prompt string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	lastLines := prompt[strings.LastIndex(prompt, "Last lines:\n")+len("Last lines:\n"):]
+	if strings.HasSuffix(prompt, "Output:") {
+		names := knownNames.FindAllString(lastLines, -1)
+		if len(names) == 0 {
+			return "NONE", nil
+		}
+		return strings.Join(names, ", "), nil
+	}
+	summary := prompt[strings.Index(prompt, "Existing summary of "):strings.LastIndex(prompt, "\n\nLast lines:")]
+	summary = summary[strings.Index(summary, ":\n")+2:]
+	newLines := strings.TrimSpace(strings.TrimSuffix(lastLines, "Updated summary:"))
+	return strings.TrimSpace(summary + " [" + strings.ReplaceAll(newLines, "\n", " | ") + "]"), nil
+}
@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/deluan/flowllm"
+)
+
+// JSONLChatHistory is a ChatMessageHistory persisted as files in a directory, one file per
+// session. Each file has one JSON encoded message per line, so new messages are just appended.
+type JSONLChatHistory struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewJSONLChatHistory creates a ChatMessageHistory that stores the sessions in the given
+// directory, creating it if it does not exist.
+func NewJSONLChatHistory(dir string) (*JSONLChatHistory, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &JSONLChatHistory{dir: dir}, nil
+}
+
+// path returns the file name of the session. Session IDs are escaped, so they can't be used to
+// access files outside the directory.
+func (h *JSONLChatHistory) path(sessionID string) string {
+	return filepath.Join(h.dir, url.PathEscape(sessionID)+".jsonl")
+}
+
+func (h *JSONLChatHistory) Messages(_ context.Context, sessionID string) (flowllm.ChatMessages, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	messages := flowllm.ChatMessages{}
+	f, err := os.Open(h.path(sessionID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return messages, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var msg flowllm.ChatMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return nil, fmt.Errorf("invalid message in %s: %w", f.Name(), err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, scanner.Err()
+}
+
+func (h *JSONLChatHistory) AddMessages(_ context.Context, sessionID string, messages ...flowllm.ChatMessage) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, err := os.OpenFile(h.path(sessionID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, msg := range messages {
+		if err := enc.Encode(msg); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// ReplaceMessages writes the messages to a temporary file, which is then renamed to the file of
+// the session, so the previous messages are kept if writing fails.
+func (h *JSONLChatHistory) ReplaceMessages(_ context.Context, sessionID string, messages ...flowllm.ChatMessage) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, err := os.CreateTemp(h.dir, ".replace-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	enc := json.NewEncoder(f)
+	for _, msg := range messages {
+		if err := enc.Encode(msg); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), h.path(sessionID))
+}
+
+func (h *JSONLChatHistory) Clear(_ context.Context, sessionID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	err := os.Remove(h.path(sessionID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -1,8 +1,12 @@
 package memory_test
 
 import (
+	"context"
+	"strings"
 	"testing"
 
+	"github.com/deluan/flowllm"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -11,3 +15,35 @@ func TestMemory(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Memory Suite")
 }
+
+// fakeEmbeddings creates embeddings based on the presence of a few known words
+type fakeEmbeddings struct{}
+
+var vocabulary = []string{"fruit", "apple", "banana", "phone", "laptop"}
+
+func (f *fakeEmbeddings) EmbedString(_ context.Context, text string) ([]float32, error) {
+	vector := make([]float32, len(vocabulary)+1)
+	vector[len(vocabulary)] = 0.1
+	for i, word := range vocabulary {
+		if strings.Contains(strings.ToLower(text), word) {
+			vector[i] = 1
+		}
+	}
+	return vector, nil
+}
+
+func (f *fakeEmbeddings) EmbedStrings(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = f.EmbedString(ctx, text)
+	}
+	return vectors, nil
+}
+
+// exchange returns a question/answer pair, to be saved in the memories
+func exchange(input, output string) flowllm.ChatMessages {
+	return flowllm.ChatMessages{
+		{Role: "user", Content: input},
+		{Role: "assistant", Content: output},
+	}
+}
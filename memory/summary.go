@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/deluan/flowllm"
+)
+
+// DefaultSummaryPrompt is the default prompt used to update the summary of the conversation.
+// It has access to the variables {summary} and {new_lines}.
+const DefaultSummaryPrompt = flowllm.Template(`Progressively summarize the lines of conversation provided, adding onto the previous summary and returning a new summary.
+
+EXAMPLE
+Current summary:
+The user asks what the assistant thinks of artificial intelligence. The assistant thinks artificial intelligence is a force for good.
+
+New lines of conversation:
+user: Why do you think artificial intelligence is a force for good?
+assistant: Because artificial intelligence will help humans reach their full potential.
+
+New summary:
+The user asks what the assistant thinks of artificial intelligence. The assistant thinks artificial intelligence is a force for good because it will help humans reach their full potential.
+END OF EXAMPLE
+
+Current summary:
+{summary}
+
+New lines of conversation:
+{new_lines}
+
+New summary:`)
+
+// summaryRole is the role of the message that holds the summary of the conversation in the
+// ChatMessageHistory. It is returned as a system message by Load.
+const summaryRole = "summary"
+
+// SummaryOptions for the Summary memory
+type SummaryOptions struct {
+	// Prompt used to update the summary. Defaults to DefaultSummaryPrompt
+	Prompt flowllm.Template
+	// ChatHistory is where the summary is kept. Defaults to an InMemoryChatHistory
+	ChatHistory ChatMessageHistory
+}
+
+// Summary is a memory that keeps a running summary of the conversation, instead of the messages
+// themselves. The summary is updated by a LanguageModel every time an exchange is saved, and it is
+// stored in the ChatHistory as a single message with the "summary" role.
+// A summary is kept for each session, taken from the context, see flowllm.WithSessionID.
+type Summary struct {
+	model flowllm.LanguageModel
+	opts  SummaryOptions
+	locks sessionLocks
+}
+
+// NewSummary creates a new Summary memory, using the given model to update the summary.
+func NewSummary(model flowllm.LanguageModel, opts SummaryOptions) *Summary {
+	if opts.Prompt == "" {
+		opts.Prompt = DefaultSummaryPrompt
+	}
+	if opts.ChatHistory == nil {
+		opts.ChatHistory = NewInMemoryChatHistory()
+	}
+	return &Summary{model: model, opts: opts}
+}
+
+// Load returns the summary of the conversation as a system message.
+func (s *Summary) Load(ctx context.Context, _ string) (flowllm.ChatMessages, error) {
+	id := sessionID(ctx)
+	defer s.locks.lock(id)()
+	summary, _, err := loadSummary(ctx, s.opts.ChatHistory, id)
+	if err != nil {
+		return nil, err
+	}
+	return summaryMessages(summary, nil), nil
+}
+
+// Save updates the summary with the messages of the last exchange. Messages already in the
+// ChatHistory that are not part of the summary are summarized with them.
+func (s *Summary) Save(ctx context.Context, messages flowllm.ChatMessages) error {
+	id := sessionID(ctx)
+	defer s.locks.lock(id)()
+	summary, pending, err := loadSummary(ctx, s.opts.ChatHistory, id)
+	if err != nil {
+		return err
+	}
+	summary, err = summarize(ctx, s.model, s.opts.Prompt, summary, append(pending, messages...))
+	if err != nil {
+		return err
+	}
+	return storeSummary(ctx, s.opts.ChatHistory, id, summary, nil)
+}
+
+// loadSummary returns the summary of the session and the messages that follow it in the history.
+func loadSummary(ctx context.Context, history ChatMessageHistory, id string) (string, flowllm.ChatMessages, error) {
+	messages, err := history.Messages(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(messages) > 0 && messages[0].Role == summaryRole {
+		return messages[0].Content, messages[1:], nil
+	}
+	return "", messages, nil
+}
+
+// storeSummary replaces the messages of the session in the history with the summary, followed by
+// the messages.
+func storeSummary(ctx context.Context, history ChatMessageHistory, id string, summary string, messages flowllm.ChatMessages) error {
+	stored := append(flowllm.ChatMessages{{Role: summaryRole, Content: summary}}, messages...)
+	return history.ReplaceMessages(ctx, id, stored...)
+}
+
+// summarize uses the model to add the messages to the current summary.
+func summarize(ctx context.Context, model flowllm.LanguageModel, prompt flowllm.Template, summary string, messages flowllm.ChatMessages) (string, error) {
+	vals, err := prompt.Call(ctx, flowllm.Values{"summary": summary, "new_lines": messages.String()})
+	if err != nil {
+		return "", err
+	}
+	newSummary, err := model.Call(ctx, vals.Get(flowllm.DefaultKey))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(newSummary), nil
+}
+
+// sessionLocks serializes the updates of each session, so concurrent updates of the same session
+// do not lose messages. Locks are removed when they are no longer in use.
+type sessionLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sessionLock
+}
+
+type sessionLock struct {
+	mu    sync.Mutex
+	users int
+}
+
+// lock locks the session and returns the function that unlocks it.
+func (l *sessionLocks) lock(id string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*sessionLock{}
+	}
+	sl := l.locks[id]
+	if sl == nil {
+		sl = &sessionLock{}
+		l.locks[id] = sl
+	}
+	sl.users++
+	l.mu.Unlock()
+
+	sl.mu.Lock()
+	return func() {
+		sl.mu.Unlock()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		sl.users--
+		if sl.users == 0 {
+			delete(l.locks, id)
+		}
+	}
+}
+
+// summaryMessages returns the summary as a system message, followed by the messages.
+func summaryMessages(summary string, messages flowllm.ChatMessages) flowllm.ChatMessages {
+	var result flowllm.ChatMessages
+	if summary != "" {
+		result = append(result, flowllm.ChatMessage{Role: "system", Content: summary})
+	}
+	return append(result, messages...)
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/tiktoken"
+)
+
+const (
+	defaultSummaryBufferMaxTokens = 2000
+	defaultTokenizerModel         = "gpt-3.5-turbo"
+)
+
+// SummaryBufferOptions for the SummaryBuffer memory
+type SummaryBufferOptions struct {
+	// Prompt used to update the summary. Defaults to DefaultSummaryPrompt
+	Prompt flowllm.Template
+	// MaxTokens is the maximum number of tokens of the messages kept verbatim. Defaults to 2000
+	MaxTokens int
+	// LenFunc returns the number of tokens in a string. Defaults to tiktoken.Len("gpt-3.5-turbo")
+	LenFunc func(string) int
+	// ChatHistory is where the summary and the messages are kept. Defaults to an InMemoryChatHistory
+	ChatHistory ChatMessageHistory
+}
+
+// SummaryBuffer is a memory that keeps the most recent messages verbatim, up to a token limit.
+// When the limit is exceeded, the oldest messages are removed from the buffer and folded into a
+// running summary of the conversation, updated by a LanguageModel. The summary is stored in the
+// ChatHistory as a message with the "summary" role, followed by the recent messages. A buffer and a
+// summary are kept for each session, taken from the context, see flowllm.WithSessionID.
+type SummaryBuffer struct {
+	model flowllm.LanguageModel
+	opts  SummaryBufferOptions
+	locks sessionLocks
+}
+
+// NewSummaryBuffer creates a new SummaryBuffer memory, using the given model to update the summary.
+func NewSummaryBuffer(model flowllm.LanguageModel, opts SummaryBufferOptions) *SummaryBuffer {
+	if opts.Prompt == "" {
+		opts.Prompt = DefaultSummaryPrompt
+	}
+	if opts.MaxTokens == 0 {
+		opts.MaxTokens = defaultSummaryBufferMaxTokens
+	}
+	if opts.LenFunc == nil {
+		opts.LenFunc = tiktoken.Len(defaultTokenizerModel)
+	}
+	if opts.ChatHistory == nil {
+		opts.ChatHistory = NewInMemoryChatHistory()
+	}
+	return &SummaryBuffer{model: model, opts: opts}
+}
+
+// Load returns the summary of the older messages as a system message, followed by the recent messages.
+func (b *SummaryBuffer) Load(ctx context.Context, _ string) (flowllm.ChatMessages, error) {
+	id := sessionID(ctx)
+	defer b.locks.lock(id)()
+	summary, messages, err := loadSummary(ctx, b.opts.ChatHistory, id)
+	if err != nil {
+		return nil, err
+	}
+	return summaryMessages(summary, messages), nil
+}
+
+// Save adds the messages of the last exchange to the buffer. If the buffer exceeds the token
+// limit, the oldest exchanges are folded into the summary.
+func (b *SummaryBuffer) Save(ctx context.Context, messages flowllm.ChatMessages) error {
+	id := sessionID(ctx)
+	defer b.locks.lock(id)()
+	summary, buffer, err := loadSummary(ctx, b.opts.ChatHistory, id)
+	if err != nil {
+		return err
+	}
+	newMessages := messages
+	messages = append(buffer, messages...)
+
+	// Messages are pruned in whole exchanges, so the buffer always starts with a user message
+	var pruned flowllm.ChatMessages
+	for len(messages) > 0 && b.opts.LenFunc(messages.String()) > b.opts.MaxTokens {
+		n := 1
+		for n < len(messages) && messages[n].Role != "user" {
+			n++
+		}
+		pruned = append(pruned, messages[:n]...)
+		messages = messages[n:]
+	}
+	if len(pruned) == 0 {
+		return b.opts.ChatHistory.AddMessages(ctx, id, newMessages...)
+	}
+	summary, err = summarize(ctx, b.model, b.opts.Prompt, summary, pruned)
+	if err != nil {
+		return err
+	}
+	return storeSummary(ctx, b.opts.ChatHistory, id, summary, messages)
+}
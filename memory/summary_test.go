@@ -0,0 +1,191 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/memory"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Summary", func() {
+	var (
+		ctx   context.Context
+		model *fakeSummarizer
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		model = &fakeSummarizer{}
+	})
+
+	It("returns nothing before the first exchange", func() {
+		mem := memory.NewSummary(model, memory.SummaryOptions{})
+		messages, err := mem.Load(ctx, "hi")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(BeEmpty())
+	})
+
+	It("updates the summary on each exchange", func() {
+		mem := memory.NewSummary(model, memory.SummaryOptions{})
+		Expect(mem.Save(ctx, exchange("Hi, I'm Bob", "Hello Bob"))).To(Succeed())
+		Expect(mem.Save(ctx, exchange("I like apples", "Me too"))).To(Succeed())
+
+		Expect(model.prompts).To(HaveLen(2))
+		Expect(model.prompts[1]).To(ContainSubstring("Current summary:\n[user: Hi, I'm Bob | assistant: Hello Bob]"))
+		Expect(model.prompts[1]).To(ContainSubstring("New lines of conversation:\nuser: I like apples\nassistant: Me too"))
+
+		messages, err := mem.Load(ctx, "What do I like?")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(Equal(flowllm.ChatMessages{
+			{Role: "system", Content: "[user: Hi, I'm Bob | assistant: Hello Bob] [user: I like apples | assistant: Me too]"},
+		}))
+	})
+
+	It("keeps the summary in the ChatHistory", func() {
+		history := memory.NewInMemoryChatHistory()
+		mem := memory.NewSummary(model, memory.SummaryOptions{ChatHistory: history})
+		Expect(mem.Save(ctx, exchange("Hi, I'm Bob", "Hello Bob"))).To(Succeed())
+
+		stored, err := history.Messages(ctx, memory.DefaultSessionID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored).To(Equal(flowllm.ChatMessages{
+			{Role: "summary", Content: "[user: Hi, I'm Bob | assistant: Hello Bob]"},
+		}))
+
+		mem = memory.NewSummary(model, memory.SummaryOptions{ChatHistory: history})
+		messages, err := mem.Load(ctx, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(Equal(flowllm.ChatMessages{
+			{Role: "system", Content: "[user: Hi, I'm Bob | assistant: Hello Bob]"},
+		}))
+	})
+
+	It("returns the errors from the model", func() {
+		model.err = errors.New("model failed")
+		mem := memory.NewSummary(model, memory.SummaryOptions{})
+		Expect(mem.Save(ctx, exchange("Hi", "Hello"))).To(MatchError("model failed"))
+	})
+})
+
+var _ = Describe("SummaryBuffer", func() {
+	var (
+		ctx   context.Context
+		model *fakeSummarizer
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		model = &fakeSummarizer{}
+	})
+
+	It("keeps the messages verbatim while under the token limit", func() {
+		mem := memory.NewSummaryBuffer(model, memory.SummaryBufferOptions{MaxTokens: 100})
+		Expect(mem.Save(ctx, exchange("Hi, I'm Bob", "Hello Bob"))).To(Succeed())
+
+		messages, err := mem.Load(ctx, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(Equal(flowllm.ChatMessages{
+			{Role: "user", Content: "Hi, I'm Bob"},
+			{Role: "assistant", Content: "Hello Bob"},
+		}))
+		Expect(model.prompts).To(BeEmpty())
+	})
+
+	It("folds the oldest messages into the summary when over the token limit", func() {
+		wordCount := func(s string) int { return len(strings.Fields(s)) }
+		mem := memory.NewSummaryBuffer(model, memory.SummaryBufferOptions{MaxTokens: 10, LenFunc: wordCount})
+		Expect(mem.Save(ctx, exchange("Hi, I'm Bob", "Hello Bob"))).To(Succeed())
+		Expect(mem.Save(ctx, exchange("I like apples", "Me too"))).To(Succeed())
+
+		messages, err := mem.Load(ctx, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(Equal(flowllm.ChatMessages{
+			{Role: "system", Content: "[user: Hi, I'm Bob | assistant: Hello Bob]"},
+			{Role: "user", Content: "I like apples"},
+			{Role: "assistant", Content: "Me too"},
+		}))
+
+		Expect(mem.Save(ctx, exchange("Do you like bananas?", "Yes"))).To(Succeed())
+		messages, err = mem.Load(ctx, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(HaveLen(3))
+		Expect(messages[0].Content).To(Equal("[user: Hi, I'm Bob | assistant: Hello Bob] [user: I like apples | assistant: Me too]"))
+		Expect(messages[1].Content).To(Equal("Do you like bananas?"))
+	})
+
+	It("folds whole exchanges into the summary, including tool messages", func() {
+		wordCount := func(s string) int { return len(strings.Fields(s)) }
+		mem := memory.NewSummaryBuffer(model, memory.SummaryBufferOptions{MaxTokens: 9, LenFunc: wordCount})
+		Expect(mem.Save(ctx, flowllm.ChatMessages{
+			{Role: "user", Content: "Weather?"},
+			{Role: "tool", Content: "sunny"},
+			{Role: "assistant", Content: "Sunny"},
+		})).To(Succeed())
+		Expect(mem.Save(ctx, exchange("Thanks", "Welcome"))).To(Succeed())
+
+		messages, err := mem.Load(ctx, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(Equal(flowllm.ChatMessages{
+			{Role: "system", Content: "[user: Weather? | tool: sunny | assistant: Sunny]"},
+			{Role: "user", Content: "Thanks"},
+			{Role: "assistant", Content: "Welcome"},
+		}))
+	})
+
+	It("keeps the summary and the recent messages in the ChatHistory", func() {
+		history := memory.NewInMemoryChatHistory()
+		wordCount := func(s string) int { return len(strings.Fields(s)) }
+		opts := memory.SummaryBufferOptions{MaxTokens: 10, LenFunc: wordCount, ChatHistory: history}
+		mem := memory.NewSummaryBuffer(model, opts)
+		sessionCtx := flowllm.WithSessionID(ctx, "session1")
+		Expect(mem.Save(sessionCtx, exchange("Hi, I'm Bob", "Hello Bob"))).To(Succeed())
+		Expect(mem.Save(sessionCtx, exchange("I like apples", "Me too"))).To(Succeed())
+
+		stored, err := history.Messages(ctx, "session1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored).To(Equal(flowllm.ChatMessages{
+			{Role: "summary", Content: "[user: Hi, I'm Bob | assistant: Hello Bob]"},
+			{Role: "user", Content: "I like apples"},
+			{Role: "assistant", Content: "Me too"},
+		}))
+
+		mem = memory.NewSummaryBuffer(model, opts)
+		messages, err := mem.Load(sessionCtx, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(HaveLen(3))
+		Expect(messages[0]).To(Equal(flowllm.ChatMessage{Role: "system", Content: "[user: Hi, I'm Bob | assistant: Hello Bob]"}))
+	})
+
+	It("measures tokens with tiktoken by default", func() {
+		mem := memory.NewSummaryBuffer(model, memory.SummaryBufferOptions{MaxTokens: 12})
+		// "user: This is a test\nassistant: ok" has 11 tokens
+		Expect(mem.Save(ctx, exchange("This is a test", "ok"))).To(Succeed())
+		Expect(model.prompts).To(BeEmpty())
+		Expect(mem.Save(ctx, exchange("Another", "ok"))).To(Succeed())
+		Expect(model.prompts).To(HaveLen(1))
+	})
+})
+
+// fakeSummarizer appends the new lines of the conversation to the summary, in a compact form
+type fakeSummarizer struct {
+	prompts []string
+	err     error
+}
+
+func (f *fakeSummarizer) Call(_ context.Context, prompt string) (string, error) {
+	f.prompts = append(f.prompts, prompt)
+	if f.err != nil {
+		return "", f.err
+	}
+	parts := strings.Split(prompt, "Current summary:\n")
+	rest := parts[len(parts)-1]
+	summary := strings.TrimSpace(rest[:strings.Index(rest, "New lines of conversation:")])
+	newLines := rest[strings.Index(rest, "New lines of conversation:\n")+len("New lines of conversation:\n"):]
+	newLines = strings.TrimSpace(newLines[:strings.Index(newLines, "New summary:")])
+	compact := "[" + strings.ReplaceAll(newLines, "\n", " | ") + "]"
+	return strings.TrimSpace(summary + " " + compact), nil
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/tiktoken"
+)
+
+const (
+	// tokensPerMessage is the overhead of each message in the chat format used by OpenAI models,
+	// see https://github.com/openai/openai-cookbook/blob/main/examples/How_to_count_tokens_with_tiktoken.ipynb
+	tokensPerMessage = 3
+	// tokensPerReply is the overhead of priming the reply of the assistant
+	tokensPerReply = 3
+
+	defaultTokenBufferMaxTokens = 2000
+)
+
+// TokenBufferOptions for the TokenBuffer memory
+type TokenBufferOptions struct {
+	// MaxTokens is the maximum number of tokens of the messages returned by Load, including the
+	// overhead of the chat format. Defaults to 2000
+	MaxTokens int
+	// LenFunc returns the number of tokens in a string. Defaults to tiktoken.Len("gpt-3.5-turbo")
+	LenFunc func(string) int
+	// ChatHistory is where the messages are kept. Defaults to an InMemoryChatHistory
+	ChatHistory ChatMessageHistory
+}
+
+// TokenBuffer is a memory that returns the most recent messages of the conversation that fit in
+// a token budget. Unlike Buffer, which counts turns, it prevents long messages from exceeding
+// the context of the model. The session is taken from the context, see flowllm.WithSessionID.
+type TokenBuffer struct {
+	opts TokenBufferOptions
+}
+
+// NewTokenBuffer creates a new TokenBuffer memory. If history is not nil, it is used as the
+// initial conversation of the DefaultSessionID session. It is ignored if a ChatHistory is set
+// in the options.
+func NewTokenBuffer(opts TokenBufferOptions, history *flowllm.ChatMessages) *TokenBuffer {
+	if opts.MaxTokens == 0 {
+		opts.MaxTokens = defaultTokenBufferMaxTokens
+	}
+	if opts.LenFunc == nil {
+		opts.LenFunc = tiktoken.Len(defaultTokenizerModel)
+	}
+	if opts.ChatHistory == nil {
+		chatHistory := NewInMemoryChatHistory()
+		if history != nil {
+			_ = chatHistory.AddMessages(context.Background(), DefaultSessionID, *history...)
+		}
+		opts.ChatHistory = chatHistory
+	}
+	return &TokenBuffer{opts: opts}
+}
+
+// Load returns the most recent messages that fit in the token budget.
+func (b *TokenBuffer) Load(ctx context.Context, _ string) (flowllm.ChatMessages, error) {
+	messages, err := b.opts.ChatHistory.Messages(ctx, sessionID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	total := tokensPerReply
+	start := len(messages)
+	for start > 0 {
+		tokens := messageTokens(messages[start-1], b.opts.LenFunc)
+		if total+tokens > b.opts.MaxTokens {
+			break
+		}
+		total += tokens
+		start--
+	}
+	return messages[start:], nil
+}
+
+func (b *TokenBuffer) Save(ctx context.Context, messages flowllm.ChatMessages) error {
+	return b.opts.ChatHistory.AddMessages(ctx, sessionID(ctx), messages...)
+}
+
+// messageTokens returns the number of tokens used by the message in the chat format.
+func messageTokens(msg flowllm.ChatMessage, lenFunc func(string) int) int {
+	return tokensPerMessage + lenFunc(msg.Role) + lenFunc(msg.Content)
+}
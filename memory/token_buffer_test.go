@@ -0,0 +1,76 @@
+package memory_test
+
+import (
+	"context"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/memory"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TokenBuffer", func() {
+	var (
+		ctx       context.Context
+		wordCount func(string) int
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		wordCount = func(s string) int { return len(strings.Fields(s)) }
+	})
+
+	It("returns all messages when they fit in the budget", func() {
+		history := flowllm.ChatMessages{{Role: "user", Content: "Hi"}}
+		buf := memory.NewTokenBuffer(memory.TokenBufferOptions{LenFunc: wordCount}, &history)
+		Expect(buf.Save(ctx, exchange("How are you?", "Fine, thanks"))).To(Succeed())
+
+		messages, err := buf.Load(ctx, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(Equal(flowllm.ChatMessages{
+			{Role: "user", Content: "Hi"},
+			{Role: "user", Content: "How are you?"},
+			{Role: "assistant", Content: "Fine, thanks"},
+		}))
+	})
+
+	It("drops the oldest messages that do not fit in the budget, counting the chat format overhead", func() {
+		// Each message costs 3 tokens of overhead + 1 for the role + the words of the content,
+		// and 3 tokens are reserved for the reply
+		buf := memory.NewTokenBuffer(memory.TokenBufferOptions{MaxTokens: 3 + 6 + 7, LenFunc: wordCount}, nil)
+		Expect(buf.Save(ctx, exchange("first question", "first answer"))).To(Succeed())
+		Expect(buf.Save(ctx, exchange("a long second question", "ok"))).To(Succeed())
+
+		messages, err := buf.Load(ctx, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(Equal(flowllm.ChatMessages{
+			{Role: "user", Content: "a long second question"},
+			{Role: "assistant", Content: "ok"},
+		}))
+	})
+
+	It("returns nothing if the last message does not fit in the budget", func() {
+		buf := memory.NewTokenBuffer(memory.TokenBufferOptions{MaxTokens: 10, LenFunc: wordCount}, nil)
+		Expect(buf.Save(ctx, exchange("question", "a very long answer that does not fit"))).To(Succeed())
+
+		messages, err := buf.Load(ctx, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(BeEmpty())
+	})
+
+	It("uses tiktoken to count tokens by default", func() {
+		// "user" is 1 token and "This is a test" is 4 tokens, "assistant" is 1 token and "ok then" is 2 tokens
+		history := flowllm.ChatMessages{{Role: "user", Content: "This is a test"}, {Role: "assistant", Content: "ok then"}}
+
+		buf := memory.NewTokenBuffer(memory.TokenBufferOptions{MaxTokens: 3 + 8 + 6}, &history)
+		messages, err := buf.Load(ctx, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(HaveLen(2))
+
+		buf = memory.NewTokenBuffer(memory.TokenBufferOptions{MaxTokens: 3 + 8 + 5}, &history)
+		messages, err = buf.Load(ctx, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/deluan/flowllm"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	defaultVectorStoreMemoryK = 4
+	defaultFetchFactor        = 4
+
+	// Metadata keys used to store the exchanges in the VectorStore
+	messagesKey = "memory_messages"
+	savedAtKey  = "memory_saved_at"
+	sessionKey  = "memory_session"
+
+	// savedAtFormat is a fixed length version of time.RFC3339Nano, so timestamps can be compared as strings
+	savedAtFormat = "2006-01-02T15:04:05.000000000Z07:00"
+)
+
+// VectorStoreMemoryOptions for the VectorStoreMemory
+type VectorStoreMemoryOptions struct {
+	// K is the number of past exchanges loaded from the store. Defaults to 4
+	K int
+	// Now returns the current time, used to sort the exchanges. Defaults to time.Now
+	Now func() time.Time
+	// SessionStore returns the store where the exchanges of a session are kept, usually a
+	// collection named after the session, so searches only rank the exchanges of that session.
+	// Defaults to the store passed to NewVectorStoreMemory for all sessions
+	SessionStore func(sessionID string) flowllm.VectorStore
+}
+
+// VectorStoreMemory is a memory that saves each exchange as a Document in a
+// VectorStore. When loading, it returns the past exchanges most relevant to the current input,
+// instead of the most recent ones, so facts from early in a long conversation are not lost.
+// The exchanges are returned in the order they were saved.
+//
+// Exchanges are saved with the session taken from the context (see flowllm.WithSessionID), and
+// only the exchanges of the current session are loaded. If all sessions share the same store,
+// the search is widened until K exchanges of the session are found, so use
+// VectorStoreMemoryOptions.SessionStore when the store holds many sessions.
+type VectorStoreMemory struct {
+	sessionStore func(sessionID string) flowllm.VectorStore
+	k            int
+	now          func() time.Time
+}
+
+// NewVectorStoreMemory creates a new VectorStoreMemory backed by the given store. Use a
+// dedicated store or collection, as documents not saved by the memory are ignored.
+func NewVectorStoreMemory(store flowllm.VectorStore, opts VectorStoreMemoryOptions) *VectorStoreMemory {
+	if opts.K == 0 {
+		opts.K = defaultVectorStoreMemoryK
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.SessionStore == nil {
+		opts.SessionStore = func(string) flowllm.VectorStore { return store }
+	}
+	return &VectorStoreMemory{sessionStore: opts.SessionStore, k: opts.K, now: opts.Now}
+}
+
+// Load returns the K past exchanges most relevant to the input.
+func (m *VectorStoreMemory) Load(ctx context.Context, input string) (flowllm.ChatMessages, error) {
+	if input == "" {
+		return nil, nil
+	}
+	session := sessionID(ctx)
+	store := m.sessionStore(session)
+
+	// The store may hold other sessions, so more exchanges are fetched to compensate for them,
+	// until K exchanges of the session are found or there are no more exchanges in the store
+	var docs []flowllm.Document
+	for fetch := m.k * defaultFetchFactor; ; fetch *= defaultFetchFactor {
+		results, err := store.SimilaritySearch(ctx, input, fetch)
+		if err != nil {
+			return nil, err
+		}
+		docs = docs[:0]
+		for _, doc := range results {
+			if doc.Metadata[sessionKey] == session && len(docs) < m.k {
+				docs = append(docs, doc)
+			}
+		}
+		if len(docs) == m.k || len(results) < fetch {
+			break
+		}
+	}
+	slices.SortStableFunc(docs, func(a, b flowllm.Document) bool {
+		return fmt.Sprint(a.Metadata[savedAtKey]) < fmt.Sprint(b.Metadata[savedAtKey])
+	})
+
+	var messages flowllm.ChatMessages
+	for _, doc := range docs {
+		data, ok := doc.Metadata[messagesKey].(string)
+		if !ok {
+			continue
+		}
+		var exchange flowllm.ChatMessages
+		if err := json.Unmarshal([]byte(data), &exchange); err != nil {
+			return nil, fmt.Errorf("invalid messages in document %q: %w", doc.ID, err)
+		}
+		messages = append(messages, exchange...)
+	}
+	return messages, nil
+}
+
+// Save adds the messages of the last exchange to the store, as a single Document.
+func (m *VectorStoreMemory) Save(ctx context.Context, messages flowllm.ChatMessages) error {
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+	session := sessionID(ctx)
+	return m.sessionStore(session).AddDocuments(ctx, flowllm.Document{
+		PageContent: messages.Contents(),
+		Metadata: map[string]any{
+			messagesKey: string(data),
+			savedAtKey:  m.now().UTC().Format(savedAtFormat),
+			sessionKey:  session,
+		},
+	})
+}
@@ -0,0 +1,139 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/memory"
+	"github.com/deluan/flowllm/vectorstores"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VectorStoreMemory", func() {
+	var (
+		ctx   context.Context
+		store *vectorstores.Memory
+		mem   *memory.VectorStoreMemory
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		store = vectorstores.NewMemoryVectorStore(&fakeEmbeddings{})
+		now = time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+		mem = memory.NewVectorStoreMemory(store, memory.VectorStoreMemoryOptions{
+			K: 2,
+			Now: func() time.Time {
+				now = now.Add(time.Second)
+				return now
+			},
+		})
+	})
+
+	It("returns nothing when there is no input", func() {
+		Expect(mem.Save(ctx, exchange("My favorite fruit is apple", "Nice!"))).To(Succeed())
+		messages, err := mem.Load(ctx, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(BeEmpty())
+	})
+
+	It("loads the past exchanges most relevant to the input, in chronological order", func() {
+		Expect(mem.Save(ctx, exchange("My favorite fruit is banana", "Good choice"))).To(Succeed())
+		Expect(mem.Save(ctx, exchange("I need a new laptop", "What is your budget?"))).To(Succeed())
+		Expect(mem.Save(ctx, exchange("My phone is old", "Phones get slow with time"))).To(Succeed())
+		Expect(mem.Save(ctx, exchange("I also like apple, another fruit", "Apples are healthy"))).To(Succeed())
+
+		messages, err := mem.Load(ctx, "Which fruit do I like?")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(Equal(flowllm.ChatMessages{
+			{Role: "user", Content: "My favorite fruit is banana"},
+			{Role: "assistant", Content: "Good choice"},
+			{Role: "user", Content: "I also like apple, another fruit"},
+			{Role: "assistant", Content: "Apples are healthy"},
+		}))
+
+		messages, err = mem.Load(ctx, "Should I buy a phone or a laptop?")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(HaveLen(4))
+		Expect(messages[0].Content).To(Equal("I need a new laptop"))
+		Expect(messages[2].Content).To(Equal("My phone is old"))
+	})
+
+	Describe("sessions", func() {
+		var sessionCtx context.Context
+
+		BeforeEach(func() {
+			sessionCtx = flowllm.WithSessionID(ctx, "target")
+		})
+
+		saveSessions := func(mem *memory.VectorStoreMemory) {
+			Expect(mem.Save(sessionCtx, exchange("My favorite fruit is banana", "Good choice"))).To(Succeed())
+			for i := 0; i < 20; i++ {
+				otherCtx := flowllm.WithSessionID(ctx, fmt.Sprintf("other-%d", i))
+				Expect(mem.Save(otherCtx, exchange("I like apple, the fruit", "Apples are healthy"))).To(Succeed())
+			}
+		}
+
+		It("finds the exchanges of the session outside the top results of all sessions", func() {
+			saveSessions(mem)
+
+			messages, err := mem.Load(sessionCtx, "Which apple fruit do I like?")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(messages).To(Equal(exchange("My favorite fruit is banana", "Good choice")))
+		})
+
+		It("searches only the store of the session", func() {
+			mem = memory.NewVectorStoreMemory(store, memory.VectorStoreMemoryOptions{
+				K: 2,
+				SessionStore: func(sessionID string) flowllm.VectorStore {
+					return store.Collection("memory_" + sessionID)
+				},
+			})
+			saveSessions(mem)
+
+			messages, err := mem.Load(sessionCtx, "Which apple fruit do I like?")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(messages).To(Equal(exchange("My favorite fruit is banana", "Good choice")))
+
+			docs, err := store.Collection("memory_target").SimilaritySearch(ctx, "fruit", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(docs).To(HaveLen(1))
+		})
+	})
+
+	It("keeps all messages of an exchange, including system and tool messages", func() {
+		messages := flowllm.ChatMessages{
+			{Role: "system", Content: "The user is shopping"},
+			{Role: "user", Content: "How much is the laptop?"},
+			{Role: "tool", Content: `{"price": 1000}`},
+			{Role: "assistant", Content: "It costs 1000"},
+		}
+		Expect(mem.Save(ctx, messages)).To(Succeed())
+
+		loaded, err := mem.Load(ctx, "laptop")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(Equal(messages))
+	})
+
+	It("works as the memory of a chain", func() {
+		var history flowllm.ChatMessages
+		chain := flowllm.WithMemory(mem, flowllm.HandlerFunc(func(_ context.Context, values ...flowllm.Values) (flowllm.Values, error) {
+			history = values[0][flowllm.DefaultChatKey].(flowllm.ChatMessages)
+			return flowllm.Values{flowllm.DefaultKey: "ok"}, nil
+		}))
+
+		_, err := chain.Call(ctx, flowllm.Values{"input": "I bought a laptop"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(history).To(BeEmpty())
+
+		_, err = chain.Call(ctx, flowllm.Values{"input": "Is my laptop good?"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(history).To(Equal(flowllm.ChatMessages{
+			{Role: "user", Content: "I bought a laptop"},
+			{Role: "assistant", Content: "ok"},
+		}))
+	})
+})
@@ -13,6 +13,7 @@ import (
 	"errors"
 	"log"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/semaphore"
 )
@@ -166,6 +167,38 @@ func Tee[T any](ctx context.Context, in <-chan T) (<-chan T, <-chan T) {
 	return out1, out2
 }
 
+// Throttle copies the values from in to its output channel, waiting at least the interval between
+// values, measured from the time the previous value was received. It can be used to limit the rate
+// of the work done by the following stages.
+func Throttle[T any](ctx context.Context, in <-chan T, interval time.Duration) <-chan T {
+	if interval <= 0 {
+		return ReadOrDone(ctx, in)
+	}
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		var lastSent time.Time
+		for v := range ReadOrDone(ctx, in) {
+			if wait := interval - time.Since(lastSent); !lastSent.IsZero() && wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+			select {
+			case out <- v:
+				lastSent = time.Now()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
 func FromSlice[T any](ctx context.Context, in []T) <-chan T {
 	output := make(chan T, len(in))
 	for _, c := range in {
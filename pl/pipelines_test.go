@@ -165,4 +165,35 @@ var _ = Describe("Pipeline", func() {
 			})
 		})
 	})
+	Describe("Throttle", func() {
+		It("waits the interval between values", func() {
+			start := time.Now()
+			var values []int
+			for v := range pl.Throttle(context.Background(), pl.FromSlice(context.Background(), []int{1, 2, 3, 4}), 20*time.Millisecond) {
+				values = append(values, v)
+			}
+			Expect(values).To(Equal([]int{1, 2, 3, 4}))
+			Expect(time.Since(start)).To(BeNumerically(">=", 60*time.Millisecond))
+		})
+		It("waits the interval between values after a slow consumer", func() {
+			var received []time.Time
+			for v := range pl.Throttle(context.Background(), pl.FromSlice(context.Background(), []int{1, 2, 3}), 20*time.Millisecond) {
+				received = append(received, time.Now())
+				if v == 1 {
+					time.Sleep(50 * time.Millisecond)
+				}
+			}
+			Expect(received).To(HaveLen(3))
+			Expect(received[2].Sub(received[1])).To(BeNumerically(">=", 20*time.Millisecond))
+		})
+		When("the context is canceled", func() {
+			It("closes its output", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				in := make(chan int)
+				out := pl.Throttle(ctx, in, time.Hour)
+				cancel()
+				Eventually(out).Should(BeClosed())
+			})
+		})
+	})
 })
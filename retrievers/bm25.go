@@ -0,0 +1,251 @@
+package retrievers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/vectorstores"
+	"go.etcd.io/bbolt"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	defaultBM25K1 = 1.2
+	defaultBM25B  = 0.75
+)
+
+// BM25Options for the BM25 keyword index
+type BM25Options struct {
+	// K1 controls the term frequency saturation. It must not be negative, and 0 ignores the term
+	// frequency. Defaults to 1.2 when nil
+	K1 *float64
+	// B controls how much the document length normalizes the term frequency, between 0 and 1.
+	// 0 disables the length normalization. Defaults to 0.75 when nil
+	B *float64
+	// Tokenizer splits a text into terms. Defaults to lowercasing the text and splitting it
+	// on any character that is not a letter or a number
+	Tokenizer func(string) []string
+}
+
+// BM25 is an in-memory keyword index that ranks documents using the Okapi BM25 algorithm. It is
+// useful to find documents containing exact terms, like product codes or names, that are usually
+// missed by embeddings. It implements the flowllm.Retriever interface.
+type BM25 struct {
+	opts     BM25Options
+	k1, b    float64
+	mu       sync.RWMutex
+	docs     map[string]bm25Doc
+	postings map[string]map[string]int
+	totalLen int
+	db       *bbolt.DB
+	bucket   string
+}
+
+type bm25Doc struct {
+	flowllm.Document
+	length int
+}
+
+// NewBM25 creates a new empty BM25 index. It returns an error if K1 is negative or B is not
+// between 0 and 1.
+func NewBM25(opts BM25Options) (*BM25, error) {
+	k1, b := defaultBM25K1, defaultBM25B
+	if opts.K1 != nil {
+		k1 = *opts.K1
+	}
+	if opts.B != nil {
+		b = *opts.B
+	}
+	if k1 < 0 {
+		return nil, fmt.Errorf("invalid K1 %v: must not be negative", k1)
+	}
+	if b < 0 || b > 1 {
+		return nil, fmt.Errorf("invalid B %v: must be between 0 and 1", b)
+	}
+	if opts.Tokenizer == nil {
+		opts.Tokenizer = Tokenize
+	}
+	return &BM25{
+		opts:     opts,
+		k1:       k1,
+		b:        b,
+		docs:     map[string]bm25Doc{},
+		postings: map[string]map[string]int{},
+	}, nil
+}
+
+// NewBoltBM25 creates a BM25 index persisted in the given bucket of a BoltDB database. Documents
+// already stored in the bucket are loaded and indexed. The database is not closed by the index.
+func NewBoltBM25(db *bbolt.DB, bucket string, opts BM25Options) (*BM25, error) {
+	b, err := NewBM25(opts)
+	if err != nil {
+		return nil, err
+	}
+	b.db = db
+	b.bucket = bucket
+	err = db.Update(func(tx *bbolt.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return fmt.Errorf("create bucket: %w", err)
+		}
+		return bkt.ForEach(func(k, v []byte) error {
+			var doc flowllm.Document
+			if err := json.Unmarshal(v, &doc); err != nil {
+				return err
+			}
+			b.index(doc)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+var nonWordChars = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// Tokenize is the default tokenizer used by the BM25 index. It lowercases the text and splits it
+// on any character that is not a letter or a number.
+func Tokenize(text string) []string {
+	var terms []string
+	for _, term := range nonWordChars.Split(strings.ToLower(text), -1) {
+		if term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// AddDocuments adds the given documents to the index. Documents with the same ID as an already
+// indexed document replace it. Documents without ID get one derived from their content and metadata.
+func (b *BM25) AddDocuments(_ context.Context, documents ...flowllm.Document) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	documents = slices.Clone(documents)
+	for i := range documents {
+		documents[i].ID = vectorstores.DocumentID(documents[i])
+	}
+	if b.db != nil {
+		err := b.db.Update(func(tx *bbolt.Tx) error {
+			bkt := tx.Bucket([]byte(b.bucket))
+			for _, doc := range documents {
+				buf, err := json.Marshal(doc)
+				if err != nil {
+					return err
+				}
+				if err := bkt.Put([]byte(doc.ID), buf); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, doc := range documents {
+		b.index(doc)
+	}
+	return nil
+}
+
+// Delete removes the documents with the given IDs from the index.
+func (b *BM25) Delete(_ context.Context, ids ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.db != nil {
+		err := b.db.Update(func(tx *bbolt.Tx) error {
+			bkt := tx.Bucket([]byte(b.bucket))
+			for _, id := range ids {
+				if err := bkt.Delete([]byte(id)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, id := range ids {
+		b.remove(id)
+	}
+	return nil
+}
+
+func (b *BM25) index(doc flowllm.Document) {
+	b.remove(doc.ID)
+	terms := b.opts.Tokenizer(doc.PageContent)
+	for _, term := range terms {
+		if b.postings[term] == nil {
+			b.postings[term] = map[string]int{}
+		}
+		b.postings[term][doc.ID]++
+	}
+	b.docs[doc.ID] = bm25Doc{Document: doc, length: len(terms)}
+	b.totalLen += len(terms)
+}
+
+func (b *BM25) remove(id string) {
+	doc, ok := b.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range b.opts.Tokenizer(doc.PageContent) {
+		delete(b.postings[term], id)
+		if len(b.postings[term]) == 0 {
+			delete(b.postings, term)
+		}
+	}
+	b.totalLen -= doc.length
+	delete(b.docs, id)
+}
+
+// Retrieve returns the k documents with the highest BM25 score for the query. Only documents
+// containing at least one of the query terms are returned.
+func (b *BM25) Retrieve(_ context.Context, query string, k int) ([]flowllm.ScoredDocument, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.docs) == 0 {
+		return nil, nil
+	}
+
+	n := float64(len(b.docs))
+	avgLen := float64(b.totalLen) / n
+	scores := map[string]float64{}
+	for _, term := range b.opts.Tokenizer(query) {
+		postings := b.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range postings {
+			docLen := float64(b.docs[id].length)
+			freq := float64(tf)
+			scores[id] += idf * freq * (b.k1 + 1) / (freq + b.k1*(1-b.b+b.b*docLen/avgLen))
+		}
+	}
+
+	results := make([]flowllm.ScoredDocument, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, flowllm.ScoredDocument{Document: b.docs[id].Document, Score: float32(score)})
+	}
+	slices.SortFunc(results, func(a, b flowllm.ScoredDocument) bool {
+		if a.Score == b.Score {
+			return a.ID < b.ID
+		}
+		return a.Score > b.Score
+	})
+	if k < len(results) {
+		results = results[:k]
+	}
+	return results, nil
+}
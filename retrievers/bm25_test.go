@@ -0,0 +1,127 @@
+package retrievers_test
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/retrievers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.etcd.io/bbolt"
+)
+
+var _ = Describe("BM25", func() {
+	var (
+		ctx   context.Context
+		index *retrievers.BM25
+		docs  []flowllm.Document
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		index, err = retrievers.NewBM25(retrievers.BM25Options{})
+		Expect(err).ToNot(HaveOccurred())
+		docs = []flowllm.Document{
+			{ID: "1", PageContent: "The quick brown fox jumps over the lazy dog"},
+			{ID: "2", PageContent: "Replacement part XK-42 for the brown model"},
+			{ID: "3", PageContent: "Foxes are quick. A fox is a small omnivore. The fox lives in the forest"},
+		}
+	})
+
+	It("returns nothing when the index is empty", func() {
+		results, err := index.Retrieve(ctx, "fox", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(BeEmpty())
+	})
+
+	It("ranks documents by term frequency and rarity", func() {
+		Expect(index.AddDocuments(ctx, docs...)).To(Succeed())
+
+		results, err := index.Retrieve(ctx, "fox", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].ID).To(Equal("3"))
+		Expect(results[1].ID).To(Equal("1"))
+		Expect(results[0].Score).To(BeNumerically(">", results[1].Score))
+	})
+
+	param := func(v float64) *float64 { return &v }
+
+	It("disables the length normalization when B is 0", func() {
+		docs := []flowllm.Document{
+			{ID: "short", PageContent: "fox"},
+			{ID: "long", PageContent: "the fox jumps over the lazy dog"},
+			{ID: "other", PageContent: "a cat"},
+		}
+		Expect(index.AddDocuments(ctx, docs...)).To(Succeed())
+		results, err := index.Retrieve(ctx, "fox", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results[0].Score).To(BeNumerically(">", results[1].Score))
+
+		index, err = retrievers.NewBM25(retrievers.BM25Options{B: param(0)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(index.AddDocuments(ctx, docs...)).To(Succeed())
+		results, err = index.Retrieve(ctx, "fox", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].Score).To(Equal(results[1].Score))
+	})
+
+	It("rejects invalid parameters", func() {
+		_, err := retrievers.NewBM25(retrievers.BM25Options{B: param(1.5)})
+		Expect(err).To(MatchError(ContainSubstring("invalid B")))
+		_, err = retrievers.NewBM25(retrievers.BM25Options{K1: param(-1)})
+		Expect(err).To(MatchError(ContainSubstring("invalid K1")))
+	})
+
+	It("finds exact terms like product codes", func() {
+		Expect(index.AddDocuments(ctx, docs...)).To(Succeed())
+
+		results, err := index.Retrieve(ctx, "xk-42", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].ID).To(Equal("2"))
+	})
+
+	It("replaces and deletes documents by ID", func() {
+		Expect(index.AddDocuments(ctx, docs...)).To(Succeed())
+		Expect(index.AddDocuments(ctx, flowllm.Document{ID: "1", PageContent: "A sleepy cat"})).To(Succeed())
+		Expect(index.Delete(ctx, "3")).To(Succeed())
+
+		results, err := index.Retrieve(ctx, "fox cat", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].PageContent).To(Equal("A sleepy cat"))
+	})
+
+	It("assigns IDs to documents without one", func() {
+		Expect(index.AddDocuments(ctx, flowllm.Document{PageContent: "no id"})).To(Succeed())
+
+		results, err := index.Retrieve(ctx, "id", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].ID).ToNot(BeEmpty())
+	})
+
+	Describe("NewBoltBM25", func() {
+		It("persists the documents in the database", func() {
+			db, err := bbolt.Open(filepath.Join(GinkgoT().TempDir(), "bm25.db"), 0600, nil)
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(db.Close)
+
+			index, err := retrievers.NewBoltBM25(db, "bm25", retrievers.BM25Options{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(index.AddDocuments(ctx, docs...)).To(Succeed())
+			Expect(index.Delete(ctx, "1")).To(Succeed())
+
+			reloaded, err := retrievers.NewBoltBM25(db, "bm25", retrievers.BM25Options{})
+			Expect(err).ToNot(HaveOccurred())
+			results, err := reloaded.Retrieve(ctx, "fox brown", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect([]string{results[0].ID, results[1].ID}).To(ConsistOf("2", "3"))
+		})
+	})
+})
@@ -0,0 +1,132 @@
+// Package cohere implements a reranker backed by the Cohere Rerank API.
+package cohere
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/deluan/flowllm"
+)
+
+const (
+	DefaultBaseURL = "https://api.cohere.ai"
+	DefaultModel   = "rerank-english-v2.0"
+)
+
+// Options for the Cohere reranker
+type Options struct {
+	// ApiKey used to authenticate with the API. Defaults to the COHERE_API_KEY environment variable
+	ApiKey string
+	// Model used to rerank the documents. Defaults to DefaultModel
+	Model string
+	// BaseURL of the API. Defaults to DefaultBaseURL
+	BaseURL string
+	// TopN is the maximum number of documents returned by the API. If not set, all documents are returned
+	TopN int
+	// HTTPClient used to make requests. Defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Reranker reorders documents using the Cohere Rerank API. It implements the retrievers.Reranker interface.
+type Reranker struct {
+	opts Options
+}
+
+// NewReranker creates a new Cohere reranker.
+func NewReranker(opts Options) (*Reranker, error) {
+	if opts.ApiKey == "" {
+		opts.ApiKey = os.Getenv("COHERE_API_KEY")
+	}
+	if opts.ApiKey == "" {
+		return nil, fmt.Errorf("missing the Cohere API key, set it in the COHERE_API_KEY environment variable")
+	}
+	if opts.Model == "" {
+		opts.Model = DefaultModel
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultBaseURL
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &Reranker{opts: opts}, nil
+}
+
+type rerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n,omitempty"`
+}
+
+type rerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float32 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+// Rerank returns the documents sorted by the relevance score returned by the API.
+func (r *Reranker) Rerank(ctx context.Context, query string, docs []flowllm.ScoredDocument) ([]flowllm.ScoredDocument, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	payload := rerankRequest{
+		Model:     r.opts.Model,
+		Query:     query,
+		Documents: make([]string, len(docs)),
+		TopN:      r.opts.TopN,
+	}
+	for i, doc := range docs {
+		payload.Documents[i] = doc.PageContent
+	}
+
+	var resp rerankResponse
+	if err := r.doRequest(ctx, "/v1/rerank", payload, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]flowllm.ScoredDocument, 0, len(resp.Results))
+	for _, res := range resp.Results {
+		if res.Index < 0 || res.Index >= len(docs) {
+			return nil, fmt.Errorf("invalid document index in rerank response: %d", res.Index)
+		}
+		doc := docs[res.Index]
+		doc.Score = res.RelevanceScore
+		results = append(results, doc)
+	}
+	return results, nil
+}
+
+func (r *Reranker) doRequest(ctx context.Context, path string, payload any, result any) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(r.opts.BaseURL, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+r.opts.ApiKey)
+
+	resp, err := r.opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("error calling rerank API: status %d: body: %s", resp.StatusCode, string(body))
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,116 @@
+package cohere_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/retrievers"
+	"github.com/deluan/flowllm/retrievers/cohere"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ retrievers.Reranker = (*cohere.Reranker)(nil)
+
+var _ = Describe("Reranker", func() {
+	var (
+		ctx      context.Context
+		server   *httptest.Server
+		received map[string]any
+		docs     []flowllm.ScoredDocument
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		received = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/rerank" || r.Method != http.MethodPost {
+				http.NotFound(w, r)
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer test-key" {
+				http.Error(w, `{"message":"invalid api token"}`, http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewDecoder(r.Body).Decode(&received)
+			// Scores documents by the number of times the query appears in them
+			query := received["query"].(string)
+			var results []map[string]any
+			for i, doc := range received["documents"].([]any) {
+				count := strings.Count(doc.(string), query)
+				results = append(results, map[string]any{"index": i, "relevance_score": float64(count) / 10})
+			}
+			sort.SliceStable(results, func(i, j int) bool {
+				return results[i]["relevance_score"].(float64) > results[j]["relevance_score"].(float64)
+			})
+			if topN, ok := received["top_n"].(float64); ok && int(topN) < len(results) {
+				results = results[:int(topN)]
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "1", "results": results})
+		}))
+		DeferCleanup(server.Close)
+		docs = []flowllm.ScoredDocument{
+			{Document: flowllm.Document{PageContent: "go", Metadata: map[string]any{"id": 1}}, Score: 0.9},
+			{Document: flowllm.Document{PageContent: "go go go", Metadata: map[string]any{"id": 2}}, Score: 0.8},
+			{Document: flowllm.Document{PageContent: "go go", Metadata: map[string]any{"id": 3}}, Score: 0.7},
+		}
+	})
+
+	newReranker := func(opts cohere.Options) *cohere.Reranker {
+		opts.BaseURL = server.URL
+		if opts.ApiKey == "" {
+			opts.ApiKey = "test-key"
+		}
+		reranker, err := cohere.NewReranker(opts)
+		Expect(err).ToNot(HaveOccurred())
+		return reranker
+	}
+
+	It("reorders the documents using the scores returned by the API", func() {
+		reranker := newReranker(cohere.Options{})
+		results, err := reranker.Rerank(ctx, "go", docs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(received["model"]).To(Equal(cohere.DefaultModel))
+		Expect(received["documents"]).To(Equal([]any{"go", "go go go", "go go"}))
+
+		Expect(results).To(HaveLen(3))
+		Expect(results[0].Metadata["id"]).To(Equal(2))
+		Expect(results[0].Score).To(BeNumerically("~", 0.3, 0.0001))
+		Expect(results[1].Metadata["id"]).To(Equal(3))
+		Expect(results[2].Metadata["id"]).To(Equal(1))
+	})
+
+	It("sends the TopN option to the API", func() {
+		reranker := newReranker(cohere.Options{TopN: 1, Model: "rerank-multilingual-v2.0"})
+		results, err := reranker.Rerank(ctx, "go", docs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(received["model"]).To(Equal("rerank-multilingual-v2.0"))
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].PageContent).To(Equal("go go go"))
+	})
+
+	It("does not call the API when there are no documents", func() {
+		reranker := newReranker(cohere.Options{})
+		results, err := reranker.Rerank(ctx, "go", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(BeEmpty())
+		Expect(received).To(BeNil())
+	})
+
+	It("returns an error when the API fails", func() {
+		reranker := newReranker(cohere.Options{ApiKey: "wrong-key"})
+		_, err := reranker.Rerank(ctx, "go", docs)
+		Expect(err).To(MatchError(ContainSubstring("status 401")))
+	})
+
+	It("requires an API key", func() {
+		GinkgoT().Setenv("COHERE_API_KEY", "")
+		_, err := cohere.NewReranker(cohere.Options{})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,139 @@
+package retrievers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/deluan/flowllm"
+	"go.etcd.io/bbolt"
+)
+
+// DocStore stores documents by their ID. It is used by retrievers that need to keep the original
+// documents separated from what is indexed, like the ParentDocument retriever.
+type DocStore interface {
+	// Set stores the documents, replacing any existing document with the same ID
+	Set(ctx context.Context, documents ...flowllm.Document) error
+	// Get returns the documents with the given IDs, in the same order. IDs not found are skipped
+	Get(ctx context.Context, ids ...string) ([]flowllm.Document, error)
+	// Delete removes the documents with the given IDs
+	Delete(ctx context.Context, ids ...string) error
+}
+
+// MemoryDocStore is an in-memory DocStore.
+type MemoryDocStore struct {
+	mu   sync.RWMutex
+	docs map[string]flowllm.Document
+}
+
+// NewMemoryDocStore creates a new empty in-memory DocStore.
+func NewMemoryDocStore() *MemoryDocStore {
+	return &MemoryDocStore{docs: map[string]flowllm.Document{}}
+}
+
+func (m *MemoryDocStore) Set(_ context.Context, documents ...flowllm.Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, doc := range documents {
+		if doc.ID == "" {
+			return fmt.Errorf("document without ID")
+		}
+		m.docs[doc.ID] = doc
+	}
+	return nil
+}
+
+func (m *MemoryDocStore) Get(_ context.Context, ids ...string) ([]flowllm.Document, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var docs []flowllm.Document
+	for _, id := range ids {
+		if doc, ok := m.docs[id]; ok {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+func (m *MemoryDocStore) Delete(_ context.Context, ids ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		delete(m.docs, id)
+	}
+	return nil
+}
+
+// BoltDocStore is a DocStore persisted in a bucket of a BoltDB database.
+type BoltDocStore struct {
+	db     *bbolt.DB
+	bucket string
+}
+
+// NewBoltDocStore creates a DocStore persisted in the given bucket of a BoltDB database, creating
+// the bucket if it does not exist. The database is not closed by the store.
+func NewBoltDocStore(db *bbolt.DB, bucket string) (*BoltDocStore, error) {
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return fmt.Errorf("create bucket: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &BoltDocStore{db: db, bucket: bucket}, nil
+}
+
+func (b *BoltDocStore) Set(_ context.Context, documents ...flowllm.Document) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		bkt := tx.Bucket([]byte(b.bucket))
+		for _, doc := range documents {
+			if doc.ID == "" {
+				return fmt.Errorf("document without ID")
+			}
+			buf, err := json.Marshal(doc)
+			if err != nil {
+				return err
+			}
+			if err := bkt.Put([]byte(doc.ID), buf); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *BoltDocStore) Get(_ context.Context, ids ...string) ([]flowllm.Document, error) {
+	var docs []flowllm.Document
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		bkt := tx.Bucket([]byte(b.bucket))
+		for _, id := range ids {
+			v := bkt.Get([]byte(id))
+			if v == nil {
+				continue
+			}
+			var doc flowllm.Document
+			if err := json.Unmarshal(v, &doc); err != nil {
+				return err
+			}
+			docs = append(docs, doc)
+		}
+		return nil
+	})
+	return docs, err
+}
+
+func (b *BoltDocStore) Delete(_ context.Context, ids ...string) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		bkt := tx.Bucket([]byte(b.bucket))
+		for _, id := range ids {
+			if err := bkt.Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
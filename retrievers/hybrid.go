@@ -0,0 +1,183 @@
+package retrievers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deluan/flowllm"
+)
+
+// FusionMethod is the method used by the Hybrid retriever to combine the results of the vector
+// and keyword searches.
+type FusionMethod string
+
+const (
+	// ReciprocalRankFusion scores each document by the sum of 1/(RRFConstant + rank) of each result list
+	// it appears in. It only takes into account the position of the documents in the lists, so it works
+	// well when the scores of the lists are not comparable.
+	ReciprocalRankFusion FusionMethod = "rrf"
+	// WeightedScores normalizes the scores of each result list to the range [0, 1], and then combines
+	// them with a weighted sum.
+	WeightedScores FusionMethod = "weighted"
+)
+
+const (
+	defaultRRFConstant  = 60
+	defaultVectorWeight = 0.5
+	defaultFetchFactor  = 4
+)
+
+// HybridOptions for the Hybrid retriever
+type HybridOptions struct {
+	// Method used to combine the results. Defaults to ReciprocalRankFusion
+	Method FusionMethod
+	// VectorWeight is the weight of the vector search results, between 0 and 1. The weight
+	// of the keyword search results is 1 - VectorWeight, so 0 uses only the keyword search and 1
+	// only the vector search. Defaults to 0.5 when nil
+	VectorWeight *float64
+	// RRFConstant is the constant used by the ReciprocalRankFusion method. Defaults to 60
+	RRFConstant int
+	// FetchK is the number of documents fetched from each search before combining them.
+	// Defaults to 4 times the number of documents requested
+	FetchK int
+}
+
+// Hybrid is a retriever that combines the results of a similarity search in a vector store with
+// the results of a keyword search, like the one provided by the BM25 index. This way documents
+// containing exact terms of the query are found even when their embeddings are not similar to the
+// query's. Documents are matched between the two searches by their content.
+type Hybrid struct {
+	store        flowllm.VectorStore
+	vector       flowllm.Retriever
+	keyword      flowllm.Retriever
+	opts         HybridOptions
+	vectorWeight float64
+}
+
+// NewHybrid creates a new Hybrid retriever, wrapping the given vector store and keyword retriever.
+// It returns an error if the VectorWeight is not between 0 and 1.
+func NewHybrid(store flowllm.VectorStore, embeddings flowllm.Embeddings, keyword flowllm.Retriever, opts HybridOptions) (*Hybrid, error) {
+	if opts.Method == "" {
+		opts.Method = ReciprocalRankFusion
+	}
+	vectorWeight := defaultVectorWeight
+	if opts.VectorWeight != nil {
+		vectorWeight = *opts.VectorWeight
+	}
+	if vectorWeight < 0 || vectorWeight > 1 {
+		return nil, fmt.Errorf("invalid vector weight %v: must be between 0 and 1", vectorWeight)
+	}
+	if opts.RRFConstant == 0 {
+		opts.RRFConstant = defaultRRFConstant
+	}
+	return &Hybrid{
+		store:        store,
+		vector:       FromVectorStore(store, embeddings),
+		keyword:      keyword,
+		opts:         opts,
+		vectorWeight: vectorWeight,
+	}, nil
+}
+
+// AddDocuments adds the documents to the vector store, and to the keyword retriever if it
+// supports adding documents, like the BM25 index.
+func (h *Hybrid) AddDocuments(ctx context.Context, documents ...flowllm.Document) error {
+	if err := h.store.AddDocuments(ctx, documents...); err != nil {
+		return err
+	}
+	if adder, ok := h.keyword.(interface {
+		AddDocuments(context.Context, ...flowllm.Document) error
+	}); ok {
+		return adder.AddDocuments(ctx, documents...)
+	}
+	return nil
+}
+
+// Retrieve returns the k most relevant documents for the query, combining the results of the
+// vector and keyword searches.
+func (h *Hybrid) Retrieve(ctx context.Context, query string, k int) ([]flowllm.ScoredDocument, error) {
+	fetchK := h.opts.FetchK
+	if fetchK == 0 {
+		fetchK = k * defaultFetchFactor
+	}
+	vectorResults, err := h.vector.Retrieve(ctx, query, fetchK)
+	if err != nil {
+		return nil, err
+	}
+	keywordResults, err := h.keyword.Retrieve(ctx, query, fetchK)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []flowllm.ScoredDocument
+	switch h.opts.Method {
+	case WeightedScores:
+		results = fuse(weightedScores, vectorResults, keywordResults, h.vectorWeight)
+	default:
+		rrf := func(docs []flowllm.ScoredDocument) []float64 {
+			scores := make([]float64, len(docs))
+			for i := range docs {
+				scores[i] = 1 / float64(h.opts.RRFConstant+i+1)
+			}
+			return scores
+		}
+		results = fuse(rrf, vectorResults, keywordResults, h.vectorWeight)
+	}
+	if k < len(results) {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// fuse combines the two lists of results, using the scoreFunc to calculate the score of each
+// document in each list, and weighting the scores of each list.
+func fuse(scoreFunc func([]flowllm.ScoredDocument) []float64, vectorResults, keywordResults []flowllm.ScoredDocument,
+	vectorWeight float64) []flowllm.ScoredDocument {
+	scores := map[string]float64{}
+	docs := map[string]flowllm.Document{}
+	var keys []string
+	add := func(results []flowllm.ScoredDocument, weight float64) {
+		for i, score := range scoreFunc(results) {
+			key := results[i].PageContent
+			if _, ok := docs[key]; !ok {
+				docs[key] = results[i].Document
+				keys = append(keys, key)
+			}
+			scores[key] += weight * score
+		}
+	}
+	add(vectorResults, vectorWeight)
+	add(keywordResults, 1-vectorWeight)
+
+	results := make([]flowllm.ScoredDocument, len(keys))
+	for i, key := range keys {
+		results[i] = flowllm.ScoredDocument{Document: docs[key], Score: float32(scores[key])}
+	}
+	sortByScore(results)
+	return results
+}
+
+// weightedScores normalizes the scores of the documents to the range [0, 1], using min-max normalization.
+func weightedScores(docs []flowllm.ScoredDocument) []float64 {
+	scores := make([]float64, len(docs))
+	if len(docs) == 0 {
+		return scores
+	}
+	minScore, maxScore := docs[0].Score, docs[0].Score
+	for _, doc := range docs {
+		if doc.Score < minScore {
+			minScore = doc.Score
+		}
+		if doc.Score > maxScore {
+			maxScore = doc.Score
+		}
+	}
+	for i, doc := range docs {
+		if maxScore == minScore {
+			scores[i] = 1
+			continue
+		}
+		scores[i] = float64((doc.Score - minScore) / (maxScore - minScore))
+	}
+	return scores
+}
@@ -0,0 +1,104 @@
+package retrievers_test
+
+import (
+	"context"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/retrievers"
+	"github.com/deluan/flowllm/vectorstores"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hybrid", func() {
+	var (
+		ctx   context.Context
+		store *vectorstores.Memory
+		index *retrievers.BM25
+		docs  []flowllm.Document
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		store = vectorstores.NewMemoryVectorStore(&fakeEmbeddings{})
+		var err error
+		index, err = retrievers.NewBM25(retrievers.BM25Options{})
+		Expect(err).ToNot(HaveOccurred())
+		docs = []flowllm.Document{
+			{PageContent: "Apple is a fruit"},
+			{PageContent: "Banana is a fruit"},
+			{PageContent: "Phone model ZX-81 is sold out"},
+			{PageContent: "Laptop model ZX-99 is in stock"},
+		}
+	})
+
+	DescribeTable("combines the vector and keyword results",
+		func(method retrievers.FusionMethod) {
+			hybrid, err := retrievers.NewHybrid(store, &fakeEmbeddings{}, index, retrievers.HybridOptions{Method: method})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hybrid.AddDocuments(ctx, docs...)).To(Succeed())
+
+			// The embeddings only know about phones, the keyword index finds the product code
+			results, err := hybrid.Retrieve(ctx, "phone ZX-99", 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect([]string{results[0].PageContent, results[1].PageContent}).To(ConsistOf(
+				"Phone model ZX-81 is sold out",
+				"Laptop model ZX-99 is in stock",
+			))
+			Expect(results[0].Score).To(BeNumerically(">=", results[1].Score))
+		},
+		Entry("Reciprocal Rank Fusion", retrievers.ReciprocalRankFusion),
+		Entry("Weighted Scores", retrievers.WeightedScores),
+	)
+
+	weight := func(w float64) *float64 { return &w }
+
+	It("gives precedence to the retriever with the highest weight", func() {
+		hybrid, err := retrievers.NewHybrid(store, &fakeEmbeddings{}, index, retrievers.HybridOptions{VectorWeight: weight(0.9)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hybrid.AddDocuments(ctx, docs...)).To(Succeed())
+
+		results, err := hybrid.Retrieve(ctx, "banana ZX-99", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results[0].PageContent).To(Equal("Banana is a fruit"))
+
+		hybrid, err = retrievers.NewHybrid(store, &fakeEmbeddings{}, index, retrievers.HybridOptions{VectorWeight: weight(0.1)})
+		Expect(err).ToNot(HaveOccurred())
+		results, err = hybrid.Retrieve(ctx, "banana ZX-99", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results[0].PageContent).To(Equal("Laptop model ZX-99 is in stock"))
+	})
+
+	It("uses only the keyword results with a zero vector weight", func() {
+		hybrid, err := retrievers.NewHybrid(store, &fakeEmbeddings{}, index, retrievers.HybridOptions{
+			Method: retrievers.WeightedScores, VectorWeight: weight(0),
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hybrid.AddDocuments(ctx, docs...)).To(Succeed())
+
+		results, err := hybrid.Retrieve(ctx, "banana ZX-99", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results[0].PageContent).To(Equal("Laptop model ZX-99 is in stock"))
+	})
+
+	It("rejects vector weights outside of [0, 1]", func() {
+		_, err := retrievers.NewHybrid(store, &fakeEmbeddings{}, index, retrievers.HybridOptions{VectorWeight: weight(1.5)})
+		Expect(err).To(MatchError(ContainSubstring("invalid vector weight")))
+		_, err = retrievers.NewHybrid(store, &fakeEmbeddings{}, index, retrievers.HybridOptions{VectorWeight: weight(-0.1)})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("accepts any retriever as keyword retriever", func() {
+		keyword := flowllm.RetrieverFunc(func(context.Context, string, int) ([]flowllm.ScoredDocument, error) {
+			return []flowllm.ScoredDocument{{Document: docs[3], Score: 10}}, nil
+		})
+		hybrid, err := retrievers.NewHybrid(store, &fakeEmbeddings{}, keyword, retrievers.HybridOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hybrid.AddDocuments(ctx, docs...)).To(Succeed())
+
+		results, err := hybrid.Retrieve(ctx, "laptop", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results[0].PageContent).To(Equal("Laptop model ZX-99 is in stock"))
+	})
+})
@@ -0,0 +1,199 @@
+package retrievers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/pl"
+)
+
+// LLMRerankMode is the strategy used by the LLMReranker to score the documents.
+type LLMRerankMode string
+
+const (
+	// Pointwise asks the model to score the relevance of each document independently. It makes one
+	// call per document, which can be executed in parallel.
+	Pointwise LLMRerankMode = "pointwise"
+	// Listwise asks the model to sort all documents at once, in a single call. The documents must
+	// fit in the model's context.
+	Listwise LLMRerankMode = "listwise"
+)
+
+const (
+	// DefaultPointwisePrompt is the default prompt used in Pointwise mode. It has access to the
+	// variables {query} and {document}.
+	DefaultPointwisePrompt = flowllm.Template(`On a scale of 0 to 10, how relevant is the following document to the query?
+Answer only with the number.
+
+Query: {query}
+
+Document: {document}
+
+Relevance:`)
+
+	// DefaultListwisePrompt is the default prompt used in Listwise mode. It has access to the
+	// variables {query}, {documents} and {count}.
+	DefaultListwisePrompt = flowllm.Template(`The following are {count} documents, each indicated by a number identifier [].
+
+{documents}
+
+Rank the documents above based on their relevance to the query: {query}
+All the documents should be included and listed using identifiers, in descending order of relevance.
+Answer only with the ranking, using the format [2] > [1] > [3].
+
+Ranking:`)
+
+	defaultRerankMaxParallel = 4
+)
+
+// LLMRerankerOptions for the LLMReranker
+type LLMRerankerOptions struct {
+	// Mode is the strategy used to score the documents. Defaults to Pointwise
+	Mode LLMRerankMode
+	// Prompt used to ask the model. Defaults to DefaultPointwisePrompt or DefaultListwisePrompt,
+	// depending on the Mode
+	Prompt flowllm.Template
+	// MaxParallel is the maximum number of concurrent calls to the model in Pointwise mode. Defaults to 4
+	MaxParallel int
+}
+
+// LLMReranker is a Reranker that uses a LanguageModel to judge the relevance of the documents.
+type LLMReranker struct {
+	model flowllm.LanguageModel
+	opts  LLMRerankerOptions
+}
+
+// NewLLMReranker creates a new LLMReranker.
+func NewLLMReranker(model flowllm.LanguageModel, opts LLMRerankerOptions) *LLMReranker {
+	if opts.Mode == "" {
+		opts.Mode = Pointwise
+	}
+	if opts.Prompt == "" {
+		opts.Prompt = DefaultPointwisePrompt
+		if opts.Mode == Listwise {
+			opts.Prompt = DefaultListwisePrompt
+		}
+	}
+	if opts.MaxParallel == 0 {
+		opts.MaxParallel = defaultRerankMaxParallel
+	}
+	return &LLMReranker{model: model, opts: opts}
+}
+
+// Rerank returns the documents sorted by the relevance judged by the model. In Pointwise mode, the
+// scores are the model's answers normalized to the range [0, 1]. In Listwise mode, the scores are
+// derived from the position of the documents in the ranking.
+func (r *LLMReranker) Rerank(ctx context.Context, query string, docs []flowllm.ScoredDocument) ([]flowllm.ScoredDocument, error) {
+	if r.opts.Mode == Listwise {
+		return r.listwise(ctx, query, docs)
+	}
+	return r.pointwise(ctx, query, docs)
+}
+
+func (r *LLMReranker) call(ctx context.Context, values flowllm.Values) (string, error) {
+	prompt, err := r.opts.Prompt.Call(ctx, values)
+	if err != nil {
+		return "", err
+	}
+	return r.model.Call(ctx, prompt.Get(flowllm.DefaultKey))
+}
+
+var firstNumber = regexp.MustCompile(`-?\d+(\.\d+)?`)
+
+type pointwiseScore struct {
+	idx   int
+	score float32
+}
+
+func (r *LLMReranker) pointwise(ctx context.Context, query string, docs []flowllm.ScoredDocument) ([]flowllm.ScoredDocument, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indexes := make([]int, len(docs))
+	for i := range docs {
+		indexes[i] = i
+	}
+	resC, errC := pl.Stage(ctx, r.opts.MaxParallel, pl.FromSlice(ctx, indexes), func(ctx context.Context, idx int) (pointwiseScore, error) {
+		answer, err := r.call(ctx, flowllm.Values{"query": query, "document": docs[idx].PageContent})
+		if err != nil {
+			return pointwiseScore{}, err
+		}
+		num := firstNumber.FindString(answer)
+		score, err := strconv.ParseFloat(num, 32)
+		if err != nil {
+			return pointwiseScore{}, fmt.Errorf("invalid relevance score %q: %w", answer, err)
+		}
+		return pointwiseScore{idx: idx, score: float32(score / 10)}, nil
+	})
+
+	// Both channels are drained until the pipeline closes them, so no worker is left blocked. The
+	// first error cancels the context, stopping the calls that have not started yet
+	finalErrC := make(chan error, 1)
+	go func() {
+		var firstErr error
+		for err := range errC {
+			if err != nil && firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+		}
+		finalErrC <- firstErr
+	}()
+
+	results := make([]flowllm.ScoredDocument, len(docs))
+	copy(results, docs)
+	for res := range resC {
+		results[res.idx].Score = res.score
+	}
+	if err := <-finalErrC; err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sortByScore(results)
+	return results, nil
+}
+
+var rankingIdentifier = regexp.MustCompile(`\[(\d+)]`)
+
+func (r *LLMReranker) listwise(ctx context.Context, query string, docs []flowllm.ScoredDocument) ([]flowllm.ScoredDocument, error) {
+	var list strings.Builder
+	for i, doc := range docs {
+		list.WriteString(fmt.Sprintf("[%d] %s\n", i+1, doc.PageContent))
+	}
+	answer, err := r.call(ctx, flowllm.Values{"query": query, "documents": list.String(), "count": len(docs)})
+	if err != nil {
+		return nil, err
+	}
+
+	// Documents are added in the order returned by the model, ignoring invalid and repeated
+	// identifiers. Documents missing from the answer are added at the end, in their original order
+	var ranking []int
+	seen := map[int]bool{}
+	for _, match := range rankingIdentifier.FindAllStringSubmatch(answer, -1) {
+		idx, _ := strconv.Atoi(match[1])
+		idx--
+		if idx < 0 || idx >= len(docs) || seen[idx] {
+			continue
+		}
+		seen[idx] = true
+		ranking = append(ranking, idx)
+	}
+	for i := range docs {
+		if !seen[i] {
+			ranking = append(ranking, i)
+		}
+	}
+
+	results := make([]flowllm.ScoredDocument, len(docs))
+	for pos, idx := range ranking {
+		results[pos] = docs[idx]
+		results[pos].Score = float32(len(docs)-pos) / float32(len(docs))
+	}
+	return results, nil
+}
@@ -0,0 +1,121 @@
+package retrievers_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/retrievers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LLMReranker", func() {
+	var (
+		ctx  context.Context
+		docs []flowllm.ScoredDocument
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		docs = []flowllm.ScoredDocument{
+			{Document: flowllm.Document{PageContent: "Apples are red"}, Score: 0.9},
+			{Document: flowllm.Document{PageContent: "Bananas are yellow"}, Score: 0.8},
+			{Document: flowllm.Document{PageContent: "Phones are expensive"}, Score: 0.7},
+		}
+	})
+
+	Describe("Pointwise", func() {
+		It("scores each document with the model", func() {
+			model := &fakeRerankModel{answer: func(prompt string) string {
+				switch {
+				case strings.Contains(prompt, "Bananas"):
+					return "9"
+				case strings.Contains(prompt, "Phones"):
+					return " 5.5, somewhat relevant"
+				default:
+					return "1"
+				}
+			}}
+			reranker := retrievers.NewLLMReranker(model, retrievers.LLMRerankerOptions{})
+
+			results, err := reranker.Rerank(ctx, "yellow fruit", docs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(model.prompts).To(HaveLen(3))
+			Expect(model.prompts[0]).To(ContainSubstring("Query: yellow fruit"))
+
+			Expect(results).To(HaveLen(3))
+			Expect(results[0].PageContent).To(Equal("Bananas are yellow"))
+			Expect(results[0].Score).To(BeNumerically("~", 0.9, 0.0001))
+			Expect(results[1].PageContent).To(Equal("Phones are expensive"))
+			Expect(results[1].Score).To(BeNumerically("~", 0.55, 0.0001))
+			Expect(results[2].PageContent).To(Equal("Apples are red"))
+		})
+
+		It("returns an error if the answer is not a number", func() {
+			model := &fakeRerankModel{answer: func(string) string { return "very relevant" }}
+			reranker := retrievers.NewLLMReranker(model, retrievers.LLMRerankerOptions{MaxParallel: 1})
+
+			_, err := reranker.Rerank(ctx, "yellow fruit", docs)
+			Expect(err).To(MatchError(ContainSubstring("invalid relevance score")))
+		})
+
+		It("returns the errors from the model", func() {
+			model := &fakeRerankModel{err: errors.New("model failed")}
+			reranker := retrievers.NewLLMReranker(model, retrievers.LLMRerankerOptions{})
+
+			_, err := reranker.Rerank(ctx, "yellow fruit", docs)
+			Expect(err).To(MatchError("model failed"))
+		})
+	})
+
+	Describe("Listwise", func() {
+		It("sorts the documents using the ranking returned by the model", func() {
+			model := &fakeRerankModel{answer: func(string) string { return "[2] > [3] > [1]" }}
+			reranker := retrievers.NewLLMReranker(model, retrievers.LLMRerankerOptions{Mode: retrievers.Listwise})
+
+			results, err := reranker.Rerank(ctx, "yellow fruit", docs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(model.prompts).To(HaveLen(1))
+			Expect(model.prompts[0]).To(ContainSubstring("[1] Apples are red\n[2] Bananas are yellow\n[3] Phones are expensive"))
+
+			Expect(results).To(HaveLen(3))
+			Expect(results[0].PageContent).To(Equal("Bananas are yellow"))
+			Expect(results[1].PageContent).To(Equal("Phones are expensive"))
+			Expect(results[2].PageContent).To(Equal("Apples are red"))
+			Expect(results[0].Score).To(BeNumerically(">", results[1].Score))
+			Expect(results[1].Score).To(BeNumerically(">", results[2].Score))
+		})
+
+		It("keeps documents missing from the ranking at the end", func() {
+			model := &fakeRerankModel{answer: func(string) string { return "[3] > [7] > [3]" }}
+			reranker := retrievers.NewLLMReranker(model, retrievers.LLMRerankerOptions{Mode: retrievers.Listwise})
+
+			results, err := reranker.Rerank(ctx, "phones", docs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(3))
+			Expect(results[0].PageContent).To(Equal("Phones are expensive"))
+			Expect(results[1].PageContent).To(Equal("Apples are red"))
+			Expect(results[2].PageContent).To(Equal("Bananas are yellow"))
+		})
+	})
+})
+
+type fakeRerankModel struct {
+	mu      sync.Mutex
+	prompts []string
+	answer  func(prompt string) string
+	err     error
+}
+
+func (f *fakeRerankModel) Call(_ context.Context, input string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prompts = append(f.prompts, input)
+	if f.err != nil {
+		return "", f.err
+	}
+	return f.answer(input), nil
+}
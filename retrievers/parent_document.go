@@ -0,0 +1,120 @@
+package retrievers
+
+import (
+	"context"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	"github.com/deluan/flowllm/vectorstores"
+)
+
+const defaultChildChunkSize = 100
+
+// ParentDocumentOptions for the ParentDocument retriever
+type ParentDocumentOptions struct {
+	// ChildSplitter splits the parent documents into the chunks indexed in the vector store.
+	// Defaults to a RecursiveTextSplitter with ChunkSize 100
+	ChildSplitter flowllm.Splitter
+	// ParentSplitter, if set, splits the documents added to the retriever into the parent documents.
+	// Use it to return sections of large documents instead of the full documents
+	ParentSplitter flowllm.Splitter
+	// FetchK is the number of chunks fetched from the vector store. As many chunks can belong
+	// to the same parent, it should be larger than the number of documents requested.
+	// Defaults to 4 times the number of documents requested
+	FetchK int
+}
+
+// ParentDocument is a retriever that indexes small chunks of the documents in a vector store, but
+// returns the documents they were split from. Small chunks produce more precise embeddings, while
+// the full parent documents give more context to the language model.
+//
+// The parent documents are kept in a DocStore, and each chunk records the ID of its parent in the
+// metadata, under the loaders.ParentIDKey.
+type ParentDocument struct {
+	store      flowllm.VectorStore
+	embeddings flowllm.Embeddings
+	docstore   DocStore
+	opts       ParentDocumentOptions
+}
+
+// NewParentDocument creates a new ParentDocument retriever.
+func NewParentDocument(store flowllm.VectorStore, embeddings flowllm.Embeddings, docstore DocStore, opts ParentDocumentOptions) *ParentDocument {
+	if opts.ChildSplitter == nil {
+		opts.ChildSplitter = flowllm.RecursiveTextSplitter(flowllm.SplitterOptions{ChunkSize: defaultChildChunkSize})
+	}
+	return &ParentDocument{
+		store:      store,
+		embeddings: embeddings,
+		docstore:   docstore,
+		opts:       opts,
+	}
+}
+
+// AddDocuments stores the documents (or their sections, if a ParentSplitter is set) in the DocStore,
+// and adds their chunks to the vector store. Documents without ID get one derived from their
+// content and metadata.
+func (p *ParentDocument) AddDocuments(ctx context.Context, documents ...flowllm.Document) error {
+	parents := documents
+	if p.opts.ParentSplitter != nil {
+		var err error
+		parents, err = loaders.SplitDocuments(p.opts.ParentSplitter, documents)
+		if err != nil {
+			return err
+		}
+	} else {
+		parents = make([]flowllm.Document, len(documents))
+		copy(parents, documents)
+	}
+	for i := range parents {
+		parents[i].ID = vectorstores.DocumentID(parents[i])
+	}
+
+	children, err := loaders.SplitDocuments(p.opts.ChildSplitter, parents)
+	if err != nil {
+		return err
+	}
+	if err := p.docstore.Set(ctx, parents...); err != nil {
+		return err
+	}
+	return p.store.AddDocuments(ctx, children...)
+}
+
+// Retrieve searches the chunks most similar to the query, and returns up to k distinct parent
+// documents they belong to. The score of each parent is the score of its best matching chunk.
+func (p *ParentDocument) Retrieve(ctx context.Context, query string, k int) ([]flowllm.ScoredDocument, error) {
+	fetchK := p.opts.FetchK
+	if fetchK == 0 {
+		fetchK = k * defaultFetchFactor
+	}
+	children, err := FromVectorStore(p.store, p.embeddings).Retrieve(ctx, query, fetchK)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	scores := map[string]float32{}
+	for _, child := range children {
+		id, _ := child.Metadata[loaders.ParentIDKey].(string)
+		if id == "" {
+			continue
+		}
+		if _, ok := scores[id]; ok {
+			continue
+		}
+		scores[id] = child.Score
+		ids = append(ids, id)
+		if len(ids) == k {
+			break
+		}
+	}
+
+	parents, err := p.docstore.Get(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]flowllm.ScoredDocument, len(parents))
+	for i, parent := range parents {
+		results[i] = flowllm.ScoredDocument{Document: parent, Score: scores[parent.ID]}
+	}
+	return results, nil
+}
@@ -0,0 +1,119 @@
+package retrievers_test
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/loaders"
+	"github.com/deluan/flowllm/retrievers"
+	"github.com/deluan/flowllm/vectorstores"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.etcd.io/bbolt"
+)
+
+var _ = Describe("ParentDocument", func() {
+	var (
+		ctx   context.Context
+		store *vectorstores.Memory
+		docs  []flowllm.Document
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		store = vectorstores.NewMemoryVectorStore(&fakeEmbeddings{})
+		docs = []flowllm.Document{
+			{PageContent: "Fruits are good for you.\n\nAn apple a day keeps the doctor away.\n\nA banana is a good snack.", Metadata: map[string]any{"source": "fruits.txt"}},
+			{PageContent: "Electronics are expensive.\n\nA new phone costs a lot.\n\nA laptop costs even more.", Metadata: map[string]any{"source": "electronics.txt"}},
+		}
+	})
+
+	newBoltDocStore := func() retrievers.DocStore {
+		db, err := bbolt.Open(filepath.Join(GinkgoT().TempDir(), "docstore.db"), 0600, nil)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(db.Close)
+		docstore, err := retrievers.NewBoltDocStore(db, "parents")
+		Expect(err).ToNot(HaveOccurred())
+		return docstore
+	}
+	newMemoryDocStore := func() retrievers.DocStore { return retrievers.NewMemoryDocStore() }
+
+	DescribeTable("returns the parent documents of the matching chunks",
+		func(newDocStore func() retrievers.DocStore) {
+			splitter := flowllm.RecursiveTextSplitter(flowllm.SplitterOptions{ChunkSize: 40})
+			retriever := retrievers.NewParentDocument(store, &fakeEmbeddings{}, newDocStore(), retrievers.ParentDocumentOptions{
+				ChildSplitter: splitter,
+			})
+			Expect(retriever.AddDocuments(ctx, docs...)).To(Succeed())
+
+			chunks, err := store.SimilaritySearch(ctx, "apple", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(chunks)).To(BeNumerically(">", 2))
+			Expect(chunks[0].PageContent).To(Equal("An apple a day keeps the doctor away."))
+			Expect(chunks[0].Metadata).To(HaveKey(loaders.ParentIDKey))
+
+			// Both "apple" and "banana" chunks belong to the same parent, which is returned only once
+			results, err := retriever.Retrieve(ctx, "apple banana", 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect(results[0].PageContent).To(Equal(docs[0].PageContent))
+			Expect(results[0].Metadata).To(HaveKeyWithValue("source", "fruits.txt"))
+			Expect(results[0].ID).ToNot(BeEmpty())
+			Expect(results[1].PageContent).To(Equal(docs[1].PageContent))
+			Expect(results[0].Score).To(BeNumerically(">", results[1].Score))
+		},
+		Entry("in-memory docstore", newMemoryDocStore),
+		Entry("Bolt docstore", newBoltDocStore),
+	)
+
+	It("splits the documents into parent sections with the ParentSplitter", func() {
+		retriever := retrievers.NewParentDocument(store, &fakeEmbeddings{}, retrievers.NewMemoryDocStore(), retrievers.ParentDocumentOptions{
+			ParentSplitter: flowllm.RecursiveTextSplitter(flowllm.SplitterOptions{ChunkSize: 50}),
+			ChildSplitter:  flowllm.RecursiveTextSplitter(flowllm.SplitterOptions{ChunkSize: 40}),
+		})
+		Expect(retriever.AddDocuments(ctx, docs...)).To(Succeed())
+
+		results, err := retriever.Retrieve(ctx, "laptop", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].PageContent).To(Equal("A new phone costs a lot.\n\nA laptop costs even more."))
+		Expect(results[0].Metadata).To(HaveKeyWithValue("source", "electronics.txt"))
+	})
+})
+
+var _ = Describe("DocStore", func() {
+	DescribeTable("stores and retrieves documents by ID",
+		func(newDocStore func() retrievers.DocStore) {
+			ctx := context.Background()
+			docstore := newDocStore()
+			Expect(docstore.Set(ctx,
+				flowllm.Document{ID: "1", PageContent: "first", Metadata: map[string]any{"page": "1"}},
+				flowllm.Document{ID: "2", PageContent: "second"},
+			)).To(Succeed())
+			Expect(docstore.Set(ctx, flowllm.Document{PageContent: "no id"})).ToNot(Succeed())
+
+			docs, err := docstore.Get(ctx, "2", "missing", "1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(docs).To(HaveLen(2))
+			Expect(docs[0].PageContent).To(Equal("second"))
+			Expect(docs[1].PageContent).To(Equal("first"))
+			Expect(docs[1].Metadata).To(HaveKeyWithValue("page", "1"))
+
+			Expect(docstore.Delete(ctx, "1")).To(Succeed())
+			docs, err = docstore.Get(ctx, "1", "2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(docs).To(HaveLen(1))
+			Expect(docs[0].ID).To(Equal("2"))
+		},
+		Entry("in-memory", func() retrievers.DocStore { return retrievers.NewMemoryDocStore() }),
+		Entry("Bolt", func() retrievers.DocStore {
+			db, err := bbolt.Open(filepath.Join(GinkgoT().TempDir(), "docstore.db"), 0600, nil)
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(db.Close)
+			docstore, err := retrievers.NewBoltDocStore(db, "docs")
+			Expect(err).ToNot(HaveOccurred())
+			return docstore
+		}),
+	)
+})
@@ -0,0 +1,69 @@
+package retrievers
+
+import (
+	"context"
+
+	"github.com/deluan/flowllm"
+	"golang.org/x/exp/slices"
+)
+
+// Reranker reorders a list of documents by their relevance to a query. Rerankers are usually
+// more expensive but more accurate than the similarity search used to fetch the documents,
+// so they are used as a second stage, on a small number of candidates.
+type Reranker interface {
+	// Rerank returns the documents sorted by their relevance to the query, with updated scores
+	Rerank(ctx context.Context, query string, docs []flowllm.ScoredDocument) ([]flowllm.ScoredDocument, error)
+}
+
+// RerankerFunc is an adapter to allow the use of ordinary functions as Rerankers.
+type RerankerFunc func(ctx context.Context, query string, docs []flowllm.ScoredDocument) ([]flowllm.ScoredDocument, error)
+
+func (f RerankerFunc) Rerank(ctx context.Context, query string, docs []flowllm.ScoredDocument) ([]flowllm.ScoredDocument, error) {
+	return f(ctx, query, docs)
+}
+
+// RerankOptions for the Rerank retriever
+type RerankOptions struct {
+	// FetchK is the number of candidates fetched from the wrapped retriever. Defaults to 4 times
+	// the number of documents requested
+	FetchK int
+	// TopN is the maximum number of documents returned after reranking. If not set, the number of
+	// documents requested is used
+	TopN int
+}
+
+// Rerank returns a Retriever that fetches candidates from the given retriever, reorders them with
+// the reranker, and returns only the top ones.
+func Rerank(retriever flowllm.Retriever, reranker Reranker, opts RerankOptions) flowllm.Retriever {
+	return flowllm.RetrieverFunc(func(ctx context.Context, query string, k int) ([]flowllm.ScoredDocument, error) {
+		fetchK := opts.FetchK
+		if fetchK == 0 {
+			fetchK = k * defaultFetchFactor
+		}
+		candidates, err := retriever.Retrieve(ctx, query, fetchK)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) == 0 {
+			return candidates, nil
+		}
+		results, err := reranker.Rerank(ctx, query, candidates)
+		if err != nil {
+			return nil, err
+		}
+		if opts.TopN > 0 && opts.TopN < k {
+			k = opts.TopN
+		}
+		if k < len(results) {
+			results = results[:k]
+		}
+		return results, nil
+	})
+}
+
+// sortByScore sorts the documents by score, keeping the original order of documents with the same score.
+func sortByScore(docs []flowllm.ScoredDocument) {
+	slices.SortStableFunc(docs, func(a, b flowllm.ScoredDocument) bool {
+		return a.Score > b.Score
+	})
+}
@@ -0,0 +1,73 @@
+package retrievers_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/retrievers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rerank", func() {
+	var (
+		ctx       context.Context
+		fetched   int
+		retriever flowllm.Retriever
+		reverse   retrievers.Reranker
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fetched = 0
+		retriever = flowllm.RetrieverFunc(func(_ context.Context, _ string, k int) ([]flowllm.ScoredDocument, error) {
+			fetched = k
+			var docs []flowllm.ScoredDocument
+			for i := 0; i < k && i < 10; i++ {
+				docs = append(docs, flowllm.ScoredDocument{
+					Document: flowllm.Document{PageContent: strings.Repeat("a", i+1)},
+					Score:    float32(10 - i),
+				})
+			}
+			return docs, nil
+		})
+		// Gives the highest score to the longest documents
+		reverse = retrievers.RerankerFunc(func(_ context.Context, _ string, docs []flowllm.ScoredDocument) ([]flowllm.ScoredDocument, error) {
+			results := make([]flowllm.ScoredDocument, len(docs))
+			for i, doc := range docs {
+				results[len(docs)-i-1] = flowllm.ScoredDocument{Document: doc.Document, Score: float32(len(doc.PageContent))}
+			}
+			return results, nil
+		})
+	})
+
+	It("fetches more candidates and returns the top k reranked documents", func() {
+		r := retrievers.Rerank(retriever, reverse, retrievers.RerankOptions{})
+		results, err := r.Retrieve(ctx, "query", 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fetched).To(Equal(8))
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].PageContent).To(Equal("aaaaaaaa"))
+		Expect(results[1].PageContent).To(Equal("aaaaaaa"))
+	})
+
+	It("respects the FetchK and TopN options", func() {
+		r := retrievers.Rerank(retriever, reverse, retrievers.RerankOptions{FetchK: 5, TopN: 1})
+		results, err := r.Retrieve(ctx, "query", 3)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fetched).To(Equal(5))
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].PageContent).To(Equal("aaaaa"))
+	})
+
+	It("returns the errors from the reranker", func() {
+		failing := retrievers.RerankerFunc(func(context.Context, string, []flowllm.ScoredDocument) ([]flowllm.ScoredDocument, error) {
+			return nil, errors.New("rerank failed")
+		})
+		r := retrievers.Rerank(retriever, failing, retrievers.RerankOptions{})
+		_, err := r.Retrieve(ctx, "query", 2)
+		Expect(err).To(MatchError("rerank failed"))
+	})
+})
@@ -0,0 +1,21 @@
+// Package retrievers implements different strategies to fetch the documents most relevant to a query,
+// combining vector stores, keyword indexes and language models.
+package retrievers
+
+import (
+	"context"
+
+	"github.com/deluan/flowllm"
+)
+
+// FromVectorStore returns a Retriever that embeds the query and searches for the most similar
+// documents in the given vector store.
+func FromVectorStore(store flowllm.VectorStore, embeddings flowllm.Embeddings) flowllm.Retriever {
+	return flowllm.RetrieverFunc(func(ctx context.Context, query string, k int) ([]flowllm.ScoredDocument, error) {
+		vector, err := embeddings.EmbedString(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		return store.SimilaritySearchVectorWithScore(ctx, vector, k)
+	})
+}
@@ -0,0 +1,146 @@
+package retrievers
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/vectorstores"
+	"golang.org/x/exp/slices"
+)
+
+// LastAccessedAtKey is the metadata key where the TimeWeighted retriever keeps the last time a
+// document was retrieved, formatted as RFC 3339.
+const LastAccessedAtKey = "last_accessed_at"
+
+const defaultDecayRate = 0.01
+
+// UpdatableVectorStore is a VectorStore that can update documents by their ID. Documents returned by
+// its searches must have their IDs set.
+type UpdatableVectorStore interface {
+	flowllm.VectorStore
+	// UpdateDocuments replaces the content and metadata of the documents with the same IDs
+	UpdateDocuments(ctx context.Context, documents ...flowllm.Document) error
+}
+
+// TimeWeightedOptions for the TimeWeighted retriever
+type TimeWeightedOptions struct {
+	// DecayRate is the fraction of the recency score lost per hour since the document was last
+	// accessed, between 0 and 1. Defaults to 0.01
+	DecayRate float64
+	// FetchK is the number of documents fetched from the vector store before combining their
+	// scores. Defaults to 4 times the number of documents requested
+	FetchK int
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// TimeWeighted is a retriever that combines the similarity of the documents with how recently they
+// were accessed. It is useful as a long-term memory, where frequently used memories should stay
+// relevant and the unused ones should fade away. The score of each document is:
+//
+//	similarity + (1 - DecayRate) ^ hoursSinceLastAccess
+//
+// The last access time is kept in the metadata of the documents, under the LastAccessedAtKey, and
+// it is updated in the vector store every time a document is retrieved.
+type TimeWeighted struct {
+	store      UpdatableVectorStore
+	embeddings flowllm.Embeddings
+	opts       TimeWeightedOptions
+}
+
+// NewTimeWeighted creates a new TimeWeighted retriever.
+func NewTimeWeighted(store UpdatableVectorStore, embeddings flowllm.Embeddings, opts TimeWeightedOptions) *TimeWeighted {
+	if opts.DecayRate == 0 {
+		opts.DecayRate = defaultDecayRate
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &TimeWeighted{store: store, embeddings: embeddings, opts: opts}
+}
+
+// AddDocuments adds the documents to the vector store, setting their last access time to now.
+// Documents without ID get one derived from their content and metadata.
+func (t *TimeWeighted) AddDocuments(ctx context.Context, documents ...flowllm.Document) error {
+	now := t.opts.Now().Format(time.RFC3339Nano)
+	documents = slices.Clone(documents)
+	for i, doc := range documents {
+		documents[i].ID = vectorstores.DocumentID(doc)
+		documents[i].Metadata = withMetadata(doc.Metadata, LastAccessedAtKey, now)
+	}
+	return t.store.AddDocuments(ctx, documents...)
+}
+
+// Retrieve returns the k documents with the highest combined score for the query, and updates
+// their last access time.
+func (t *TimeWeighted) Retrieve(ctx context.Context, query string, k int) ([]flowllm.ScoredDocument, error) {
+	fetchK := t.opts.FetchK
+	if fetchK == 0 {
+		fetchK = k * defaultFetchFactor
+	}
+	candidates, err := FromVectorStore(t.store, t.embeddings).Retrieve(ctx, query, fetchK)
+	if err != nil {
+		return nil, err
+	}
+
+	now := t.opts.Now()
+	results := make([]flowllm.ScoredDocument, len(candidates))
+	for i, doc := range candidates {
+		results[i] = doc
+		results[i].Score = doc.Score + float32(t.recency(now, doc.Metadata[LastAccessedAtKey]))
+	}
+	sortByScore(results)
+	if k < len(results) {
+		results = results[:k]
+	}
+
+	accessedAt := now.Format(time.RFC3339Nano)
+	var updated []flowllm.Document
+	for i := range results {
+		results[i].Metadata = withMetadata(results[i].Metadata, LastAccessedAtKey, accessedAt)
+		if results[i].ID != "" {
+			updated = append(updated, results[i].Document)
+		}
+	}
+	if len(updated) > 0 {
+		if err := t.store.UpdateDocuments(ctx, updated...); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// recency returns the recency score of a document, given its last access time. Documents without
+// a valid last access time get a score of 0.
+func (t *TimeWeighted) recency(now time.Time, lastAccessed any) float64 {
+	var accessedAt time.Time
+	switch v := lastAccessed.(type) {
+	case time.Time:
+		accessedAt = v
+	case string:
+		var err error
+		accessedAt, err = time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return 0
+		}
+	default:
+		return 0
+	}
+	hours := now.Sub(accessedAt).Hours()
+	if hours < 0 {
+		hours = 0
+	}
+	return math.Pow(1-t.opts.DecayRate, hours)
+}
+
+// withMetadata returns a copy of the metadata with the key set to the value.
+func withMetadata(metadata map[string]any, key string, value any) map[string]any {
+	result := make(map[string]any, len(metadata)+1)
+	for k, v := range metadata {
+		result[k] = v
+	}
+	result[key] = value
+	return result
+}
@@ -0,0 +1,95 @@
+package retrievers_test
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/retrievers"
+	"github.com/deluan/flowllm/vectorstores"
+	"github.com/deluan/flowllm/vectorstores/bolt"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TimeWeighted", func() {
+	var (
+		ctx   context.Context
+		now   time.Time
+		clock func() time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+		clock = func() time.Time { return now }
+	})
+
+	newMemoryStore := func() retrievers.UpdatableVectorStore {
+		return vectorstores.NewMemoryVectorStore(&fakeEmbeddings{})
+	}
+	newBoltStore := func() retrievers.UpdatableVectorStore {
+		store, closeDB, err := bolt.NewVectorStore(&fakeEmbeddings{}, bolt.Options{Path: filepath.Join(GinkgoT().TempDir(), "vs.db")})
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(closeDB)
+		return store
+	}
+
+	DescribeTable("favors the most recently accessed documents",
+		func(newStore func() retrievers.UpdatableVectorStore) {
+			store := newStore()
+			retriever := retrievers.NewTimeWeighted(store, &fakeEmbeddings{}, retrievers.TimeWeightedOptions{Now: clock})
+
+			Expect(retriever.AddDocuments(ctx, flowllm.Document{PageContent: "I ate an apple", Metadata: map[string]any{"day": "1"}})).To(Succeed())
+			now = now.Add(100 * time.Hour)
+			Expect(retriever.AddDocuments(ctx, flowllm.Document{PageContent: "I bought an apple"})).To(Succeed())
+
+			// Both documents are equally similar to the query, the most recent one comes first
+			results, err := retriever.Retrieve(ctx, "apple", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].PageContent).To(Equal("I bought an apple"))
+
+			results, err = retriever.Retrieve(ctx, "apple", 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect(results[1].PageContent).To(Equal("I ate an apple"))
+			Expect(results[0].Score - results[1].Score).To(BeNumerically("~", 1-math.Pow(0.99, 100), 0.0001))
+
+			// Retrieving the documents updates their last access time in the store
+			docs, err := store.SimilaritySearch(ctx, "apple", 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(docs).To(HaveLen(2))
+			for _, doc := range docs {
+				Expect(doc.Metadata).To(HaveKeyWithValue(retrievers.LastAccessedAtKey, now.Format(time.RFC3339Nano)))
+			}
+			Expect(docs).To(ContainElement(HaveField("Metadata", HaveKeyWithValue("day", "1"))))
+
+			// Now the first document was accessed as recently as the second
+			now = now.Add(time.Hour)
+			results, err = retriever.Retrieve(ctx, "apple", 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results[0].Score).To(BeNumerically("~", results[1].Score, 0.0001))
+		},
+		Entry("Memory", newMemoryStore),
+		Entry("Bolt", newBoltStore),
+	)
+
+	It("combines similarity with recency", func() {
+		store := vectorstores.NewMemoryVectorStore(&fakeEmbeddings{})
+		retriever := retrievers.NewTimeWeighted(store, &fakeEmbeddings{}, retrievers.TimeWeightedOptions{Now: clock, DecayRate: 0.5})
+
+		Expect(retriever.AddDocuments(ctx, flowllm.Document{PageContent: "Apple is a fruit"})).To(Succeed())
+		now = now.Add(time.Hour)
+		Expect(retriever.AddDocuments(ctx, flowllm.Document{PageContent: "My phone is broken"})).To(Succeed())
+
+		results, err := retriever.Retrieve(ctx, "fruit apple", 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].PageContent).To(Equal("Apple is a fruit"))
+		Expect(results[0].Score).To(BeNumerically(">", 1.4))
+		Expect(results[1].Score).To(BeNumerically("<", 1.1))
+	})
+})
@@ -0,0 +1,181 @@
+package flowllm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// BreakpointThreshold is the method used by the SemanticSplitter to decide where to start a new chunk.
+type BreakpointThreshold string
+
+const (
+	// PercentileThreshold starts a new chunk where the similarity between neighbouring sentences
+	// is below the given percentile of all similarities in the text
+	PercentileThreshold BreakpointThreshold = "percentile"
+	// StdDevThreshold starts a new chunk where the similarity between neighbouring sentences is
+	// below the mean of all similarities in the text by more than the given number of standard
+	// deviations
+	StdDevThreshold BreakpointThreshold = "stddev"
+)
+
+var defaultThresholdAmounts = map[BreakpointThreshold]float64{
+	PercentileThreshold: 5,
+	StdDevThreshold:     3,
+}
+
+// SemanticSplitterOptions for the SemanticSplitter
+type SemanticSplitterOptions struct {
+	// Embeddings is used to embed the sentences of the text. Required
+	Embeddings Embeddings
+	// Threshold is the method used to find the breakpoints between chunks. Defaults to PercentileThreshold
+	Threshold BreakpointThreshold
+	// ThresholdAmount is the percentile (0-100) for the PercentileThreshold, or the number of
+	// standard deviations for the StdDevThreshold. Defaults to 5 and 3, respectively
+	ThresholdAmount *float64
+	// ChunkSize is the maximum size of each chunk. Chunks that are larger are split with the
+	// SentenceSplitter. Defaults to 1000
+	ChunkSize int
+	// LenFunc is the length function to be used to calculate the chunk size
+	LenFunc func(string) int
+	// Abbreviations is a list of abbreviations that do not end a sentence. Defaults to common
+	// English abbreviations
+	Abbreviations []string
+}
+
+// SemanticSplitter returns a ContextSplitter that splits a text into sentences, like the
+// SentenceSplitter, embeds them and starts a new chunk wherever the cosine similarity between
+// neighbouring sentences drops below the threshold. All sentences of the text are embedded with a
+// single call to EmbedStrings, and any error it returns is returned by the splitter. The splitter
+// also returns an error if the Threshold is not a known method.
+func SemanticSplitter(opts SemanticSplitterOptions) ContextSplitter {
+	if opts.Threshold == "" {
+		opts.Threshold = PercentileThreshold
+	}
+	amount, knownThreshold := defaultThresholdAmounts[opts.Threshold]
+	if opts.ThresholdAmount != nil {
+		amount = *opts.ThresholdAmount
+	}
+	if opts.ChunkSize == 0 {
+		opts.ChunkSize = defaultSplitterChunkSize
+	}
+	if opts.LenFunc == nil {
+		opts.LenFunc = defaultSplitterLenFunc
+	}
+	if opts.Abbreviations == nil {
+		opts.Abbreviations = defaultAbbreviations
+	}
+	abbreviations := make(map[string]bool, len(opts.Abbreviations))
+	for _, a := range opts.Abbreviations {
+		abbreviations[strings.ToLower(a)] = true
+	}
+	sizeSplitter := SentenceSplitter(SplitterOptions{
+		ChunkSize:     opts.ChunkSize,
+		LenFunc:       opts.LenFunc,
+		Abbreviations: opts.Abbreviations,
+	})
+
+	return func(ctx context.Context, text string) ([]string, error) {
+		if opts.Embeddings == nil {
+			return nil, errors.New("no embeddings set. Use SemanticSplitterOptions.Embeddings when creating the splitter")
+		}
+		if !knownThreshold {
+			return nil, fmt.Errorf("unknown breakpoint threshold %q", opts.Threshold)
+		}
+		var sentences []string
+		for _, s := range splitSentences(text, abbreviations) {
+			if strings.TrimSpace(s.text) != "" {
+				sentences = append(sentences, s.text)
+			}
+		}
+		if len(sentences) < 2 {
+			return sizeSplitter(text)
+		}
+
+		trimmed := make([]string, len(sentences))
+		for i, s := range sentences {
+			trimmed[i] = strings.TrimSpace(s)
+		}
+		vectors, err := opts.Embeddings.EmbedStrings(ctx, trimmed)
+		if err != nil {
+			return nil, err
+		}
+		if len(vectors) != len(sentences) {
+			return nil, errors.New("number of embeddings does not match the number of sentences")
+		}
+		similarities := make([]float64, len(vectors)-1)
+		for i := range similarities {
+			similarities[i] = cosineSimilarity(vectors[i], vectors[i+1])
+		}
+		threshold := breakpointThreshold(similarities, opts.Threshold, amount)
+
+		var chunks []string
+		var group strings.Builder
+		flush := func() error {
+			split, err := sizeSplitter(group.String())
+			if err != nil {
+				return err
+			}
+			chunks = append(chunks, split...)
+			group.Reset()
+			return nil
+		}
+		for i, s := range sentences {
+			if i > 0 && similarities[i-1] < threshold {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+			group.WriteString(s)
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		return chunks, nil
+	}
+}
+
+// breakpointThreshold returns the similarity below which a new chunk is started.
+func breakpointThreshold(similarities []float64, threshold BreakpointThreshold, amount float64) float64 {
+	if threshold == StdDevThreshold {
+		var mean, variance float64
+		for _, s := range similarities {
+			mean += s
+		}
+		mean /= float64(len(similarities))
+		for _, s := range similarities {
+			variance += (s - mean) * (s - mean)
+		}
+		variance /= float64(len(similarities))
+		return mean - amount*math.Sqrt(variance)
+	}
+
+	// Percentile with linear interpolation between the closest ranks
+	sorted := append([]float64(nil), similarities...)
+	sort.Float64s(sorted)
+	rank := amount / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	if lower < 0 {
+		return sorted[0]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	var p, p2, q2 float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		p += float64(a[i]) * float64(b[i])
+		p2 += float64(a[i]) * float64(a[i])
+		q2 += float64(b[i]) * float64(b[i])
+	}
+	if p2 == 0 || q2 == 0 {
+		return 0
+	}
+	return p / (math.Sqrt(p2) * math.Sqrt(q2))
+}
@@ -0,0 +1,111 @@
+package flowllm_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/deluan/flowllm"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SemanticSplitter", func() {
+	const text = "Cats purr when happy. Cats sleep a lot. Cars need fuel. Cars have wheels. Cars can be fast."
+	var embeddings *topicEmbeddings
+	amount := func(v float64) *float64 { return &v }
+
+	BeforeEach(func() {
+		embeddings = &topicEmbeddings{topics: []string{"Cats", "Cars"}}
+	})
+
+	It("starts a new chunk where the similarity is below the percentile", func() {
+		splitter := SemanticSplitter(SemanticSplitterOptions{Embeddings: embeddings})
+		chunks, err := splitter(context.Background(), text)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(chunks).To(Equal([]string{
+			"Cats purr when happy. Cats sleep a lot.",
+			"Cars need fuel. Cars have wheels. Cars can be fast.",
+		}))
+		Expect(embeddings.calls).To(Equal(1))
+	})
+
+	It("starts a new chunk where the similarity is below the standard deviation threshold", func() {
+		splitter := SemanticSplitter(SemanticSplitterOptions{
+			Embeddings: embeddings, Threshold: StdDevThreshold, ThresholdAmount: amount(1),
+		})
+		chunks, err := splitter(context.Background(), text)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(chunks).To(HaveLen(2))
+
+		splitter = SemanticSplitter(SemanticSplitterOptions{Embeddings: embeddings, Threshold: StdDevThreshold})
+		chunks, err = splitter(context.Background(), text)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(chunks).To(Equal([]string{text}))
+	})
+
+	It("accepts a ThresholdAmount of 0", func() {
+		splitter := SemanticSplitter(SemanticSplitterOptions{Embeddings: embeddings, ThresholdAmount: amount(0)})
+		chunks, err := splitter(context.Background(), text)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(chunks).To(Equal([]string{text}))
+	})
+
+	It("returns an error for unknown thresholds", func() {
+		splitter := SemanticSplitter(SemanticSplitterOptions{Embeddings: embeddings, Threshold: "median"})
+		_, err := splitter(context.Background(), text)
+		Expect(err).To(MatchError(`unknown breakpoint threshold "median"`))
+		Expect(embeddings.calls).To(BeZero())
+	})
+
+	It("splits the chunks larger than the ChunkSize", func() {
+		splitter := SemanticSplitter(SemanticSplitterOptions{Embeddings: embeddings, ChunkSize: 40})
+		chunks, err := splitter(context.Background(), text)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(chunks).To(Equal([]string{
+			"Cats purr when happy. Cats sleep a lot.",
+			"Cars need fuel. Cars have wheels.",
+			"Cars can be fast.",
+		}))
+	})
+
+	It("returns the errors of the embeddings", func() {
+		embeddings.err = errors.New("boom")
+		splitter := SemanticSplitter(SemanticSplitterOptions{Embeddings: embeddings})
+		_, err := splitter(context.Background(), text)
+		Expect(err).To(MatchError("boom"))
+	})
+})
+
+// topicEmbeddings embeds the texts as a one-hot vector of the first topic they contain.
+type topicEmbeddings struct {
+	topics []string
+	calls  int
+	err    error
+}
+
+func (t *topicEmbeddings) EmbedString(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := t.EmbedStrings(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func (t *topicEmbeddings) EmbedStrings(_ context.Context, texts []string) ([][]float32, error) {
+	t.calls++
+	if t.err != nil {
+		return nil, t.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float32, len(t.topics))
+		for j, topic := range t.topics {
+			if strings.Contains(text, topic) {
+				vectors[i][j] = 1
+				break
+			}
+		}
+	}
+	return vectors, nil
+}
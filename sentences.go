@@ -0,0 +1,117 @@
+package flowllm
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+var defaultAbbreviations = []string{
+	"mr.", "mrs.", "ms.", "dr.", "prof.", "sr.", "jr.", "st.", "mt.", "vs.", "e.g.", "i.e.", "cf.",
+	"inc.", "ltd.", "co.", "corp.", "no.", "nos.", "fig.", "figs.", "vol.", "approx.", "dept.", "gen.",
+	"col.", "lt.", "sgt.", "capt.", "gov.", "sen.", "rep.", "jan.", "feb.", "mar.", "apr.", "jun.",
+	"jul.", "aug.", "sep.", "sept.", "oct.", "nov.", "dec.",
+}
+
+// sentence is a sentence of a text, with the whitespace that follows it.
+type sentence struct {
+	text string
+	// paragraph is true if the sentence starts a paragraph
+	paragraph bool
+}
+
+// splitSentences splits the text into sentences, using the Unicode sentence boundary rules
+// (UAX #29). Sentences ending with one of the abbreviations (lowercase, with the period), or with
+// an initial ("J."), are joined with the next sentence. Single line breaks are considered spaces,
+// so that hard-wrapped lines are not split, and blank lines start a new paragraph. Concatenating
+// all the sentences returns the original text.
+func splitSentences(text string, abbreviations map[string]bool) []sentence {
+	prepared := joinLines(text)
+	var sentences []sentence
+	paragraph, merge := true, false
+	offset := 0
+	state := -1
+	for len(prepared) > 0 {
+		var s string
+		s, prepared, state = uniseg.FirstSentenceInString(prepared, state)
+		original := text[offset : offset+len(s)]
+		offset += len(s)
+
+		switch {
+		case len(sentences) > 0 && (merge || strings.TrimSpace(s) == ""):
+			sentences[len(sentences)-1].text += original
+		default:
+			sentences = append(sentences, sentence{text: original, paragraph: paragraph})
+		}
+		// Line breaks left in the prepared text are from blank lines
+		paragraph = strings.ContainsAny(s, "\n\r ")
+		merge = !paragraph && endsWithAbbreviation(s, abbreviations)
+	}
+	return sentences
+}
+
+// joinLines replaces the single line breaks in the text with spaces, keeping the line breaks of
+// blank lines. The returned text has the same length as the original.
+func joinLines(text string) string {
+	b := []byte(text)
+	isBlank := func(c byte) bool { return c == ' ' || c == '\t' || c == '\r' }
+	for i, c := range b {
+		if c != '\n' {
+			continue
+		}
+		prev := i - 1
+		for prev >= 0 && isBlank(b[prev]) {
+			prev--
+		}
+		next := i + 1
+		for next < len(b) && isBlank(b[next]) {
+			next++
+		}
+		if (prev >= 0 && b[prev] == '\n') || (next < len(b) && b[next] == '\n') {
+			continue
+		}
+		b[i] = ' '
+		if i > 0 && b[i-1] == '\r' {
+			b[i-1] = ' '
+		}
+	}
+	return string(b)
+}
+
+// endsWithAbbreviation returns true if the last word of the sentence is one of the abbreviations,
+// or an initial.
+func endsWithAbbreviation(s string, abbreviations map[string]bool) bool {
+	s = strings.TrimRightFunc(s, unicode.IsSpace)
+	word := s[strings.LastIndexFunc(s, unicode.IsSpace)+1:]
+	word = strings.TrimLeft(word, "([\"'")
+	if abbreviations[strings.ToLower(word)] {
+		return true
+	}
+	r, size := utf8.DecodeRuneInString(word)
+	return len(word) == size+1 && word[size] == '.' && unicode.IsUpper(r)
+}
+
+// splitWords splits the text at its word boundaries, and the words that are longer than maxLen at
+// their grapheme clusters, so that no part is longer than maxLen, unless it is a single grapheme
+// cluster. Concatenating all the parts returns the original text.
+func splitWords(text string, maxLen int, lenFunc func(string) int) []string {
+	var parts []string
+	state := -1
+	for len(text) > 0 {
+		var word string
+		word, text, state = uniseg.FirstWordInString(text, state)
+		if lenFunc(word) <= maxLen {
+			parts = append(parts, word)
+			continue
+		}
+		graphemeState := -1
+		for len(word) > 0 {
+			var cluster string
+			cluster, word, _, graphemeState = uniseg.FirstGraphemeClusterInString(word, graphemeState)
+			parts = append(parts, cluster)
+		}
+	}
+	return parts
+}
@@ -2,6 +2,7 @@ package flowllm
 
 import (
 	"log"
+	"regexp"
 	"strings"
 )
 
@@ -21,6 +22,12 @@ type SplitterOptions struct {
 	LenFunc func(string) int
 	// Separators is a list of strings that will be used to split the text
 	Separators []string
+	// KeepSeparator keeps the separators in the chunks, at the start of the text that follows them.
+	// By default, the separators are removed at the boundaries of the chunks
+	KeepSeparator bool
+	// Abbreviations is a list of abbreviations (ex: "Dr.", "e.g.") that do not end a sentence, used
+	// by the SentenceSplitter. Defaults to common English abbreviations
+	Abbreviations []string
 }
 
 // RecursiveTextSplitter splits a text into chunks of a given size, trying to
@@ -43,13 +50,22 @@ func RecursiveTextSplitter(opts SplitterOptions) Splitter {
 	splitter = func(text string) ([]string, error) {
 		var separator string
 		for _, s := range opts.Separators {
-			if s == "" || strings.Contains(text, s) {
+			// A text starting with the separator is not split by it when keeping the separators
+			if s == "" || (!opts.KeepSeparator && strings.Contains(text, s)) ||
+				(opts.KeepSeparator && strings.Contains(strings.TrimPrefix(text, s), s)) {
 				separator = s
 				break
 			}
 		}
 
 		splits := strings.Split(text, separator)
+		mergeSeparator := separator
+		if opts.KeepSeparator && separator != "" {
+			for i := 1; i < len(splits); i++ {
+				splits[i] = separator + splits[i]
+			}
+			mergeSeparator = ""
+		}
 		var finalChunks []string
 		var goodSplits []string
 		for _, split := range splits {
@@ -57,7 +73,7 @@ func RecursiveTextSplitter(opts SplitterOptions) Splitter {
 				goodSplits = append(goodSplits, split)
 			} else {
 				if len(goodSplits) > 0 {
-					mergedText := mergeSplits(goodSplits, separator, opts.ChunkSize, opts.ChunkOverlap, opts.LenFunc) // Pass LenFunc
+					mergedText := mergeSplits(goodSplits, mergeSeparator, opts.ChunkSize, opts.ChunkOverlap, opts.LenFunc) // Pass LenFunc
 					finalChunks = append(finalChunks, mergedText...)
 					goodSplits = nil
 				}
@@ -70,7 +86,7 @@ func RecursiveTextSplitter(opts SplitterOptions) Splitter {
 		}
 
 		if len(goodSplits) > 0 {
-			mergedText := mergeSplits(goodSplits, separator, opts.ChunkSize, opts.ChunkOverlap, opts.LenFunc) // Pass LenFunc
+			mergedText := mergeSplits(goodSplits, mergeSeparator, opts.ChunkSize, opts.ChunkOverlap, opts.LenFunc) // Pass LenFunc
 			finalChunks = append(finalChunks, mergedText...)
 		}
 		return finalChunks, nil
@@ -78,36 +94,257 @@ func RecursiveTextSplitter(opts SplitterOptions) Splitter {
 	return splitter
 }
 
-// MarkdownSplitter returns a Splitter that splits a document into chunks using a set
-// of MarkdownSplitter-specific separators. It is a recursive splitter, meaning that
-// it will split each chunk into smaller chunks using the same separators.
+// MarkdownSplitter returns a Splitter that splits a Markdown document into chunks, trying to keep
+// each section, started by a heading, in its own chunk. Small sections are merged, and large
+// sections are split at their paragraphs, keeping fenced code blocks whole when possible. Both ATX
+// ("## Title") and setext (underlined) headings are supported, and lines inside fenced code blocks
+// are never considered headings. The headings are kept in the chunks.
 func MarkdownSplitter(opts SplitterOptions) Splitter {
-	opts.Separators = []string{
-		// First, try to split along MarkdownSplitter headings (starting with level 2)
-		"\n## ",
-		"\n### ",
-		"\n#### ",
-		"\n##### ",
-		"\n###### ",
-		// Note the alternative syntax for headings (below) is not handled here
-		// Heading level 2
-		// ---------------
-		// End of code block
-		"```\n\n",
-		// Horizontal lines
-		"\n\n***\n\n",
-		"\n\n---\n\n",
-		"\n\n___\n\n",
-		// Note that this splitter doesn't handle horizontal lines defined
-		// by *three or more* of ***, ---, or ___, but this is not handled
-		"\n\n",
-		"\n",
-		" ",
-		"",
+	if opts.ChunkSize == 0 {
+		opts.ChunkSize = defaultSplitterChunkSize
+	}
+	if opts.LenFunc == nil {
+		opts.LenFunc = defaultSplitterLenFunc
+	}
+	fallback := RecursiveTextSplitter(SplitterOptions{
+		ChunkSize:    opts.ChunkSize,
+		ChunkOverlap: opts.ChunkOverlap,
+		LenFunc:      opts.LenFunc,
+		Separators:   []string{"\n", " ", ""},
+	})
+
+	// merge merges the splits that fit in a chunk, splitting the others with split
+	merge := func(splits []string, split Splitter) ([]string, error) {
+		var finalChunks []string
+		var goodSplits []string
+		for _, s := range splits {
+			if opts.LenFunc(s) < opts.ChunkSize {
+				goodSplits = append(goodSplits, s)
+				continue
+			}
+			if len(goodSplits) > 0 {
+				finalChunks = append(finalChunks, mergeSplits(goodSplits, "\n\n", opts.ChunkSize, opts.ChunkOverlap, opts.LenFunc)...)
+				goodSplits = nil
+			}
+			chunks, err := split(s)
+			if err != nil {
+				return nil, err
+			}
+			finalChunks = append(finalChunks, chunks...)
+		}
+		if len(goodSplits) > 0 {
+			finalChunks = append(finalChunks, mergeSplits(goodSplits, "\n\n", opts.ChunkSize, opts.ChunkOverlap, opts.LenFunc)...)
+		}
+		return finalChunks, nil
+	}
+
+	return func(text string) ([]string, error) {
+		var sections []string
+		for _, section := range MarkdownSections(text) {
+			sections = append(sections, section.Content)
+		}
+		return merge(sections, func(section string) ([]string, error) {
+			return merge(markdownBlocks(section), fallback)
+		})
+	}
+}
+
+// SentenceSplitter returns a Splitter that splits a text into sentences, using the Unicode
+// sentence boundary rules (UAX #29), and then packs the sentences into chunks of up to ChunkSize.
+// Paragraphs, separated by blank lines, are kept in the same chunk when they fit. The overlap
+// between chunks is made of whole sentences, up to ChunkOverlap. Sentences larger than ChunkSize
+// are split at their words, and words larger than ChunkSize at their grapheme clusters, so that
+// multibyte characters are never broken.
+func SentenceSplitter(opts SplitterOptions) Splitter {
+	if opts.ChunkSize == 0 {
+		opts.ChunkSize = defaultSplitterChunkSize
+	}
+	if opts.LenFunc == nil {
+		opts.LenFunc = defaultSplitterLenFunc
+	}
+	if opts.Abbreviations == nil {
+		opts.Abbreviations = defaultAbbreviations
+	}
+	abbreviations := make(map[string]bool, len(opts.Abbreviations))
+	for _, a := range opts.Abbreviations {
+		abbreviations[strings.ToLower(a)] = true
+	}
+
+	type unit struct {
+		text   string
+		length int
+		// paragraphLength is the length of the paragraph started by the unit, if it starts one
+		paragraphLength int
+	}
+
+	return func(text string) ([]string, error) {
+		var units []unit
+		paragraphStart := 0
+		for _, s := range splitSentences(text, abbreviations) {
+			if s.paragraph {
+				paragraphStart = len(units)
+			}
+			length := opts.LenFunc(s.text)
+			if length <= opts.ChunkSize {
+				units = append(units, unit{text: s.text, length: length})
+			} else {
+				for _, word := range splitWords(s.text, opts.ChunkSize, opts.LenFunc) {
+					units = append(units, unit{text: word, length: opts.LenFunc(word)})
+				}
+			}
+			units[paragraphStart].paragraphLength += length
+		}
+
+		var chunks []string
+		var current []unit
+		total := 0
+		flush := func() {
+			var b strings.Builder
+			for _, c := range current {
+				b.WriteString(c.text)
+			}
+			if chunk := strings.TrimSpace(b.String()); chunk != "" {
+				chunks = append(chunks, chunk)
+			}
+		}
+		for _, u := range units {
+			full := total+u.length > opts.ChunkSize
+			// Start a new chunk for a paragraph that does not fit in the current one, but fits in its own
+			breakParagraph := u.paragraphLength > 0 && total+u.paragraphLength > opts.ChunkSize &&
+				u.paragraphLength <= opts.ChunkSize
+			if len(current) > 0 && (full || breakParagraph) {
+				flush()
+				for len(current) > 0 && (total > opts.ChunkOverlap || total+u.length > opts.ChunkSize) {
+					total -= current[0].length
+					current = current[1:]
+				}
+			}
+			current = append(current, u)
+			total += u.length
+		}
+		flush()
+		return chunks, nil
 	}
+}
+
+// GoSplitter returns a Splitter that splits Go source code, trying to keep top-level declarations
+// and then blocks of statements together. Separators are kept in the chunks.
+func GoSplitter(opts SplitterOptions) Splitter {
+	return codeSplitter(opts, "\nfunc ", "\nvar ", "\nconst ", "\ntype ",
+		"\n\tif ", "\n\tfor ", "\n\tswitch ", "\n\tselect ", "\n\tcase ")
+}
+
+// PythonSplitter returns a Splitter that splits Python source code, trying to keep classes and
+// functions together. Separators are kept in the chunks.
+func PythonSplitter(opts SplitterOptions) Splitter {
+	return codeSplitter(opts, "\nclass ", "\ndef ", "\nasync def ", "\n    def ", "\n    async def ", "\n\tdef ")
+}
+
+// JavaScriptSplitter returns a Splitter that splits JavaScript source code, trying to keep
+// functions, classes and then blocks of statements together. Separators are kept in the chunks.
+func JavaScriptSplitter(opts SplitterOptions) Splitter {
+	return codeSplitter(opts, "\nexport ", "\nfunction ", "\nasync function ", "\nclass ", "\nconst ", "\nlet ", "\nvar ",
+		"\n  if ", "\n  for ", "\n  while ", "\n  switch ", "\n  case ")
+}
+
+// TypeScriptSplitter returns a Splitter that splits TypeScript source code, like the
+// JavaScriptSplitter, also keeping interfaces, types, enums and namespaces together.
+func TypeScriptSplitter(opts SplitterOptions) Splitter {
+	return codeSplitter(opts, "\nexport ", "\ninterface ", "\ntype ", "\nenum ", "\nnamespace ", "\nfunction ",
+		"\nasync function ", "\nclass ", "\nconst ", "\nlet ", "\nvar ",
+		"\n  if ", "\n  for ", "\n  while ", "\n  switch ", "\n  case ")
+}
+
+// JavaSplitter returns a Splitter that splits Java source code, trying to keep classes, methods
+// and then blocks of statements together. Separators are kept in the chunks.
+func JavaSplitter(opts SplitterOptions) Splitter {
+	return codeSplitter(opts, "\nclass ", "\npublic ", "\nprotected ", "\nprivate ", "\ninterface ", "\nenum ",
+		"\n    public ", "\n    protected ", "\n    private ", "\n    static ",
+		"\n        if ", "\n        for ", "\n        while ", "\n        switch ", "\n        case ")
+}
+
+// RustSplitter returns a Splitter that splits Rust source code, trying to keep items (functions,
+// structs, traits, impls, modules) and then blocks of statements together. Separators are kept in
+// the chunks.
+func RustSplitter(opts SplitterOptions) Splitter {
+	return codeSplitter(opts, "\npub fn ", "\nfn ", "\npub struct ", "\nstruct ", "\npub enum ", "\nenum ",
+		"\npub trait ", "\ntrait ", "\nimpl", "\npub mod ", "\nmod ", "\nconst ", "\nstatic ",
+		"\n    pub fn ", "\n    fn ", "\n    let ", "\n    if ", "\n    for ", "\n    while ", "\n    loop ", "\n    match ")
+}
+
+// codeSplitter returns a RecursiveTextSplitter that splits at the separators of a programming
+// language, falling back to blank lines, lines and words.
+func codeSplitter(opts SplitterOptions, separators ...string) Splitter {
+	opts.Separators = append(separators, "\n\n", "\n", " ", "")
+	opts.KeepSeparator = true
 	return RecursiveTextSplitter(opts)
 }
 
+// ToChunkSplitter converts a Splitter into a ChunkSplitter, locating the chunks returned by the
+// splitter in the text with LocateChunks.
+func ToChunkSplitter(splitter Splitter) ChunkSplitter {
+	return func(text string) ([]Chunk, error) {
+		chunks, err := splitter(text)
+		if err != nil {
+			return nil, err
+		}
+		return LocateChunks(text, chunks), nil
+	}
+}
+
+// LocateChunks finds the position of each chunk in the text they were split from. The chunks are
+// expected to be in the order they appear in the text, possibly overlapping. Chunks that are not
+// found verbatim in the text are matched ignoring differences in whitespace, as splitters may
+// trim or join parts of the text with different separators. Chunks that cannot be found at all
+// have their StartIndex and EndIndex set to -1.
+func LocateChunks(text string, chunks []string) []Chunk {
+	result := make([]Chunk, 0, len(chunks))
+	from := 0
+	line, lineIndex := 1, 0
+	for _, chunk := range chunks {
+		start, end := locateChunk(text, chunk, from)
+		if start < 0 {
+			result = append(result, Chunk{Text: chunk, StartIndex: -1, EndIndex: -1})
+			continue
+		}
+		line += strings.Count(text[lineIndex:start], "\n")
+		lineIndex = start
+		result = append(result, Chunk{
+			Text:       chunk,
+			StartIndex: start,
+			EndIndex:   end,
+			StartLine:  line,
+			EndLine:    line + strings.Count(text[start:end], "\n"),
+		})
+		// The next chunk may overlap this one, but cannot start before it
+		from = start + 1
+	}
+	return result
+}
+
+// locateChunk returns the start and end offsets of the first occurrence of the chunk in the
+// text, starting at from, or -1 if it is not found.
+func locateChunk(text, chunk string, from int) (int, int) {
+	if from > len(text) || chunk == "" {
+		return -1, -1
+	}
+	if i := strings.Index(text[from:], chunk); i >= 0 {
+		return from + i, from + i + len(chunk)
+	}
+	words := strings.Fields(chunk)
+	if len(words) == 0 {
+		return -1, -1
+	}
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	loc := regexp.MustCompile(strings.Join(words, `\s+`)).FindStringIndex(text[from:])
+	if loc == nil {
+		return -1, -1
+	}
+	return from + loc[0], from + loc[1]
+}
+
 func joinDocs(docs []string, separator string) string {
 	return strings.TrimSpace(strings.Join(docs, separator))
 }
@@ -1,6 +1,8 @@
 package flowllm_test
 
 import (
+	"strings"
+
 	. "github.com/deluan/flowllm"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -84,8 +86,8 @@ This is even more content.`
 				splitter = MarkdownSplitter(SplitterOptions{ChunkSize: 40, ChunkOverlap: 20})
 				expectedOutput = []string{
 					"# Header 1\n\nThis is some content.",
-					"Header 2\n\nThis is some more content.",
-					"Header 3\n\nThis is even more content.",
+					"## Header 2\n\nThis is some more content.",
+					"### Header 3\n\nThis is even more content.",
 				}
 			})
 
@@ -95,5 +97,132 @@ This is even more content.`
 				Expect(chunks).To(Equal(expectedOutput))
 			})
 		})
+
+		It("merges small sections and splits at setext headings", func() {
+			splitter = MarkdownSplitter(SplitterOptions{ChunkSize: 50})
+			chunks, err := splitter("Title\n=====\n\nIntro.\n\nUsage\n-----\n\nRun the program with the flags.\n\nMore\n----\n\nText.")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chunks).To(Equal([]string{
+				"Title\n=====\n\nIntro.",
+				"Usage\n-----\n\nRun the program with the flags.",
+				"More\n----\n\nText.",
+			}))
+		})
+
+		It("keeps fenced code blocks whole when splitting large sections", func() {
+			splitter = MarkdownSplitter(SplitterOptions{ChunkSize: 60})
+			chunks, err := splitter("## Example\n\nThe code below prints a comment:\n\n```sh\n# not a heading\n\necho done\n```\n\nThat's all.")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chunks).To(Equal([]string{
+				"## Example\n\nThe code below prints a comment:",
+				"```sh\n# not a heading\n\necho done\n```\n\nThat's all.",
+			}))
+		})
+	})
+
+	Describe("GoSplitter", func() {
+		It("splits at top-level declarations, keeping the keywords in the chunks", func() {
+			splitter := GoSplitter(SplitterOptions{ChunkSize: 60})
+			chunks, err := splitter("package main\n\nfunc a() {\n\tprintln(\"a\")\n}\n\nfunc b() {\n\tprintln(\"b\")\n}\n\ntype T struct{}")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chunks).To(Equal([]string{
+				"package main\n\nfunc a() {\n\tprintln(\"a\")\n}",
+				"func b() {\n\tprintln(\"b\")\n}\n\ntype T struct{}",
+			}))
+		})
+	})
+
+	Describe("PythonSplitter", func() {
+		It("splits at classes and methods", func() {
+			splitter := PythonSplitter(SplitterOptions{ChunkSize: 40})
+			chunks, err := splitter("class A:\n    def one(self):\n        return 1\n\n    def two(self):\n        return 2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chunks).To(Equal([]string{
+				"class A:",
+				"def one(self):\n        return 1",
+				"def two(self):\n        return 2",
+			}))
+		})
+	})
+
+	Describe("LocateChunks", func() {
+		It("finds overlapping and repeated chunks in order", func() {
+			chunks := LocateChunks("one two\none two", []string{"one two", "two\none", "one two"})
+			Expect(chunks).To(Equal([]Chunk{
+				{Text: "one two", StartIndex: 0, EndIndex: 7, StartLine: 1, EndLine: 1},
+				{Text: "two\none", StartIndex: 4, EndIndex: 11, StartLine: 1, EndLine: 2},
+				{Text: "one two", StartIndex: 8, EndIndex: 15, StartLine: 2, EndLine: 2},
+			}))
+		})
+
+		It("ignores differences in whitespace, and marks the chunks not found", func() {
+			chunks := LocateChunks("# Title\r\n\r\n\r\nText", []string{"# Title\n\nText", "missing"})
+			Expect(chunks).To(Equal([]Chunk{
+				{Text: "# Title\n\nText", StartIndex: 0, EndIndex: 17, StartLine: 1, EndLine: 4},
+				{Text: "missing", StartIndex: -1, EndIndex: -1},
+			}))
+		})
+	})
+
+	Describe("ToChunkSplitter", func() {
+		It("returns the position of the chunks of the splitter", func() {
+			splitter := ToChunkSplitter(RecursiveTextSplitter(SplitterOptions{ChunkSize: 12}))
+			chunks, err := splitter("First line\nSecond line")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chunks).To(Equal([]Chunk{
+				{Text: "First line", StartIndex: 0, EndIndex: 10, StartLine: 1, EndLine: 1},
+				{Text: "Second line", StartIndex: 11, EndIndex: 22, StartLine: 2, EndLine: 2},
+			}))
+		})
+	})
+
+	Describe("SentenceSplitter", func() {
+		It("packs whole sentences into chunks, handling abbreviations and wrapped lines", func() {
+			splitter := SentenceSplitter(SplitterOptions{ChunkSize: 60})
+			chunks, err := splitter("Dr. Smith arrived at 10 a.m. on Monday. He met J. Doe,\nthe new director. They talked for an hour.")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chunks).To(Equal([]string{
+				"Dr. Smith arrived at 10 a.m. on Monday.",
+				"He met J. Doe,\nthe new director. They talked for an hour.",
+			}))
+		})
+
+		It("keeps paragraphs together when they fit in a chunk", func() {
+			splitter := SentenceSplitter(SplitterOptions{ChunkSize: 40})
+			chunks, err := splitter("One. Two.\n\nThree is longer. Four is longer.")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chunks).To(Equal([]string{"One. Two.", "Three is longer. Four is longer."}))
+		})
+
+		It("overlaps chunks with whole sentences", func() {
+			splitter := SentenceSplitter(SplitterOptions{ChunkSize: 30, ChunkOverlap: 12})
+			chunks, err := splitter("First one. Second one. Third one. Fourth one.")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chunks).To(Equal([]string{
+				"First one. Second one.",
+				"Second one. Third one.",
+				"Third one. Fourth one.",
+			}))
+		})
+
+		It("segments sentences of non-English text, without breaking characters", func() {
+			splitter := SentenceSplitter(SplitterOptions{ChunkSize: 30})
+			chunks, err := splitter("今日は晴れです。明日は雨です。")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chunks).To(Equal([]string{"今日は晴れです。", "明日は雨です。"}))
+
+			splitter = SentenceSplitter(SplitterOptions{ChunkSize: 4})
+			chunks, err = splitter("🇧🇷🇵🇹")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chunks).To(Equal([]string{"🇧🇷", "🇵🇹"}))
+		})
+
+		It("uses the LenFunc to measure the chunks", func() {
+			words := func(s string) int { return len(strings.Fields(s)) }
+			splitter := SentenceSplitter(SplitterOptions{ChunkSize: 4, LenFunc: words})
+			chunks, err := splitter("One two. Three four. Five six seven eight nine.")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chunks).To(Equal([]string{"One two. Three four.", "Five six seven eight", "nine."}))
+		})
 	})
 })
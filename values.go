@@ -1,6 +1,7 @@
 package flowllm
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -8,10 +9,25 @@ import (
 )
 
 const (
-	DefaultKey     = "text"
-	DefaultChatKey = "_chat_messages"
+	DefaultKey        = "text"
+	DefaultChatKey    = "_chat_messages"
+	DefaultSessionKey = "_session_id"
 )
 
+type sessionIDKey struct{}
+
+// WithSessionID returns a copy of the context carrying the given session ID. Memories use the
+// session ID to keep the conversations of different users apart.
+func WithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDKey{}, sessionID)
+}
+
+// SessionID returns the session ID carried by the context, or an empty string if there is none.
+func SessionID(ctx context.Context) string {
+	id, _ := ctx.Value(sessionIDKey{}).(string)
+	return id
+}
+
 // Values is a map of string to any value. This is the type used to pass values between handlers.
 type Values map[string]any
 
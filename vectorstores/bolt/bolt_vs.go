@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"time"
@@ -20,6 +21,11 @@ const (
 	DefaultPermission = 0600
 )
 
+// collectionsBucket registers the names of the buckets used as collections, to tell them apart
+// from other buckets in the same database, like the ones used by the Bolt chat history, BM25
+// index and docstore.
+const collectionsBucket = "flowllm_vector_collections"
+
 // Options for the Bolt vector store.
 type Options struct {
 	Path       string
@@ -30,10 +36,16 @@ type Options struct {
 
 // VectorStore is a vector store backed by BoltDB. It implements the flowllm.VectorStore interface,
 // and it is ideal for small to medium-sized collections of vectors.
+//
+// Each collection is stored in its own bucket. The store returned by NewVectorStore works on the
+// bucket specified in the Options, use Collection to get a view scoped to another collection.
+// Collections are registered in a separate bucket, so other buckets in the same database are
+// never listed or dropped as collections.
 type VectorStore struct {
 	embeddings flowllm.Embeddings
 	db         *bbolt.DB
 	bucket     string
+	search     vectorstores.SearchOptions
 }
 
 // NewVectorStore creates a new Bolt vector store.
@@ -59,34 +71,158 @@ func NewVectorStore(embeddings flowllm.Embeddings, opts Options) (*VectorStore,
 		return nil, func() {}, err
 	}
 	err = db.Update(func(tx *bbolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte(s.bucket))
-		if err != nil {
-			return fmt.Errorf("create bucket: %w", err)
-		}
-		return nil
+		_, err := createCollection(tx, s.bucket)
+		return err
 	})
 	if err != nil {
+		_ = db.Close()
 		return nil, func() {}, err
 	}
 	s.db = db
 	return &s, func() { _ = db.Close() }, nil
 }
 
+// Collection returns a view of the store scoped to the given collection. The view shares the
+// database with the original store, and it is valid until the database is closed. The collection
+// is created when the first document is added to it.
+func (s *VectorStore) Collection(name string) *VectorStore {
+	view := *s
+	view.bucket = name
+	return &view
+}
+
+// WithSearchOptions returns a view of the store that applies the given options to all searches.
+// Scores are calculated with the Cosine metric.
+func (s *VectorStore) WithSearchOptions(opts vectorstores.SearchOptions) *VectorStore {
+	view := *s
+	view.search = opts
+	return &view
+}
+
+// CreateCollection creates a new empty collection. It does nothing if the collection already exists.
+func (s *VectorStore) CreateCollection(_ context.Context, name string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		_, err := createCollection(tx, name)
+		return err
+	})
+}
+
+// ListCollections returns the names of all collections in the database, sorted alphabetically.
+func (s *VectorStore) ListCollections(_ context.Context) ([]string, error) {
+	var names []string
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		registry := tx.Bucket([]byte(collectionsBucket))
+		if registry == nil {
+			return nil
+		}
+		return registry.ForEach(func(name, _ []byte) error {
+			if tx.Bucket(name) != nil {
+				names = append(names, string(name))
+			}
+			return nil
+		})
+	})
+	return names, err
+}
+
+// DropCollection removes a collection and all its documents from the database. It returns
+// vectorstores.ErrCollectionNotFound if the name is not a collection, even if it is another bucket
+// in the database.
+func (s *VectorStore) DropCollection(_ context.Context, name string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		registry := tx.Bucket([]byte(collectionsBucket))
+		if registry == nil || registry.Get([]byte(name)) == nil {
+			return vectorstores.ErrCollectionNotFound
+		}
+		if err := registry.Delete([]byte(name)); err != nil {
+			return err
+		}
+		err := tx.DeleteBucket([]byte(name))
+		if errors.Is(err, bbolt.ErrBucketNotFound) {
+			return vectorstores.ErrCollectionNotFound
+		}
+		return err
+	})
+}
+
+// createCollection creates the bucket of a collection, if it does not exist, and registers it as a
+// collection. Buckets created by older versions of the store are migrated when registered.
+func createCollection(tx *bbolt.Tx, name string) (*bbolt.Bucket, error) {
+	if name == collectionsBucket {
+		return nil, fmt.Errorf("invalid collection name: %s", name)
+	}
+	registry, err := tx.CreateBucketIfNotExists([]byte(collectionsBucket))
+	if err != nil {
+		return nil, fmt.Errorf("create bucket: %w", err)
+	}
+	registered := registry.Get([]byte(name)) != nil
+	if err := registry.Put([]byte(name), []byte{1}); err != nil {
+		return nil, err
+	}
+	bucket, err := tx.CreateBucketIfNotExists([]byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("create bucket: %w", err)
+	}
+	if !registered {
+		if err := migrateKeys(bucket); err != nil {
+			return nil, fmt.Errorf("migrate collection %s: %w", name, err)
+		}
+	}
+	return bucket, nil
+}
+
+// migrateKeys replaces the keys generated by older versions of the store, which were the hash of
+// the whole stored item, with the ones generated by vectorstores.DocumentID, so documents added
+// again without ID replace the existing ones instead of being duplicated. Documents added with an
+// ID are kept as they are.
+func migrateKeys(bucket *bbolt.Bucket) error {
+	type rename struct {
+		oldKey, newKey string
+		value          []byte
+	}
+	var renames []rename
+	err := bucket.ForEach(func(k, v []byte) error {
+		if string(k) != fmt.Sprintf("%x", sha256.Sum256(v)) {
+			return nil
+		}
+		var item boltItem
+		if err := json.Unmarshal(v, &item); err != nil {
+			return err
+		}
+		newKey := vectorstores.DocumentID(flowllm.Document{PageContent: item.Content, Metadata: item.Metadata})
+		// Values are only valid during the transaction, and can't be used after modifying the bucket
+		renames = append(renames, rename{oldKey: string(k), newKey: newKey, value: append([]byte(nil), v...)})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range renames {
+		if bucket.Get([]byte(r.newKey)) == nil {
+			if err := bucket.Put([]byte(r.newKey), r.value); err != nil {
+				return err
+			}
+		}
+		if err := bucket.Delete([]byte(r.oldKey)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type boltItem struct {
 	Vectors  []float32              `json:"vectors"`
 	Content  string                 `json:"content"`
 	Metadata map[string]interface{} `json:"metadata"`
 }
 
-func (d boltItem) id() string {
-	return fmt.Sprintf("%x", sha256.Sum256(d.Marshall()))
-}
-
 func (d boltItem) Marshall() []byte {
 	buf, _ := json.Marshal(d)
 	return buf
 }
 
+// AddDocuments adds the documents to the collection. Documents with the same ID as an existing
+// document replace it. Documents without ID get one derived from their content and metadata.
 func (s *VectorStore) AddDocuments(ctx context.Context, documents ...flowllm.Document) error {
 	texts := make([]string, len(documents))
 	for i, document := range documents {
@@ -98,14 +234,79 @@ func (s *VectorStore) AddDocuments(ctx context.Context, documents ...flowllm.Doc
 	}
 
 	return s.db.Batch(func(tx *bbolt.Tx) error {
-		bucket := tx.Bucket([]byte(s.bucket))
+		bucket, err := createCollection(tx, s.bucket)
+		if err != nil {
+			return err
+		}
 		for i, doc := range documents {
 			item := boltItem{
 				Vectors:  vectors[i],
 				Content:  doc.PageContent,
 				Metadata: doc.Metadata,
 			}
-			if err := bucket.Put([]byte(item.id()), item.Marshall()); err != nil {
+			if err := bucket.Put([]byte(vectorstores.DocumentID(doc)), item.Marshall()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateDocuments replaces the content and metadata of the documents with the same IDs in the
+// collection. Only documents with a changed content are embedded again. It returns
+// vectorstores.ErrDocumentNotFound if any of the documents does not exist, without updating any of them.
+func (s *VectorStore) UpdateDocuments(ctx context.Context, documents ...flowllm.Document) error {
+	items := make([]boltItem, len(documents))
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(s.bucket))
+		for i, doc := range documents {
+			var v []byte
+			if bucket != nil {
+				v = bucket.Get([]byte(doc.ID))
+			}
+			if v == nil {
+				return fmt.Errorf("%w: %s", vectorstores.ErrDocumentNotFound, doc.ID)
+			}
+			if err := json.Unmarshal(v, &items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var changed []int
+	var texts []string
+	for i, doc := range documents {
+		if items[i].Content != doc.PageContent {
+			changed = append(changed, i)
+			texts = append(texts, doc.PageContent)
+		}
+		items[i].Content = doc.PageContent
+		items[i].Metadata = doc.Metadata
+	}
+	if len(texts) > 0 {
+		vectors, err := s.embeddings.EmbedStrings(ctx, texts)
+		if err != nil {
+			return err
+		}
+		for j, i := range changed {
+			items[i].Vectors = vectors[j]
+		}
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(s.bucket))
+		if bucket == nil {
+			return vectorstores.ErrDocumentNotFound
+		}
+		for i, doc := range documents {
+			if bucket.Get([]byte(doc.ID)) == nil {
+				return fmt.Errorf("%w: %s", vectorstores.ErrDocumentNotFound, doc.ID)
+			}
+			if err := bucket.Put([]byte(doc.ID), items[i].Marshall()); err != nil {
 				return err
 			}
 		}
@@ -126,6 +327,9 @@ func (s *VectorStore) SimilaritySearchVectorWithScore(_ context.Context, query [
 	var matches []match
 	err := s.db.View(func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket([]byte(s.bucket))
+		if bucket == nil {
+			return nil
+		}
 		return bucket.ForEach(func(k, v []byte) error {
 			var item boltItem
 			err := json.Unmarshal(v, &item)
@@ -157,6 +361,7 @@ func (s *VectorStore) SimilaritySearchVectorWithScore(_ context.Context, query [
 			results = append(results, flowllm.ScoredDocument{
 				Score: match.similarity,
 				Document: flowllm.Document{
+					ID:          string(match.id),
 					PageContent: item.Content,
 					Metadata:    item.Metadata,
 				},
@@ -164,7 +369,10 @@ func (s *VectorStore) SimilaritySearchVectorWithScore(_ context.Context, query [
 		}
 		return nil
 	})
-	return results, err
+	if err != nil {
+		return nil, err
+	}
+	return s.search.Apply(vectorstores.Cosine, results), nil
 }
 
 func min(a, b int) int {
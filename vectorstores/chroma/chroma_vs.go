@@ -0,0 +1,329 @@
+package chroma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/vectorstores"
+)
+
+const (
+	DefaultURL        = "http://localhost:8000"
+	DefaultCollection = "flowllm"
+)
+
+// Space is the distance function used by Chroma to compare vectors in a collection.
+type Space string
+
+const (
+	Cosine       Space = "cosine"
+	L2           Space = "l2"
+	InnerProduct Space = "ip"
+)
+
+// Where is a Chroma metadata filter. Example:
+//
+//	chroma.Where{"source": "file.txt"}
+//	chroma.Where{"$and": []chroma.Where{{"source": "file.txt"}, {"page": chroma.Where{"$gt": 1}}}}
+//
+// See https://docs.trychroma.com/usage-guide#using-where-filters
+type Where map[string]any
+
+// Options for the Chroma vector store.
+type Options struct {
+	// URL of the Chroma server. Defaults to the CHROMA_URL environment variable or DefaultURL
+	URL string
+	// ApiKey used to authenticate with the server. Defaults to the CHROMA_API_KEY environment variable
+	ApiKey string
+	// Collection is the name of the collection used by the store
+	Collection string
+	// Space is the distance function used when creating collections
+	Space Space
+	// HTTPClient used to make requests. Defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// VectorStore is a vector store backed by a Chroma server, accessed through its REST API.
+type VectorStore struct {
+	client     *client
+	embeddings flowllm.Embeddings
+	collection string
+	space      Space
+	where      Where
+	state      *collectionState
+}
+
+// collectionState caches the IDs of the collections, as Chroma requires them for most operations.
+type collectionState struct {
+	mu  sync.Mutex
+	ids map[string]string
+}
+
+// NewVectorStore creates a new Chroma vector store. It does not make any request to the server,
+// collections are created when needed.
+func NewVectorStore(embeddings flowllm.Embeddings, opts Options) (*VectorStore, error) {
+	if opts.URL == "" {
+		opts.URL = os.Getenv("CHROMA_URL")
+	}
+	if opts.URL == "" {
+		opts.URL = DefaultURL
+	}
+	if opts.ApiKey == "" {
+		opts.ApiKey = os.Getenv("CHROMA_API_KEY")
+	}
+	if opts.Collection == "" {
+		opts.Collection = DefaultCollection
+	}
+	if opts.Space == "" {
+		opts.Space = Cosine
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	if _, err := url.Parse(opts.URL); err != nil {
+		return nil, fmt.Errorf("invalid url %q: %w", opts.URL, err)
+	}
+	c := &client{
+		url:        opts.URL,
+		apiKey:     opts.ApiKey,
+		httpClient: opts.HTTPClient,
+	}
+	return &VectorStore{
+		client:     c,
+		embeddings: embeddings,
+		collection: opts.Collection,
+		space:      opts.Space,
+		state:      &collectionState{ids: map[string]string{}},
+	}, nil
+}
+
+// Collection returns a view of the store scoped to the given collection.
+func (s *VectorStore) Collection(name string) *VectorStore {
+	view := *s
+	view.collection = name
+	view.where = nil
+	return &view
+}
+
+// WithFilter returns a view of the store where all searches are restricted to the documents
+// matching the given metadata filter.
+func (s *VectorStore) WithFilter(where Where) *VectorStore {
+	view := *s
+	view.where = where
+	return &view
+}
+
+// CreateCollection creates a new collection, using the Space from the Options.
+// It does nothing if the collection already exists.
+func (s *VectorStore) CreateCollection(ctx context.Context, name string) error {
+	_, err := s.collectionID(ctx, name, true)
+	return err
+}
+
+// ListCollections returns the names of all collections in the server.
+func (s *VectorStore) ListCollections(ctx context.Context) ([]string, error) {
+	return s.client.listCollections(ctx)
+}
+
+// DropCollection removes a collection and all its documents from the server.
+func (s *VectorStore) DropCollection(ctx context.Context, name string) error {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	delete(s.state.ids, name)
+	deleted, err := s.client.deleteCollection(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return vectorstores.ErrCollectionNotFound
+	}
+	return nil
+}
+
+// collectionID returns the ID of the collection with the given name. If create is true, the
+// collection is created if it does not exist. Otherwise, an empty ID is returned.
+func (s *VectorStore) collectionID(ctx context.Context, name string, create bool) (string, error) {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	if id, ok := s.state.ids[name]; ok {
+		return id, nil
+	}
+	var col collection
+	var err error
+	if create {
+		col, err = s.client.getOrCreateCollection(ctx, name, s.space)
+	} else {
+		var found bool
+		col, found, err = s.client.getCollection(ctx, name)
+		if !found {
+			return "", err
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	s.state.ids[name] = col.ID
+	return col.ID, nil
+}
+
+// AddDocuments adds the given documents to the collection, creating it if it does not exist.
+// Documents with an ID replace any existing document with the same ID. Documents without ID
+// get one derived from their content and metadata. Chroma only accepts strings, numbers and booleans
+// as metadata values, so other values, like lists, are stored JSON-encoded, and decoded when the
+// documents are returned by the searches. Encoded values can't be used in filters.
+func (s *VectorStore) AddDocuments(ctx context.Context, documents ...flowllm.Document) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	texts := make([]string, len(documents))
+	for i, document := range documents {
+		texts[i] = document.PageContent
+	}
+	vectors, err := s.embeddings.EmbedStrings(ctx, texts)
+	if err != nil {
+		return err
+	}
+	colID, err := s.collectionID(ctx, s.collection, true)
+	if err != nil {
+		return err
+	}
+
+	payload := upsertPayload{
+		IDs:        make([]string, len(documents)),
+		Embeddings: vectors,
+		Documents:  texts,
+		Metadatas:  make([]map[string]any, len(documents)),
+	}
+	for i, doc := range documents {
+		payload.IDs[i] = vectorstores.DocumentID(doc)
+		payload.Metadatas[i], err = scalarMetadata(doc.Metadata)
+		if err != nil {
+			return err
+		}
+	}
+	return s.client.upsert(ctx, colID, payload)
+}
+
+// encodedKeysKey is the metadata key where the list of JSON-encoded keys is stored, as a JSON array
+const encodedKeysKey = "flowllm_encoded_keys"
+
+// scalarMetadata returns a copy of the metadata with the values that are not strings, numbers or
+// booleans JSON-encoded. The encoded keys are recorded under the encodedKeysKey.
+func scalarMetadata(metadata map[string]any) (map[string]any, error) {
+	if metadata == nil {
+		return nil, nil
+	}
+	result := make(map[string]any, len(metadata))
+	var encodedKeys []string
+	for k, v := range metadata {
+		switch v.(type) {
+		case string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			result[k] = v
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("marshalling metadata %q: %w", k, err)
+			}
+			result[k] = string(encoded)
+			encodedKeys = append(encodedKeys, k)
+		}
+	}
+	if len(encodedKeys) > 0 {
+		sort.Strings(encodedKeys)
+		keys, _ := json.Marshal(encodedKeys)
+		result[encodedKeysKey] = string(keys)
+	}
+	return result, nil
+}
+
+// decodeMetadata decodes the values encoded by scalarMetadata, in place.
+func decodeMetadata(metadata map[string]any) error {
+	keys, ok := metadata[encodedKeysKey].(string)
+	if !ok {
+		return nil
+	}
+	delete(metadata, encodedKeysKey)
+	var encodedKeys []string
+	if err := json.Unmarshal([]byte(keys), &encodedKeys); err != nil {
+		return fmt.Errorf("unmarshalling encoded metadata keys: %w", err)
+	}
+	for _, k := range encodedKeys {
+		encoded, ok := metadata[k].(string)
+		if !ok {
+			continue
+		}
+		var v any
+		if err := json.Unmarshal([]byte(encoded), &v); err != nil {
+			return fmt.Errorf("unmarshalling metadata %q: %w", k, err)
+		}
+		metadata[k] = v
+	}
+	return nil
+}
+
+// Delete removes the documents with the given IDs from the collection.
+func (s *VectorStore) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	colID, err := s.collectionID(ctx, s.collection, false)
+	if err != nil || colID == "" {
+		return err
+	}
+	return s.client.delete(ctx, colID, ids)
+}
+
+func (s *VectorStore) SimilaritySearch(ctx context.Context, query string, k int) ([]flowllm.Document, error) {
+	return vectorstores.SimilaritySearch(ctx, s, s.embeddings, query, k)
+}
+
+// SimilaritySearchVectorWithScore returns the k most similar documents to the query vector. Chroma
+// returns distances, which are converted to similarity scores, where higher is more similar.
+func (s *VectorStore) SimilaritySearchVectorWithScore(ctx context.Context, query []float32, k int) ([]flowllm.ScoredDocument, error) {
+	colID, err := s.collectionID(ctx, s.collection, false)
+	if err != nil || colID == "" {
+		return nil, err
+	}
+	resp, err := s.client.query(ctx, colID, query, k, s.where)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.IDs) == 0 {
+		return nil, nil
+	}
+
+	var results []flowllm.ScoredDocument
+	for i, id := range resp.IDs[0] {
+		doc := flowllm.ScoredDocument{Document: flowllm.Document{ID: id}}
+		if len(resp.Documents) > 0 && i < len(resp.Documents[0]) {
+			doc.PageContent = resp.Documents[0][i]
+		}
+		if len(resp.Metadatas) > 0 && i < len(resp.Metadatas[0]) {
+			doc.Metadata = resp.Metadatas[0][i]
+			if err := decodeMetadata(doc.Metadata); err != nil {
+				return nil, err
+			}
+		}
+		if len(resp.Distances) > 0 && i < len(resp.Distances[0]) {
+			doc.Score = s.score(resp.Distances[0][i])
+		}
+		results = append(results, doc)
+	}
+	return results, nil
+}
+
+// score converts a Chroma distance to a similarity score.
+func (s *VectorStore) score(distance float32) float32 {
+	if s.space == L2 {
+		return -distance
+	}
+	// Both cosine and inner product distances are calculated as 1 - similarity
+	return 1 - distance
+}
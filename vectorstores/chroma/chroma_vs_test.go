@@ -0,0 +1,156 @@
+package chroma_test
+
+import (
+	"context"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/vectorstores"
+	"github.com/deluan/flowllm/vectorstores/chroma"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VectorStore", func() {
+	var (
+		ctx    context.Context
+		server *fakeChroma
+		store  *chroma.VectorStore
+		docs   []flowllm.Document
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = newFakeChroma("secret")
+		DeferCleanup(server.Close)
+
+		var err error
+		store, err = chroma.NewVectorStore(&fakeEmbeddings{}, chroma.Options{
+			URL:        server.URL,
+			ApiKey:     "secret",
+			Collection: "docs",
+			HTTPClient: server.Client(),
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		docs = []flowllm.Document{
+			{ID: "doc-1", PageContent: "apples and oranges", Metadata: map[string]any{"source": "fruits.txt"}},
+			{ID: "doc-2", PageContent: "product catalog", Metadata: map[string]any{"source": "catalog.txt"}},
+			{ID: "doc-3", PageContent: "bananas and apples", Metadata: map[string]any{"source": "fruits.txt"}},
+		}
+	})
+
+	It("returns an empty result when the collection does not exist", func() {
+		results, err := store.SimilaritySearch(ctx, "apples", 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(BeEmpty())
+		Expect(server.collections).To(BeEmpty())
+	})
+
+	It("creates the collection and adds documents with their IDs", func() {
+		Expect(store.AddDocuments(ctx, docs...)).To(Succeed())
+		Expect(server.collections).To(HaveKey("docs"))
+		Expect(server.collections["docs"].Metadata).To(HaveKeyWithValue("hnsw:space", "cosine"))
+
+		results, err := store.SimilaritySearchVectorWithScore(ctx, []float32{0, 0, 1, 0}, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].ID).To(Equal("doc-3"))
+		Expect(results[0].PageContent).To(Equal("bananas and apples"))
+		Expect(results[0].Metadata).To(Equal(map[string]any{"source": "fruits.txt"}))
+		Expect(results[0].Score).To(BeNumerically(">", 0))
+	})
+
+	It("upserts documents with the same ID", func() {
+		Expect(store.AddDocuments(ctx, docs...)).To(Succeed())
+		Expect(store.AddDocuments(ctx, flowllm.Document{ID: "doc-2", PageContent: "product bananas"})).To(Succeed())
+		Expect(server.collections["docs"].records).To(HaveLen(3))
+
+		results, err := store.SimilaritySearch(ctx, "product", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results[0].PageContent).To(Equal("product bananas"))
+	})
+
+	It("round-trips the metadata values that are not scalars", func() {
+		doc := flowllm.Document{ID: "doc-4", PageContent: "apples", Metadata: map[string]any{
+			"source": "page.html", "page": 1, "links": []string{"/a", "/b"}, "author": nil,
+		}}
+		Expect(store.AddDocuments(ctx, doc)).To(Succeed())
+
+		results, err := store.SimilaritySearch(ctx, "apples", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results[0].Metadata).To(Equal(map[string]any{
+			"source": "page.html", "page": float64(1), "links": []any{"/a", "/b"}, "author": nil,
+		}))
+
+		stored := server.collections["docs"].records["doc-4"].metadata
+		Expect(stored).To(HaveKeyWithValue("links", `["/a","/b"]`))
+		Expect(stored).To(HaveKeyWithValue("flowllm_encoded_keys", `["author","links"]`))
+	})
+
+	It("filters searches by metadata", func() {
+		Expect(store.AddDocuments(ctx, docs...)).To(Succeed())
+		filtered := store.WithFilter(chroma.Where{"source": "fruits.txt"})
+
+		results, err := filtered.SimilaritySearch(ctx, "product", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+		for _, doc := range results {
+			Expect(doc.Metadata).To(HaveKeyWithValue("source", "fruits.txt"))
+		}
+	})
+
+	It("deletes documents by ID", func() {
+		Expect(store.AddDocuments(ctx, docs...)).To(Succeed())
+		Expect(store.Delete(ctx, "doc-1", "doc-3")).To(Succeed())
+
+		results, err := store.SimilaritySearch(ctx, "apples", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].ID).To(Equal("doc-2"))
+	})
+
+	It("manages collections", func() {
+		Expect(store.CreateCollection(ctx, "tenant1")).To(Succeed())
+		Expect(store.Collection("tenant2").AddDocuments(ctx, docs...)).To(Succeed())
+
+		names, err := store.ListCollections(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(names).To(ConsistOf("tenant1", "tenant2"))
+
+		Expect(store.DropCollection(ctx, "tenant1")).To(Succeed())
+		Expect(store.DropCollection(ctx, "tenant1")).To(MatchError(vectorstores.ErrCollectionNotFound))
+	})
+
+	It("returns server errors", func() {
+		store, err := chroma.NewVectorStore(&fakeEmbeddings{}, chroma.Options{URL: server.URL, ApiKey: "wrong", HTTPClient: server.Client()})
+		Expect(err).ToNot(HaveOccurred())
+		err = store.AddDocuments(ctx, docs...)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unauthorized"))
+	})
+})
+
+// fakeEmbeddings creates embeddings based on the presence of a few known words
+type fakeEmbeddings struct{}
+
+var vocabulary = []string{"apples", "product", "bananas"}
+
+func (f *fakeEmbeddings) EmbedString(_ context.Context, text string) ([]float32, error) {
+	vector := make([]float32, len(vocabulary)+1)
+	vector[len(vocabulary)] = 0.1
+	for i, word := range vocabulary {
+		if strings.Contains(text, word) {
+			vector[i] = 1
+		}
+	}
+	return vector, nil
+}
+
+func (f *fakeEmbeddings) EmbedStrings(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = f.EmbedString(ctx, text)
+	}
+	return vectors, nil
+}
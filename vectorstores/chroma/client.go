@@ -0,0 +1,72 @@
+package chroma
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type client struct {
+	url        string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func (c *client) endpoint(parts ...string) string {
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.TrimSuffix(c.url, "/") + "/api/v1/" + strings.Join(parts, "/")
+}
+
+// doRequest sends a request to the Chroma API and decodes the result into the given value.
+// If the response status is not 2xx, it returns an error with the body of the response.
+func (c *client) doRequest(ctx context.Context, method, url string, payload any, result any) (int, error) {
+	var body io.Reader
+	if payload != nil {
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(payloadBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	r, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		return r.StatusCode, errorMessageFromErrorResponse(method+" "+url, r.StatusCode, r.Body)
+	}
+	if result == nil {
+		return r.StatusCode, nil
+	}
+	return r.StatusCode, json.NewDecoder(r.Body).Decode(result)
+}
+
+func errorMessageFromErrorResponse(task string, status int, body io.Reader) error {
+	buf := new(bytes.Buffer)
+	_, err := io.Copy(buf, body)
+	if err != nil {
+		return fmt.Errorf("error reading body of error message: %w", err)
+	}
+
+	return fmt.Errorf("error %s: status %d: body: %s", task, status, buf.String())
+}
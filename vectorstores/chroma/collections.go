@@ -0,0 +1,69 @@
+package chroma
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type collection struct {
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+type createCollectionPayload struct {
+	Name        string         `json:"name"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	GetOrCreate bool           `json:"get_or_create"`
+}
+
+func (c *client) getOrCreateCollection(ctx context.Context, name string, space Space) (collection, error) {
+	payload := createCollectionPayload{
+		Name:        name,
+		Metadata:    map[string]any{"hnsw:space": space},
+		GetOrCreate: true,
+	}
+	var col collection
+	_, err := c.doRequest(ctx, http.MethodPost, c.endpoint("collections"), payload, &col)
+	return col, err
+}
+
+func (c *client) getCollection(ctx context.Context, name string) (collection, bool, error) {
+	var col collection
+	status, err := c.doRequest(ctx, http.MethodGet, c.endpoint("collections", name), nil, &col)
+	if isNotFound(status, err) {
+		return col, false, nil
+	}
+	return col, err == nil, err
+}
+
+func (c *client) listCollections(ctx context.Context) ([]string, error) {
+	var cols []collection
+	_, err := c.doRequest(ctx, http.MethodGet, c.endpoint("collections"), nil, &cols)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, col := range cols {
+		names = append(names, col.Name)
+	}
+	return names, nil
+}
+
+func (c *client) deleteCollection(ctx context.Context, name string) (bool, error) {
+	status, err := c.doRequest(ctx, http.MethodDelete, c.endpoint("collections", name), nil, nil)
+	if isNotFound(status, err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// isNotFound checks if the error is caused by a missing collection. Some versions of Chroma
+// return a 500 status with a "does not exist" message instead of a 404.
+func isNotFound(status int, err error) bool {
+	if status == http.StatusNotFound {
+		return true
+	}
+	return err != nil && strings.Contains(err.Error(), "does not exist")
+}
@@ -0,0 +1,186 @@
+package chroma_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/deluan/flowllm/vectorstores"
+	"golang.org/x/exp/slices"
+)
+
+// fakeChroma emulates the subset of the Chroma REST API used by the vector store
+type fakeChroma struct {
+	*httptest.Server
+	mu          sync.Mutex
+	token       string
+	collections map[string]*fakeCollection
+}
+
+type fakeCollection struct {
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	Metadata map[string]any `json:"metadata"`
+	records  map[string]fakeRecord
+}
+
+type fakeRecord struct {
+	embedding []float32
+	document  string
+	metadata  map[string]any
+}
+
+func newFakeChroma(token string) *fakeChroma {
+	f := &fakeChroma{token: token, collections: map[string]*fakeCollection{}}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+func (f *fakeChroma) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer "+f.token {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/"), "/")
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		cols := []*fakeCollection{}
+		for _, col := range f.collections {
+			cols = append(cols, col)
+		}
+		reply(w, cols)
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		var req struct {
+			Name        string         `json:"name"`
+			Metadata    map[string]any `json:"metadata"`
+			GetOrCreate bool           `json:"get_or_create"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		col, ok := f.collections[req.Name]
+		if !ok {
+			col = &fakeCollection{ID: fmt.Sprintf("id-%s", req.Name), Name: req.Name, Metadata: req.Metadata, records: map[string]fakeRecord{}}
+			f.collections[req.Name] = col
+		}
+		reply(w, col)
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		if col, ok := f.collections[parts[1]]; ok {
+			reply(w, col)
+			return
+		}
+		http.Error(w, `{"error":"ValueError('Collection `+parts[1]+` does not exist.')"}`, http.StatusInternalServerError)
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		if _, ok := f.collections[parts[1]]; ok {
+			delete(f.collections, parts[1])
+			reply(w, nil)
+			return
+		}
+		http.Error(w, `{"error":"ValueError('Collection `+parts[1]+` does not exist.')"}`, http.StatusInternalServerError)
+	case len(parts) == 3:
+		col := f.byID(parts[1])
+		if col == nil {
+			http.NotFound(w, r)
+			return
+		}
+		f.handleRecords(w, r, col, parts[2])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeChroma) handleRecords(w http.ResponseWriter, r *http.Request, col *fakeCollection, op string) {
+	var req struct {
+		IDs             []string         `json:"ids"`
+		Embeddings      [][]float32      `json:"embeddings"`
+		Documents       []string         `json:"documents"`
+		Metadatas       []map[string]any `json:"metadatas"`
+		QueryEmbeddings [][]float32      `json:"query_embeddings"`
+		NResults        int              `json:"n_results"`
+		Where           map[string]any   `json:"where"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	switch op {
+	case "upsert":
+		// Like the real server, only accept scalar metadata values
+		for _, metadata := range req.Metadatas {
+			for _, v := range metadata {
+				switch v.(type) {
+				case string, float64, bool:
+				default:
+					http.Error(w, `{"error":"invalid metadata value"}`, http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		for i, id := range req.IDs {
+			col.records[id] = fakeRecord{embedding: req.Embeddings[i], document: req.Documents[i], metadata: req.Metadatas[i]}
+		}
+		reply(w, true)
+	case "delete":
+		for _, id := range req.IDs {
+			delete(col.records, id)
+		}
+		reply(w, req.IDs)
+	case "query":
+		type result struct {
+			id       string
+			distance float32
+			fakeRecord
+		}
+		var results []result
+		for id, rec := range col.records {
+			if !matchWhere(rec.metadata, req.Where) {
+				continue
+			}
+			distance := 1 - vectorstores.CosineSimilarity(req.QueryEmbeddings[0], rec.embedding)
+			results = append(results, result{id: id, distance: distance, fakeRecord: rec})
+		}
+		slices.SortFunc(results, func(a, b result) bool { return a.distance < b.distance })
+		if len(results) > req.NResults {
+			results = results[:req.NResults]
+		}
+		resp := struct {
+			IDs       [][]string         `json:"ids"`
+			Documents [][]string         `json:"documents"`
+			Metadatas [][]map[string]any `json:"metadatas"`
+			Distances [][]float32        `json:"distances"`
+		}{IDs: [][]string{{}}, Documents: [][]string{{}}, Metadatas: [][]map[string]any{{}}, Distances: [][]float32{{}}}
+		for _, res := range results {
+			resp.IDs[0] = append(resp.IDs[0], res.id)
+			resp.Documents[0] = append(resp.Documents[0], res.document)
+			resp.Metadatas[0] = append(resp.Metadatas[0], res.metadata)
+			resp.Distances[0] = append(resp.Distances[0], res.distance)
+		}
+		reply(w, resp)
+	default:
+		http.Error(w, `{"error":"unknown operation"}`, http.StatusNotFound)
+	}
+}
+
+func (f *fakeChroma) byID(id string) *fakeCollection {
+	for _, col := range f.collections {
+		if col.ID == id {
+			return col
+		}
+	}
+	return nil
+}
+
+// matchWhere only supports equality filters
+func matchWhere(metadata map[string]any, where map[string]any) bool {
+	for k, v := range where {
+		if metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func reply(w http.ResponseWriter, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
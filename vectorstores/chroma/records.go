@@ -0,0 +1,53 @@
+package chroma
+
+import (
+	"context"
+	"net/http"
+)
+
+type upsertPayload struct {
+	IDs        []string         `json:"ids"`
+	Embeddings [][]float32      `json:"embeddings"`
+	Documents  []string         `json:"documents"`
+	Metadatas  []map[string]any `json:"metadatas"`
+}
+
+type queryPayload struct {
+	QueryEmbeddings [][]float32 `json:"query_embeddings"`
+	NResults        int         `json:"n_results"`
+	Where           Where       `json:"where,omitempty"`
+	Include         []string    `json:"include"`
+}
+
+type queryResponse struct {
+	IDs       [][]string         `json:"ids"`
+	Documents [][]string         `json:"documents"`
+	Metadatas [][]map[string]any `json:"metadatas"`
+	Distances [][]float32        `json:"distances"`
+}
+
+type deletePayload struct {
+	IDs []string `json:"ids"`
+}
+
+func (c *client) upsert(ctx context.Context, collectionID string, payload upsertPayload) error {
+	_, err := c.doRequest(ctx, http.MethodPost, c.endpoint("collections", collectionID, "upsert"), payload, nil)
+	return err
+}
+
+func (c *client) query(ctx context.Context, collectionID string, vector []float32, numResults int, where Where) (queryResponse, error) {
+	payload := queryPayload{
+		QueryEmbeddings: [][]float32{vector},
+		NResults:        numResults,
+		Where:           where,
+		Include:         []string{"documents", "metadatas", "distances"},
+	}
+	var response queryResponse
+	_, err := c.doRequest(ctx, http.MethodPost, c.endpoint("collections", collectionID, "query"), payload, &response)
+	return response, err
+}
+
+func (c *client) delete(ctx context.Context, collectionID string, ids []string) error {
+	_, err := c.doRequest(ctx, http.MethodPost, c.endpoint("collections", collectionID, "delete"), deletePayload{IDs: ids}, nil)
+	return err
+}
@@ -0,0 +1,10 @@
+package vectorstores
+
+import "errors"
+
+// DefaultCollection is the name of the collection used by the Memory vector store when no
+// collection is specified.
+const DefaultCollection = "default"
+
+// ErrCollectionNotFound is returned when trying to access or drop a collection that does not exist.
+var ErrCollectionNotFound = errors.New("collection not found")
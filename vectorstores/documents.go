@@ -0,0 +1,23 @@
+package vectorstores
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/deluan/flowllm"
+)
+
+// ErrDocumentNotFound is returned when trying to update a document that does not exist.
+var ErrDocumentNotFound = errors.New("document not found")
+
+// DocumentID returns the ID of the document, or an ID derived from its content and metadata
+// if the document does not have one.
+func DocumentID(doc flowllm.Document) string {
+	if doc.ID != "" {
+		return doc.ID
+	}
+	buf, _ := json.Marshal(doc.Metadata)
+	return fmt.Sprintf("%x", sha256.Sum256(append([]byte(doc.PageContent), buf...)))
+}
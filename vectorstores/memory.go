@@ -2,20 +2,34 @@ package vectorstores
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	"github.com/deluan/flowllm"
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 )
 
 // Memory is a simple in-memory vector store. It implements the VectorStore interface and
 // stores the vectors in memory. It is not meant to be used in production, but it is useful
 // for testing and as an example of how to implement a VectorStore.
+//
+// Documents are partitioned in named collections. The store returned by NewMemoryVectorStore
+// works on the DefaultCollection, use Collection to get a view scoped to another collection.
 type Memory struct {
 	embeddings flowllm.Embeddings
-	data       []memoryItem
+	collection string
+	search     SearchOptions
+	data       *memoryData
+}
+
+type memoryData struct {
+	mu          sync.RWMutex
+	collections map[string][]memoryItem
 }
 
 type memoryItem struct {
+	id       string
 	content  string
 	vector   []float32
 	metadata map[string]any
@@ -25,9 +39,62 @@ type memoryItem struct {
 func NewMemoryVectorStore(embeddings flowllm.Embeddings) *Memory {
 	return &Memory{
 		embeddings: embeddings,
+		collection: DefaultCollection,
+		data: &memoryData{
+			collections: map[string][]memoryItem{DefaultCollection: nil},
+		},
+	}
+}
+
+// Collection returns a view of the store scoped to the given collection. The view shares the
+// underlying data with the original store. The collection is created when the first document
+// is added to it.
+func (m *Memory) Collection(name string) *Memory {
+	view := *m
+	view.collection = name
+	return &view
+}
+
+// WithSearchOptions returns a view of the store that applies the given options to all searches.
+// Scores are calculated with the Cosine metric.
+func (m *Memory) WithSearchOptions(opts SearchOptions) *Memory {
+	view := *m
+	view.search = opts
+	return &view
+}
+
+// CreateCollection creates a new empty collection. It does nothing if the collection already exists.
+func (m *Memory) CreateCollection(_ context.Context, name string) error {
+	m.data.mu.Lock()
+	defer m.data.mu.Unlock()
+	if _, ok := m.data.collections[name]; !ok {
+		m.data.collections[name] = nil
+	}
+	return nil
+}
+
+// ListCollections returns the names of all collections in the store, sorted alphabetically.
+func (m *Memory) ListCollections(_ context.Context) ([]string, error) {
+	m.data.mu.RLock()
+	defer m.data.mu.RUnlock()
+	names := maps.Keys(m.data.collections)
+	slices.Sort(names)
+	return names, nil
+}
+
+// DropCollection removes a collection and all its documents from the store.
+func (m *Memory) DropCollection(_ context.Context, name string) error {
+	m.data.mu.Lock()
+	defer m.data.mu.Unlock()
+	if _, ok := m.data.collections[name]; !ok {
+		return ErrCollectionNotFound
 	}
+	delete(m.data.collections, name)
+	return nil
 }
 
+// AddDocuments adds the documents to the collection. Documents with the same ID as an existing
+// document replace it. Documents without ID get one derived from their content and metadata.
 func (m *Memory) AddDocuments(ctx context.Context, documents ...flowllm.Document) error {
 	texts := make([]string, len(documents))
 	for i, document := range documents {
@@ -41,16 +108,68 @@ func (m *Memory) AddDocuments(ctx context.Context, documents ...flowllm.Document
 	return nil
 }
 
+// UpdateDocuments replaces the content and metadata of the documents with the same IDs in the
+// collection. Only documents with a changed content are embedded again. It returns
+// ErrDocumentNotFound if any of the documents does not exist.
+func (m *Memory) UpdateDocuments(ctx context.Context, documents ...flowllm.Document) error {
+	m.data.mu.RLock()
+	items := m.data.collections[m.collection]
+	positions := itemPositions(items)
+	var texts []string
+	for _, doc := range documents {
+		pos, ok := positions[doc.ID]
+		if !ok {
+			m.data.mu.RUnlock()
+			return fmt.Errorf("%w: %s", ErrDocumentNotFound, doc.ID)
+		}
+		if items[pos].content != doc.PageContent {
+			texts = append(texts, doc.PageContent)
+		}
+	}
+	m.data.mu.RUnlock()
+
+	vectors := map[string][]float32{}
+	if len(texts) > 0 {
+		embedded, err := m.embeddings.EmbedStrings(ctx, texts)
+		if err != nil {
+			return err
+		}
+		for i, text := range texts {
+			vectors[text] = embedded[i]
+		}
+	}
+
+	m.data.mu.Lock()
+	defer m.data.mu.Unlock()
+	items = m.data.collections[m.collection]
+	positions = itemPositions(items)
+	for _, doc := range documents {
+		pos, ok := positions[doc.ID]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrDocumentNotFound, doc.ID)
+		}
+		if vector, ok := vectors[doc.PageContent]; ok {
+			items[pos].vector = vector
+		}
+		items[pos].content = doc.PageContent
+		items[pos].metadata = doc.Metadata
+	}
+	return nil
+}
+
 func (m *Memory) SimilaritySearch(ctx context.Context, query string, k int) ([]flowllm.Document, error) {
 	return SimilaritySearch(ctx, m, m.embeddings, query, k)
 }
 
 func (m *Memory) SimilaritySearchVectorWithScore(_ context.Context, query []float32, k int) ([]flowllm.ScoredDocument, error) {
+	m.data.mu.RLock()
+	defer m.data.mu.RUnlock()
 	var results []flowllm.ScoredDocument
-	for _, item := range m.data {
+	for _, item := range m.data.collections[m.collection] {
 		similarity := CosineSimilarity(query, item.vector)
 		results = append(results, flowllm.ScoredDocument{
 			Document: flowllm.Document{
+				ID:          item.id,
 				PageContent: item.content,
 				Metadata:    item.metadata,
 			},
@@ -61,7 +180,7 @@ func (m *Memory) SimilaritySearchVectorWithScore(_ context.Context, query []floa
 		return a.Score > b.Score
 	})
 	k = min(k, len(results))
-	return results[0:k], nil
+	return m.search.Apply(Cosine, results[0:k]), nil
 }
 
 func min(a, b int) int {
@@ -72,13 +191,32 @@ func min(a, b int) int {
 }
 
 func (m *Memory) addVectors(vectors [][]float32, documents []flowllm.Document) {
-	var memoryVectors []memoryItem
+	m.data.mu.Lock()
+	defer m.data.mu.Unlock()
+	items := m.data.collections[m.collection]
+	positions := itemPositions(items)
 	for i, vector := range vectors {
-		memoryVectors = append(memoryVectors, memoryItem{
+		item := memoryItem{
+			id:       DocumentID(documents[i]),
 			content:  documents[i].PageContent,
 			vector:   vector,
 			metadata: documents[i].Metadata,
-		})
+		}
+		if pos, ok := positions[item.id]; ok {
+			items[pos] = item
+			continue
+		}
+		positions[item.id] = len(items)
+		items = append(items, item)
+	}
+	m.data.collections[m.collection] = items
+}
+
+// itemPositions returns the position of each item in the slice, by ID.
+func itemPositions(items []memoryItem) map[string]int {
+	positions := make(map[string]int, len(items))
+	for i, item := range items {
+		positions[item.id] = i
 	}
-	m.data = append(m.data, memoryVectors...)
+	return positions
 }
@@ -2,7 +2,6 @@ package pinecone
 
 import (
 	"context"
-	"crypto/sha256"
 	"fmt"
 	"os"
 
@@ -29,6 +28,8 @@ type VectorStore struct {
 	client     *client
 	embeddings flowllm.Embeddings
 	textKey    string
+	metric     Metric
+	search     vectorstores.SearchOptions
 }
 
 // NewVectorStore creates a new Pinecone vector store.
@@ -68,6 +69,7 @@ func NewVectorStore(ctx context.Context, embeddings flowllm.Embeddings, opts Opt
 		embeddings: embeddings,
 		client:     c,
 		textKey:    "text",
+		metric:     opts.Metric,
 	}
 	err := connect(ctx, c)
 	if err != nil {
@@ -76,6 +78,14 @@ func NewVectorStore(ctx context.Context, embeddings flowllm.Embeddings, opts Opt
 	return &s, nil
 }
 
+// WithSearchOptions returns a view of the store that applies the given options to all searches.
+// Scores are normalized according to the Metric of the index.
+func (s *VectorStore) WithSearchOptions(opts vectorstores.SearchOptions) *VectorStore {
+	view := *s
+	view.search = opts
+	return &view
+}
+
 func (s *VectorStore) AddDocuments(ctx context.Context, documents ...flowllm.Document) error {
 	var texts []string
 	for i := 0; i < len(documents); i++ {
@@ -99,7 +109,7 @@ func (s *VectorStore) AddDocuments(ctx context.Context, documents ...flowllm.Doc
 		items = append(items, pineconeItem{
 			Values:   vectors[i],
 			Metadata: curMetadata,
-			ID:       fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("%v", curMetadata)))),
+			ID:       vectorstores.DocumentID(documents[i]),
 		})
 	}
 
@@ -143,13 +153,13 @@ func (s *VectorStore) SimilaritySearchVectorWithScore(ctx context.Context, query
 		return a.Score > b.Score
 	})
 
-	return resultDocuments, nil
+	return s.search.Apply(s.metric, resultDocuments), nil
 }
 
-type Metric string
+type Metric = vectorstores.Metric
 
 const (
-	Euclidean  Metric = "euclidean"
-	Cosine     Metric = "cosine"
-	DotProduct Metric = "dotproduct"
+	Euclidean  = vectorstores.Euclidean
+	Cosine     = vectorstores.Cosine
+	DotProduct = vectorstores.DotProduct
 )
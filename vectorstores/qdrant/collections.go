@@ -0,0 +1,59 @@
+package qdrant
+
+import (
+	"context"
+	"net/http"
+)
+
+type vectorParams struct {
+	Size     int      `json:"size"`
+	Distance Distance `json:"distance"`
+}
+
+type createCollectionPayload struct {
+	Vectors vectorParams `json:"vectors"`
+}
+
+type collectionsResponse struct {
+	Collections []struct {
+		Name string `json:"name"`
+	} `json:"collections"`
+}
+
+func (c *client) createCollection(ctx context.Context, name string, size int, distance Distance) error {
+	payload := createCollectionPayload{
+		Vectors: vectorParams{Size: size, Distance: distance},
+	}
+	_, err := c.doRequest(ctx, http.MethodPut, c.endpoint("collections", name), payload, nil)
+	return err
+}
+
+func (c *client) collectionExists(ctx context.Context, name string) (bool, error) {
+	status, err := c.doRequest(ctx, http.MethodGet, c.endpoint("collections", name), nil, nil)
+	if status == http.StatusNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (c *client) listCollections(ctx context.Context) ([]string, error) {
+	var resp response[collectionsResponse]
+	_, err := c.doRequest(ctx, http.MethodGet, c.endpoint("collections"), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, col := range resp.Result.Collections {
+		names = append(names, col.Name)
+	}
+	return names, nil
+}
+
+func (c *client) deleteCollection(ctx context.Context, name string) (bool, error) {
+	var resp response[bool]
+	status, err := c.doRequest(ctx, http.MethodDelete, c.endpoint("collections", name), nil, &resp)
+	if status == http.StatusNotFound {
+		return false, nil
+	}
+	return resp.Result, err
+}
@@ -0,0 +1,162 @@
+package qdrant_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/deluan/flowllm/vectorstores"
+	"golang.org/x/exp/slices"
+)
+
+// fakeQdrant emulates the subset of the Qdrant REST API used by the vector store
+type fakeQdrant struct {
+	*httptest.Server
+	mu          sync.Mutex
+	apiKey      string
+	collections map[string]*fakeCollection
+}
+
+type fakeCollection struct {
+	Size     int
+	Distance string
+	Points   map[string]fakePoint
+}
+
+type fakePoint struct {
+	ID      string         `json:"id"`
+	Vector  []float32      `json:"vector"`
+	Payload map[string]any `json:"payload"`
+	Score   float32        `json:"score"`
+}
+
+func newFakeQdrant(apiKey string) *fakeQdrant {
+	f := &fakeQdrant{apiKey: apiKey, collections: map[string]*fakeCollection{}}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+func (f *fakeQdrant) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("Api-Key") != f.apiKey {
+		http.Error(w, `{"status":{"error":"unauthorized"}}`, http.StatusUnauthorized)
+		return
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		var cols []map[string]string
+		for name := range f.collections {
+			cols = append(cols, map[string]string{"name": name})
+		}
+		reply(w, map[string]any{"collections": cols})
+	case len(parts) == 2 && r.Method == http.MethodPut:
+		var req struct {
+			Vectors struct {
+				Size     int    `json:"size"`
+				Distance string `json:"distance"`
+			} `json:"vectors"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.collections[parts[1]] = &fakeCollection{Size: req.Vectors.Size, Distance: req.Vectors.Distance, Points: map[string]fakePoint{}}
+		reply(w, true)
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		if col, ok := f.collection(w, parts[1]); ok {
+			reply(w, map[string]any{"status": "green", "config": col})
+		}
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		_, ok := f.collections[parts[1]]
+		delete(f.collections, parts[1])
+		reply(w, ok)
+	case len(parts) == 3 && r.Method == http.MethodPut:
+		col, ok := f.collection(w, parts[1])
+		if !ok {
+			return
+		}
+		var req struct {
+			Points []fakePoint `json:"points"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		for _, p := range req.Points {
+			if len(p.Vector) != col.Size {
+				http.Error(w, `{"status":{"error":"wrong vector size"}}`, http.StatusBadRequest)
+				return
+			}
+			col.Points[p.ID] = p
+		}
+		reply(w, map[string]any{"status": "completed"})
+	case len(parts) == 4 && parts[3] == "search":
+		col, ok := f.collection(w, parts[1])
+		if !ok {
+			return
+		}
+		var req struct {
+			Vector []float32      `json:"vector"`
+			Limit  int            `json:"limit"`
+			Filter map[string]any `json:"filter"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var res []fakePoint
+		for _, p := range col.Points {
+			if !matchFilter(p.Payload, req.Filter) {
+				continue
+			}
+			p.Score = vectorstores.CosineSimilarity(req.Vector, p.Vector)
+			p.Vector = nil
+			res = append(res, p)
+		}
+		slices.SortFunc(res, func(a, b fakePoint) bool { return a.Score > b.Score })
+		if len(res) > req.Limit {
+			res = res[:req.Limit]
+		}
+		reply(w, res)
+	case len(parts) == 4 && parts[3] == "delete":
+		col, ok := f.collection(w, parts[1])
+		if !ok {
+			return
+		}
+		var req struct {
+			Points []string `json:"points"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		for _, id := range req.Points {
+			delete(col.Points, id)
+		}
+		reply(w, map[string]any{"status": "completed"})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeQdrant) collection(w http.ResponseWriter, name string) (*fakeCollection, bool) {
+	col, ok := f.collections[name]
+	if !ok {
+		http.Error(w, `{"status":{"error":"Not found: Collection doesn't exist!"}}`, http.StatusNotFound)
+	}
+	return col, ok
+}
+
+// matchFilter only supports `must` conditions with `match.value`
+func matchFilter(payload map[string]any, filter map[string]any) bool {
+	must, _ := filter["must"].([]any)
+	for _, c := range must {
+		cond := c.(map[string]any)
+		var value any = payload
+		for _, k := range strings.Split(cond["key"].(string), ".") {
+			m, _ := value.(map[string]any)
+			value = m[k]
+		}
+		if value != cond["match"].(map[string]any)["value"] {
+			return false
+		}
+	}
+	return true
+}
+
+func reply(w http.ResponseWriter, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"result": result, "status": "ok", "time": 0.001})
+}
@@ -0,0 +1,42 @@
+package qdrant
+
+// Filter is a Qdrant filter, used to restrict the search to points with a matching payload.
+// The documents' metadata is stored in the "metadata" field of the payload, so the keys used
+// in the conditions should be prefixed with "metadata.". Example:
+//
+//	qdrant.Filter{Must: []qdrant.Condition{qdrant.MatchMetadata("source", "file.txt")}}
+//
+// See https://qdrant.tech/documentation/concepts/filtering/
+type Filter struct {
+	Must    []Condition `json:"must,omitempty"`
+	Should  []Condition `json:"should,omitempty"`
+	MustNot []Condition `json:"must_not,omitempty"`
+}
+
+// Condition is a single condition of a Filter. Only one of Match, Range or Filter should be set.
+type Condition struct {
+	Key   string `json:"key,omitempty"`
+	Match *Match `json:"match,omitempty"`
+	Range *Range `json:"range,omitempty"`
+	// Filter allows nesting filters, to build more complex conditions
+	*Filter
+}
+
+// Match is a condition that matches a key to an exact value, or to any of a list of values.
+type Match struct {
+	Value any   `json:"value,omitempty"`
+	Any   []any `json:"any,omitempty"`
+}
+
+// Range is a condition that matches a numeric key to a range of values.
+type Range struct {
+	Gt  *float64 `json:"gt,omitempty"`
+	Gte *float64 `json:"gte,omitempty"`
+	Lt  *float64 `json:"lt,omitempty"`
+	Lte *float64 `json:"lte,omitempty"`
+}
+
+// MatchMetadata returns a Condition that matches documents with the given metadata value.
+func MatchMetadata(key string, value any) Condition {
+	return Condition{Key: metadataKey + "." + key, Match: &Match{Value: value}}
+}
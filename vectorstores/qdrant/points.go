@@ -0,0 +1,76 @@
+package qdrant
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// pointID is the ID of a point. Qdrant accepts UUIDs and unsigned integers as IDs.
+type pointID string
+
+func (id *pointID) UnmarshalJSON(data []byte) error {
+	if strings.HasPrefix(string(data), `"`) {
+		var s string
+		err := json.Unmarshal(data, &s)
+		*id = pointID(s)
+		return err
+	}
+	*id = pointID(data)
+	return nil
+}
+
+type point struct {
+	ID      pointID        `json:"id"`
+	Vector  []float32      `json:"vector,omitempty"`
+	Payload map[string]any `json:"payload,omitempty"`
+}
+
+type upsertPayload struct {
+	Points []point `json:"points"`
+}
+
+type scoredPoint struct {
+	point
+	Score float32 `json:"score"`
+}
+
+type searchPayload struct {
+	Vector      []float32 `json:"vector"`
+	Limit       int       `json:"limit"`
+	WithPayload bool      `json:"with_payload"`
+	Filter      *Filter   `json:"filter,omitempty"`
+}
+
+type deletePayload struct {
+	Points []pointID `json:"points"`
+}
+
+func (c *client) upsert(ctx context.Context, collection string, points []point) error {
+	url := c.endpoint("collections", collection, "points") + "?wait=true"
+	_, err := c.doRequest(ctx, http.MethodPut, url, upsertPayload{Points: points}, nil)
+	return err
+}
+
+func (c *client) search(ctx context.Context, collection string, vector []float32, limit int, filter *Filter) ([]scoredPoint, error) {
+	payload := searchPayload{
+		Vector:      vector,
+		Limit:       limit,
+		WithPayload: true,
+		Filter:      filter,
+	}
+	var resp response[[]scoredPoint]
+	status, err := c.doRequest(ctx, http.MethodPost, c.endpoint("collections", collection, "points", "search"), payload, &resp)
+	if status == http.StatusNotFound {
+		// Collection does not exist yet, so there are no results
+		return nil, nil
+	}
+	return resp.Result, err
+}
+
+func (c *client) delete(ctx context.Context, collection string, ids []pointID) error {
+	url := c.endpoint("collections", collection, "points", "delete") + "?wait=true"
+	_, err := c.doRequest(ctx, http.MethodPost, url, deletePayload{Points: ids}, nil)
+	return err
+}
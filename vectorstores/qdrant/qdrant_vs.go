@@ -0,0 +1,259 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/vectorstores"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultURL        = "http://localhost:6333"
+	DefaultCollection = "flowllm"
+
+	contentKey    = "content"
+	metadataKey   = "metadata"
+	documentIDKey = "document_id"
+)
+
+// Distance is the metric used by Qdrant to compare vectors in a collection.
+type Distance string
+
+const (
+	Cosine    Distance = "Cosine"
+	Euclidean Distance = "Euclid"
+	Dot       Distance = "Dot"
+)
+
+// Options for the Qdrant vector store.
+type Options struct {
+	// URL of the Qdrant server. Defaults to the QDRANT_URL environment variable or DefaultURL
+	URL string
+	// ApiKey used to authenticate with the server. Defaults to the QDRANT_API_KEY environment variable
+	ApiKey string
+	// Collection is the name of the collection used by the store
+	Collection string
+	// Dimensions of the vectors, used when creating collections. If not set, collections
+	// are created when the first documents are added, with the dimensions of their embeddings
+	Dimensions int
+	// Distance is the metric used when creating collections
+	Distance Distance
+	// HTTPClient used to make requests. Defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// VectorStore is a vector store backed by a Qdrant server, accessed through its REST API.
+type VectorStore struct {
+	client     *client
+	embeddings flowllm.Embeddings
+	collection string
+	dimensions int
+	distance   Distance
+	filter     *Filter
+	state      *collectionState
+}
+
+// collectionState keeps track of the collections already known to exist in the server.
+type collectionState struct {
+	mu      sync.Mutex
+	created map[string]bool
+}
+
+// NewVectorStore creates a new Qdrant vector store. It does not make any request to the server,
+// collections are created when needed.
+func NewVectorStore(embeddings flowllm.Embeddings, opts Options) (*VectorStore, error) {
+	if opts.URL == "" {
+		opts.URL = os.Getenv("QDRANT_URL")
+	}
+	if opts.URL == "" {
+		opts.URL = DefaultURL
+	}
+	if opts.ApiKey == "" {
+		opts.ApiKey = os.Getenv("QDRANT_API_KEY")
+	}
+	if opts.Collection == "" {
+		opts.Collection = DefaultCollection
+	}
+	if opts.Distance == "" {
+		opts.Distance = Cosine
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	if _, err := url.Parse(opts.URL); err != nil {
+		return nil, fmt.Errorf("invalid url %q: %w", opts.URL, err)
+	}
+	c := &client{
+		url:        opts.URL,
+		apiKey:     opts.ApiKey,
+		httpClient: opts.HTTPClient,
+	}
+	return &VectorStore{
+		client:     c,
+		embeddings: embeddings,
+		collection: opts.Collection,
+		dimensions: opts.Dimensions,
+		distance:   opts.Distance,
+		state:      &collectionState{created: map[string]bool{}},
+	}, nil
+}
+
+// Collection returns a view of the store scoped to the given collection.
+func (s *VectorStore) Collection(name string) *VectorStore {
+	view := *s
+	view.collection = name
+	view.filter = nil
+	return &view
+}
+
+// WithFilter returns a view of the store where all searches are restricted to the points
+// matching the given filter.
+func (s *VectorStore) WithFilter(filter Filter) *VectorStore {
+	view := *s
+	view.filter = &filter
+	return &view
+}
+
+// CreateCollection creates a new collection, using the Dimensions and Distance from the Options.
+// It does nothing if the collection already exists.
+func (s *VectorStore) CreateCollection(ctx context.Context, name string) error {
+	if s.dimensions == 0 {
+		return errors.New("no value set for dimensions. Use Options.Dimensions when creating the store")
+	}
+	return s.ensureCollection(ctx, name, s.dimensions)
+}
+
+// ListCollections returns the names of all collections in the server.
+func (s *VectorStore) ListCollections(ctx context.Context) ([]string, error) {
+	return s.client.listCollections(ctx)
+}
+
+// DropCollection removes a collection and all its points from the server.
+func (s *VectorStore) DropCollection(ctx context.Context, name string) error {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	delete(s.state.created, name)
+	deleted, err := s.client.deleteCollection(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return vectorstores.ErrCollectionNotFound
+	}
+	return nil
+}
+
+func (s *VectorStore) ensureCollection(ctx context.Context, name string, size int) error {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	if s.state.created[name] {
+		return nil
+	}
+	exists, err := s.client.collectionExists(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := s.client.createCollection(ctx, name, size, s.distance); err != nil {
+			return err
+		}
+	}
+	s.state.created[name] = true
+	return nil
+}
+
+// AddDocuments adds the given documents to the collection, creating it if it does not exist.
+// Documents with an ID replace any existing document with the same ID. Documents without ID
+// get one derived from their content and metadata.
+func (s *VectorStore) AddDocuments(ctx context.Context, documents ...flowllm.Document) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	texts := make([]string, len(documents))
+	for i, document := range documents {
+		texts[i] = document.PageContent
+	}
+	vectors, err := s.embeddings.EmbedStrings(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureCollection(ctx, s.collection, len(vectors[0])); err != nil {
+		return err
+	}
+
+	points := make([]point, len(documents))
+	for i, doc := range documents {
+		id := vectorstores.DocumentID(doc)
+		points[i] = point{
+			ID:     toPointID(id),
+			Vector: vectors[i],
+			Payload: map[string]any{
+				contentKey:    doc.PageContent,
+				metadataKey:   doc.Metadata,
+				documentIDKey: id,
+			},
+		}
+	}
+	return s.client.upsert(ctx, s.collection, points)
+}
+
+// Delete removes the documents with the given IDs from the collection.
+func (s *VectorStore) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	pointIDs := make([]pointID, len(ids))
+	for i, id := range ids {
+		pointIDs[i] = toPointID(id)
+	}
+	return s.client.delete(ctx, s.collection, pointIDs)
+}
+
+func (s *VectorStore) SimilaritySearch(ctx context.Context, query string, k int) ([]flowllm.Document, error) {
+	return vectorstores.SimilaritySearch(ctx, s, s.embeddings, query, k)
+}
+
+func (s *VectorStore) SimilaritySearchVectorWithScore(ctx context.Context, query []float32, k int) ([]flowllm.ScoredDocument, error) {
+	matches, err := s.client.search(ctx, s.collection, query, k, s.filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []flowllm.ScoredDocument
+	for _, match := range matches {
+		content, ok := match.Payload[contentKey].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing %s in search response point %s", contentKey, match.ID)
+		}
+		id, ok := match.Payload[documentIDKey].(string)
+		if !ok {
+			id = string(match.ID)
+		}
+		metadata, _ := match.Payload[metadataKey].(map[string]any)
+		results = append(results, flowllm.ScoredDocument{
+			Document: flowllm.Document{
+				ID:          id,
+				PageContent: content,
+				Metadata:    metadata,
+			},
+			Score: match.Score,
+		})
+	}
+	return results, nil
+}
+
+// toPointID converts a document ID to a valid Qdrant point ID. UUIDs are used as is, other
+// IDs are converted to a UUID derived from them.
+func toPointID(id string) pointID {
+	if _, err := uuid.Parse(id); err == nil {
+		return pointID(id)
+	}
+	return pointID(uuid.NewSHA1(uuid.NameSpaceURL, []byte(id)).String())
+}
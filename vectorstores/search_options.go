@@ -0,0 +1,73 @@
+package vectorstores
+
+import (
+	"math"
+
+	"github.com/deluan/flowllm"
+	"golang.org/x/exp/slices"
+)
+
+// Metric is the function used by a vector store to compare vectors.
+type Metric string
+
+const (
+	Cosine     Metric = "cosine"
+	Euclidean  Metric = "euclidean"
+	DotProduct Metric = "dotproduct"
+)
+
+// SearchOptions control the results of the similarity searches of a vector store.
+type SearchOptions struct {
+	// NormalizeScores converts the scores returned by the store to relevance scores between 0
+	// and 1, where higher is more relevant, independently of the Metric used by the store
+	NormalizeScores bool
+	// MinScore is the minimum score of the documents returned. It is compared to the normalized
+	// score if NormalizeScores is set. Zero means no minimum
+	MinScore float32
+}
+
+// NormalizeScore converts a score calculated with the given metric to a relevance score between
+// 0 and 1. Cosine similarities are scaled from [-1, 1], Euclidean distances are converted
+// with 1/(1+distance) and dot products with the sigmoid function.
+func NormalizeScore(metric Metric, score float32) float32 {
+	switch metric {
+	case Euclidean:
+		if score < 0 {
+			score = -score
+		}
+		return 1 / (1 + score)
+	case DotProduct:
+		return float32(1 / (1 + math.Exp(-float64(score))))
+	default:
+		score = (score + 1) / 2
+		if score < 0 {
+			return 0
+		}
+		if score > 1 {
+			return 1
+		}
+		return score
+	}
+}
+
+// Apply applies the options to the results of a search made with the given metric, returning
+// the documents sorted by score.
+func (o SearchOptions) Apply(metric Metric, docs []flowllm.ScoredDocument) []flowllm.ScoredDocument {
+	if !o.NormalizeScores && o.MinScore == 0 {
+		return docs
+	}
+	results := make([]flowllm.ScoredDocument, 0, len(docs))
+	for _, doc := range docs {
+		if o.NormalizeScores {
+			doc.Score = NormalizeScore(metric, doc.Score)
+		}
+		if o.MinScore != 0 && doc.Score < o.MinScore {
+			continue
+		}
+		results = append(results, doc)
+	}
+	slices.SortStableFunc(results, func(a, b flowllm.ScoredDocument) bool {
+		return a.Score > b.Score
+	})
+	return results
+}
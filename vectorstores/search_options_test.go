@@ -0,0 +1,98 @@
+package vectorstores_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/deluan/flowllm"
+	. "github.com/deluan/flowllm/vectorstores"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NormalizeScore", func() {
+	DescribeTable("converts scores to the range [0, 1]",
+		func(metric Metric, score, expected float32) {
+			Expect(NormalizeScore(metric, score)).To(BeNumerically("~", expected, 1e-6))
+		},
+		Entry("cosine, identical", Cosine, float32(1), float32(1)),
+		Entry("cosine, orthogonal", Cosine, float32(0), float32(0.5)),
+		Entry("cosine, opposite", Cosine, float32(-1), float32(0)),
+		Entry("euclidean, same point", Euclidean, float32(0), float32(1)),
+		Entry("euclidean, distant", Euclidean, float32(3), float32(0.25)),
+		Entry("dot product, zero", DotProduct, float32(0), float32(0.5)),
+		Entry("dot product, large", DotProduct, float32(100), float32(1)),
+	)
+})
+
+var _ = Describe("SearchOptions", func() {
+	var docs []flowllm.ScoredDocument
+
+	BeforeEach(func() {
+		docs = []flowllm.ScoredDocument{
+			{Document: flowllm.Document{PageContent: "near"}, Score: 0.5},
+			{Document: flowllm.Document{PageContent: "far"}, Score: 4},
+			{Document: flowllm.Document{PageContent: "same"}, Score: 0},
+		}
+	})
+
+	It("returns the documents unchanged with the zero value", func() {
+		Expect(SearchOptions{}.Apply(Euclidean, docs)).To(Equal(docs))
+	})
+
+	It("normalizes the scores and sorts the documents by relevance", func() {
+		results := SearchOptions{NormalizeScores: true}.Apply(Euclidean, docs)
+		Expect(results).To(HaveLen(3))
+		Expect(results[0].PageContent).To(Equal("same"))
+		Expect(results[0].Score).To(BeNumerically("~", 1, 1e-6))
+		Expect(results[1].PageContent).To(Equal("near"))
+		Expect(results[2].PageContent).To(Equal("far"))
+		Expect(results[2].Score).To(BeNumerically("~", 0.2, 1e-6))
+	})
+
+	It("filters out documents with a score lower than the minimum", func() {
+		results := SearchOptions{NormalizeScores: true, MinScore: 0.5}.Apply(Euclidean, docs)
+		Expect(results).To(HaveLen(2))
+		Expect(results[1].PageContent).To(Equal("near"))
+
+		results = SearchOptions{MinScore: 0.5}.Apply(Cosine, docs)
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].PageContent).To(Equal("far"))
+	})
+})
+
+var _ = Describe("SimilaritySearch", func() {
+	It("returns the errors from the vector store", func() {
+		store := &failingStore{err: errors.New("search failed")}
+		_, err := SimilaritySearch(context.Background(), store, &fakeEmbeddings{}, "query", 2)
+		Expect(err).To(MatchError("search failed"))
+	})
+})
+
+type failingStore struct {
+	err error
+}
+
+func (f *failingStore) AddDocuments(context.Context, ...flowllm.Document) error { return f.err }
+
+func (f *failingStore) SimilaritySearch(context.Context, string, int) ([]flowllm.Document, error) {
+	return nil, f.err
+}
+
+func (f *failingStore) SimilaritySearchVectorWithScore(context.Context, []float32, int) ([]flowllm.ScoredDocument, error) {
+	return nil, f.err
+}
+
+type fakeEmbeddings struct{}
+
+func (f *fakeEmbeddings) EmbedString(context.Context, string) ([]float32, error) {
+	return []float32{1, 0}, nil
+}
+
+func (f *fakeEmbeddings) EmbedStrings(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{1, 0}
+	}
+	return vectors, nil
+}
@@ -22,14 +22,18 @@ func CosineSimilarity(a, b []float32) float32 {
 }
 
 // SimilaritySearch returns the k most similar documents to the given query. It uses the given
-// vector store's SimilaritySearchVectorWithScore method to perform the search.
+// vector store's SimilaritySearchVectorWithScore method to perform the search, so any search
+// options configured in the store are applied.
 func SimilaritySearch(ctx context.Context, store flowllm.VectorStore, embeddings flowllm.Embeddings, query string, k int) ([]flowllm.Document, error) {
 	queryVector, err := embeddings.EmbedString(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	results, err := store.SimilaritySearchVectorWithScore(ctx, queryVector, k)
+	if err != nil {
+		return nil, err
+	}
 	var docs []flowllm.Document
-	results, _ := store.SimilaritySearchVectorWithScore(ctx, queryVector, k)
 	for _, result := range results {
 		docs = append(docs, result.Document)
 	}
@@ -0,0 +1,372 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/vectorstores"
+	"golang.org/x/exp/slices"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	DefaultPath  = "vector_store.sqlite"
+	DefaultTable = "documents"
+)
+
+// Options for the SQLite vector store.
+type Options struct {
+	// Path is the path of the database file. Use ":memory:" for a transient in-memory database
+	Path string
+	// Table is the name of the table where the documents are stored. The full-text index is
+	// stored in a virtual table with the same name and the suffix "_fts"
+	Table string
+	// BusyTimeout is how long to wait for a lock held by another process
+	BusyTimeout time.Duration
+}
+
+// VectorStore is a vector store backed by SQLite, using a pure-Go driver. It implements the
+// flowllm.VectorStore interface. Documents, metadata and vectors are stored in a regular table,
+// and the content of the documents is also indexed in a FTS5 table for keyword searches.
+// Contrary to BoltDB, the database file can be shared by multiple processes.
+type VectorStore struct {
+	embeddings flowllm.Embeddings
+	db         *sql.DB
+	table      string
+	where      string
+	args       []any
+	search     vectorstores.SearchOptions
+}
+
+var validTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// NewVectorStore creates a new SQLite vector store, creating the tables if they don't exist.
+func NewVectorStore(embeddings flowllm.Embeddings, opts Options) (*VectorStore, func(), error) {
+	if opts.Path == "" {
+		opts.Path = DefaultPath
+	}
+	if opts.Table == "" {
+		opts.Table = DefaultTable
+	}
+	if opts.BusyTimeout == 0 {
+		opts.BusyTimeout = 5 * time.Second
+	}
+	if !validTableName.MatchString(opts.Table) {
+		return nil, func() {}, fmt.Errorf("invalid table name: %q", opts.Table)
+	}
+	// Set the busy timeout in the DSN, so it applies to all connections opened by database/sql
+	separator := "?"
+	if strings.Contains(opts.Path, "?") {
+		separator = "&"
+	}
+	dsn := fmt.Sprintf("%s%s_pragma=busy_timeout(%d)", opts.Path, separator, opts.BusyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	// Use a single connection, so the store also works with in-memory databases
+	db.SetMaxOpenConns(1)
+
+	s := VectorStore{
+		embeddings: embeddings,
+		db:         db,
+		table:      opts.Table,
+	}
+	err = s.createTables()
+	if err != nil {
+		_ = db.Close()
+		return nil, func() {}, err
+	}
+	return &s, func() { _ = db.Close() }, nil
+}
+
+func (s *VectorStore) createTables() error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS {table} (
+			rowid    INTEGER PRIMARY KEY,
+			id       TEXT NOT NULL UNIQUE,
+			content  TEXT NOT NULL,
+			metadata TEXT NOT NULL DEFAULT '{}',
+			vector   BLOB NOT NULL
+		)`,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS {table}_fts USING fts5(content, content='{table}', content_rowid='rowid')`,
+		`CREATE TRIGGER IF NOT EXISTS {table}_ai AFTER INSERT ON {table} BEGIN
+			INSERT INTO {table}_fts(rowid, content) VALUES (new.rowid, new.content);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS {table}_ad AFTER DELETE ON {table} BEGIN
+			INSERT INTO {table}_fts({table}_fts, rowid, content) VALUES ('delete', old.rowid, old.content);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS {table}_au AFTER UPDATE ON {table} BEGIN
+			INSERT INTO {table}_fts({table}_fts, rowid, content) VALUES ('delete', old.rowid, old.content);
+			INSERT INTO {table}_fts(rowid, content) VALUES (new.rowid, new.content);
+		END`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(s.sql(stmt)); err != nil {
+			return fmt.Errorf("creating tables: %w", err)
+		}
+	}
+	return nil
+}
+
+// sql replaces the {table} placeholder in the given statement with the table name.
+func (s *VectorStore) sql(stmt string) string {
+	return strings.ReplaceAll(stmt, "{table}", s.table)
+}
+
+// Where returns a view of the store where all searches are restricted to the documents matching
+// the given SQL condition. The condition can reference the columns id, content and metadata, and
+// it can use SQLite JSON functions to filter by metadata fields. Example:
+//
+//	store.Where("json_extract(metadata, '$.source') = ?", "file.txt")
+func (s *VectorStore) Where(condition string, args ...any) *VectorStore {
+	view := *s
+	view.where = condition
+	view.args = args
+	return &view
+}
+
+// WithSearchOptions returns a view of the store that applies the given options to all similarity
+// searches. Scores are calculated with the Cosine metric.
+func (s *VectorStore) WithSearchOptions(opts vectorstores.SearchOptions) *VectorStore {
+	view := *s
+	view.search = opts
+	return &view
+}
+
+// AddDocuments adds the given documents to the store. If a document has an ID and there is already a
+// document with the same ID in the store, it is replaced. Documents without ID get one derived from
+// their content and metadata.
+func (s *VectorStore) AddDocuments(ctx context.Context, documents ...flowllm.Document) error {
+	texts := make([]string, len(documents))
+	for i, document := range documents {
+		texts[i] = document.PageContent
+	}
+	vectors, err := s.embeddings.EmbedStrings(ctx, texts)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, s.sql(`
+		INSERT INTO {table} (id, content, metadata, vector) VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET content = excluded.content, metadata = excluded.metadata, vector = excluded.vector`))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, doc := range documents {
+		metadata, err := json.Marshal(doc.Metadata)
+		if err != nil {
+			return fmt.Errorf("marshalling metadata: %w", err)
+		}
+		_, err = stmt.ExecContext(ctx, vectorstores.DocumentID(doc), doc.PageContent, string(metadata), encodeVector(vectors[i]))
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// UpdateDocuments replaces the content and metadata of the documents with the same IDs in the
+// store. Only documents with a changed content are embedded again. It returns
+// vectorstores.ErrDocumentNotFound if any of the documents does not exist, without updating any of them.
+func (s *VectorStore) UpdateDocuments(ctx context.Context, documents ...flowllm.Document) error {
+	var changed []int
+	var texts []string
+	for i, doc := range documents {
+		var content string
+		err := s.db.QueryRowContext(ctx, s.sql("SELECT content FROM {table} WHERE id = ?"), doc.ID).Scan(&content)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %s", vectorstores.ErrDocumentNotFound, doc.ID)
+		}
+		if err != nil {
+			return err
+		}
+		if content != doc.PageContent {
+			changed = append(changed, i)
+			texts = append(texts, doc.PageContent)
+		}
+	}
+	vectors := make([][]byte, len(documents))
+	if len(texts) > 0 {
+		embedded, err := s.embeddings.EmbedStrings(ctx, texts)
+		if err != nil {
+			return err
+		}
+		for j, i := range changed {
+			vectors[i] = encodeVector(embedded[j])
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, s.sql(`
+		UPDATE {table} SET content = ?, metadata = ?, vector = COALESCE(?, vector) WHERE id = ?`))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, doc := range documents {
+		metadata, err := json.Marshal(doc.Metadata)
+		if err != nil {
+			return fmt.Errorf("marshalling metadata: %w", err)
+		}
+		res, err := stmt.ExecContext(ctx, doc.PageContent, string(metadata), vectors[i], doc.ID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return fmt.Errorf("%w: %s", vectorstores.ErrDocumentNotFound, doc.ID)
+		}
+	}
+	return tx.Commit()
+}
+
+// Delete removes the documents with the given IDs from the store.
+func (s *VectorStore) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	_, err := s.db.ExecContext(ctx, s.sql("DELETE FROM {table} WHERE id IN ("+placeholders+")"), args...)
+	return err
+}
+
+func (s *VectorStore) SimilaritySearch(ctx context.Context, query string, k int) ([]flowllm.Document, error) {
+	return vectorstores.SimilaritySearch(ctx, s, s.embeddings, query, k)
+}
+
+func (s *VectorStore) SimilaritySearchVectorWithScore(ctx context.Context, query []float32, k int) ([]flowllm.ScoredDocument, error) {
+	stmt := "SELECT id, content, metadata, vector FROM {table}"
+	if s.where != "" {
+		stmt += " WHERE " + s.where
+	}
+	rows, err := s.db.QueryContext(ctx, s.sql(stmt), s.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []flowllm.ScoredDocument
+	for rows.Next() {
+		var doc flowllm.ScoredDocument
+		var metadata string
+		var vector []byte
+		if err := rows.Scan(&doc.ID, &doc.PageContent, &metadata, &vector); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(metadata), &doc.Metadata); err != nil {
+			return nil, fmt.Errorf("unmarshalling metadata: %w", err)
+		}
+		doc.Score = vectorstores.CosineSimilarity(query, decodeVector(vector))
+		results = append(results, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(results, func(a, b flowllm.ScoredDocument) bool {
+		return a.Score > b.Score
+	})
+	results = s.search.Apply(vectorstores.Cosine, results)
+	k = min(k, len(results))
+	return results[:k], nil
+}
+
+var nonWordChars = regexp.MustCompile(`[^\p{L}\p{N}_]+`)
+
+// KeywordSearch returns the k documents that best match the terms in the query, using the FTS5 index.
+// Documents matching any of the terms are returned, ranked by their BM25 score. Higher scores are
+// better matches.
+func (s *VectorStore) KeywordSearch(ctx context.Context, query string, k int) ([]flowllm.ScoredDocument, error) {
+	var terms []string
+	for _, term := range nonWordChars.Split(query, -1) {
+		if term != "" {
+			terms = append(terms, `"`+term+`"`)
+		}
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	stmt := `SELECT id, content, metadata, matches.score FROM {table}
+		JOIN (SELECT rowid, -bm25({table}_fts) AS score FROM {table}_fts WHERE {table}_fts MATCH ?) AS matches
+		ON {table}.rowid = matches.rowid`
+	if s.where != "" {
+		stmt += " WHERE " + s.where
+	}
+	stmt += " ORDER BY matches.score DESC LIMIT ?"
+	args := append([]any{strings.Join(terms, " OR ")}, s.args...)
+	args = append(args, k)
+
+	rows, err := s.db.QueryContext(ctx, s.sql(stmt), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []flowllm.ScoredDocument
+	for rows.Next() {
+		var doc flowllm.ScoredDocument
+		var metadata string
+		var score float64
+		if err := rows.Scan(&doc.ID, &doc.PageContent, &metadata, &score); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(metadata), &doc.Metadata); err != nil {
+			return nil, fmt.Errorf("unmarshalling metadata: %w", err)
+		}
+		doc.Score = float32(score)
+		results = append(results, doc)
+	}
+	return results, rows.Err()
+}
+
+func encodeVector(vector []float32) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	vector := make([]float32, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return vector
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
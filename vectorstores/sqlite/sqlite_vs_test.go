@@ -0,0 +1,146 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/deluan/flowllm"
+	"github.com/deluan/flowllm/vectorstores"
+	"github.com/deluan/flowllm/vectorstores/sqlite"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VectorStore", func() {
+	var (
+		ctx   context.Context
+		path  string
+		store *sqlite.VectorStore
+		docs  []flowllm.Document
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var closeDB func()
+		var err error
+		path = filepath.Join(GinkgoT().TempDir(), "test.db")
+		store, closeDB, err = sqlite.NewVectorStore(&fakeEmbeddings{}, sqlite.Options{Path: path})
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(closeDB)
+
+		docs = []flowllm.Document{
+			{ID: "1", PageContent: "apples and oranges", Metadata: map[string]any{"source": "fruits.txt", "page": 1}},
+			{ID: "2", PageContent: "product code XK-42 is discontinued", Metadata: map[string]any{"source": "catalog.txt", "page": 1}},
+			{ID: "3", PageContent: "bananas are yellow", Metadata: map[string]any{"source": "fruits.txt", "page": 2}},
+		}
+		Expect(store.AddDocuments(ctx, docs...)).To(Succeed())
+	})
+
+	It("returns the documents with their IDs and metadata", func() {
+		results, err := store.SimilaritySearch(ctx, "bananas", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].ID).To(Equal("3"))
+		Expect(results[0].Metadata).To(HaveKeyWithValue("source", "fruits.txt"))
+	})
+
+	It("replaces documents with the same ID", func() {
+		Expect(store.AddDocuments(ctx, flowllm.Document{ID: "3", PageContent: "bananas are green"})).To(Succeed())
+
+		results, err := store.SimilaritySearch(ctx, "bananas", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(3))
+		Expect(results[0].PageContent).To(Equal("bananas are green"))
+	})
+
+	It("filters documents by metadata using SQL conditions", func() {
+		filtered := store.Where("json_extract(metadata, '$.source') = ? AND json_extract(metadata, '$.page') > ?", "fruits.txt", 1)
+		results, err := filtered.SimilaritySearch(ctx, "apples", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].ID).To(Equal("3"))
+	})
+
+	It("applies the search options to filtered views", func() {
+		filtered := store.WithSearchOptions(vectorstores.SearchOptions{MinScore: 0.5}).
+			Where("json_extract(metadata, '$.source') = ?", "fruits.txt")
+		results, err := filtered.SimilaritySearch(ctx, "apples", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].ID).To(Equal("1"))
+	})
+
+	It("deletes documents by ID", func() {
+		Expect(store.Delete(ctx, "1", "3")).To(Succeed())
+
+		results, err := store.SimilaritySearch(ctx, "apples", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].ID).To(Equal("2"))
+
+		reopened, closeDB, err := sqlite.NewVectorStore(&fakeEmbeddings{}, sqlite.Options{Path: path})
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(closeDB)
+		results, err = reopened.SimilaritySearch(ctx, "apples", 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].ID).To(Equal("2"))
+	})
+
+	Describe("KeywordSearch", func() {
+		It("finds documents containing exact terms", func() {
+			results, err := store.KeywordSearch(ctx, "XK-42", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].ID).To(Equal("2"))
+			Expect(results[0].Score).To(BeNumerically(">", 0))
+		})
+
+		It("ranks documents matching more terms first", func() {
+			results, err := store.KeywordSearch(ctx, "yellow bananas or apples", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect(results[0].ID).To(Equal("3"))
+			Expect(results[1].ID).To(Equal("1"))
+		})
+
+		It("respects the SQL filter", func() {
+			results, err := store.Where("json_extract(metadata, '$.page') = ?", 2).KeywordSearch(ctx, "apples bananas", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].ID).To(Equal("3"))
+		})
+
+		It("does not return deleted documents", func() {
+			Expect(store.Delete(ctx, "2")).To(Succeed())
+			results, err := store.KeywordSearch(ctx, "XK-42", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(BeEmpty())
+		})
+	})
+})
+
+// fakeEmbeddings creates embeddings based on the presence of a few known words
+type fakeEmbeddings struct{}
+
+var vocabulary = []string{"apples", "product", "bananas"}
+
+func (f *fakeEmbeddings) EmbedString(_ context.Context, text string) ([]float32, error) {
+	vector := make([]float32, len(vocabulary)+1)
+	vector[len(vocabulary)] = 0.1
+	for i, word := range vocabulary {
+		if strings.Contains(text, word) {
+			vector[i] = 1
+		}
+	}
+	return vector, nil
+}
+
+func (f *fakeEmbeddings) EmbedStrings(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = f.EmbedString(ctx, text)
+	}
+	return vectors, nil
+}